@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// unlockTimeout 为 BackupWindow 释放锁时使用的独立超时，避免调用方 ctx 取消后锁无法释放。
+const unlockTimeout = 30 * time.Second
+
+// FsyncLock 刷盘并阻塞写入，返回当前锁计数。
+func FsyncLock(ctx context.Context, client *mongo.Client) (int32, error) {
+	var res struct {
+		LockCount int32 `bson:"lockCount"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "fsync", Value: 1},
+		{Key: "lock", Value: true},
+	}).Decode(&res)
+
+	return res.LockCount, err
+}
+
+// FsyncUnlock 释放一次 FsyncLock，返回剩余锁计数（为 0 时写入恢复）。
+func FsyncUnlock(ctx context.Context, client *mongo.Client) (int32, error) {
+	var res struct {
+		LockCount int32 `bson:"lockCount"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "fsyncUnlock", Value: 1},
+	}).Decode(&res)
+
+	return res.LockCount, err
+}
+
+// BackupWindow 在 fsyncLock 保护下执行 fn（通常为文件系统快照），fn 返回后无论成功与否都会解锁。
+// 注意：锁作用于 client 当前连接的 mongod，副本集场景应连接到用于备份的成员（directConnection）。
+func BackupWindow(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error) (err error) {
+	if _, err = FsyncLock(ctx, client); err != nil {
+		return err
+	}
+
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), unlockTimeout)
+		defer cancel()
+
+		if _, unlockErr := FsyncUnlock(unlockCtx, client); unlockErr != nil {
+			err = errors.Join(err, unlockErr)
+		}
+	}()
+
+	return fn(ctx)
+}
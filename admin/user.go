@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Role 为 MongoDB 内置角色名。
+type Role string
+
+const (
+	RoleRead                 Role = "read"                 // RoleRead 只读。
+	RoleReadWrite            Role = "readWrite"            // RoleReadWrite 读写。
+	RoleDbAdmin              Role = "dbAdmin"              // RoleDbAdmin 库管理（索引、统计等）。
+	RoleDbOwner              Role = "dbOwner"              // RoleDbOwner 库所有者。
+	RoleUserAdmin            Role = "userAdmin"            // RoleUserAdmin 库内用户管理。
+	RoleClusterAdmin         Role = "clusterAdmin"         // RoleClusterAdmin 集群管理。
+	RoleClusterMonitor       Role = "clusterMonitor"       // RoleClusterMonitor 集群监控。
+	RoleBackup               Role = "backup"               // RoleBackup 备份。
+	RoleRestore              Role = "restore"              // RoleRestore 恢复。
+	RoleReadAnyDatabase      Role = "readAnyDatabase"      // RoleReadAnyDatabase 所有库只读。
+	RoleReadWriteAnyDatabase Role = "readWriteAnyDatabase" // RoleReadWriteAnyDatabase 所有库读写。
+	RoleUserAdminAnyDatabase Role = "userAdminAnyDatabase" // RoleUserAdminAnyDatabase 所有库用户管理。
+	RoleDbAdminAnyDatabase   Role = "dbAdminAnyDatabase"   // RoleDbAdminAnyDatabase 所有库管理。
+	RoleRoot                 Role = "root"                 // RoleRoot 超级用户。
+)
+
+// RoleRef 为授予用户的角色及其作用库。
+type RoleRef struct {
+	Role Role
+	// DB 为角色作用的库，为空时使用用户所在库。
+	DB string
+}
+
+// User 描述一个数据库用户。
+type User struct {
+	Name     string
+	Password string
+	// DB 为用户所在的认证库（通常为 admin），为空时默认 admin。
+	DB    string
+	Roles []RoleRef
+}
+
+// CreateUser 创建用户并授予角色。
+func CreateUser(ctx context.Context, client *mongo.Client, user User) error {
+	if user.Name == "" {
+		return errors.New("mongo/admin: user name is empty")
+	}
+	if user.Password == "" {
+		return errors.New("mongo/admin: user password is empty")
+	}
+
+	db := userDB(user.DB)
+	return client.Database(db).RunCommand(ctx, bson.D{
+		{Key: "createUser", Value: user.Name},
+		{Key: "pwd", Value: user.Password},
+		{Key: "roles", Value: roleDocs(db, user.Roles)},
+	}).Err()
+}
+
+// DropUser 删除用户。
+func DropUser(ctx context.Context, client *mongo.Client, db, name string) error {
+	return client.Database(userDB(db)).RunCommand(ctx, bson.D{
+		{Key: "dropUser", Value: name},
+	}).Err()
+}
+
+// GrantRoles 为已存在的用户追加角色。
+func GrantRoles(ctx context.Context, client *mongo.Client, db, name string, roles ...RoleRef) error {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	db = userDB(db)
+	return client.Database(db).RunCommand(ctx, bson.D{
+		{Key: "grantRolesToUser", Value: name},
+		{Key: "roles", Value: roleDocs(db, roles)},
+	}).Err()
+}
+
+// RevokeRoles 撤销用户的角色。
+func RevokeRoles(ctx context.Context, client *mongo.Client, db, name string, roles ...RoleRef) error {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	db = userDB(db)
+	return client.Database(db).RunCommand(ctx, bson.D{
+		{Key: "revokeRolesFromUser", Value: name},
+		{Key: "roles", Value: roleDocs(db, roles)},
+	}).Err()
+}
+
+// RotatePassword 修改用户密码，已建立的连接不受影响。
+func RotatePassword(ctx context.Context, client *mongo.Client, db, name, password string) error {
+	if password == "" {
+		return errors.New("mongo/admin: user password is empty")
+	}
+
+	return client.Database(userDB(db)).RunCommand(ctx, bson.D{
+		{Key: "updateUser", Value: name},
+		{Key: "pwd", Value: password},
+	}).Err()
+}
+
+func userDB(db string) string {
+	if db == "" {
+		return "admin"
+	}
+	return db
+}
+
+func roleDocs(db string, roles []RoleRef) bson.A {
+	docs := make(bson.A, 0, len(roles))
+	for _, r := range roles {
+		target := r.DB
+		if target == "" {
+			target = db
+		}
+		docs = append(docs, bson.D{
+			{Key: "role", Value: string(r.Role)},
+			{Key: "db", Value: target},
+		})
+	}
+	return docs
+}
@@ -0,0 +1,181 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrLogHandlerClosed 表示异步日志 handler 已关闭，不再接收日志。
+var ErrLogHandlerClosed = errors.New("mongo: log handler closed")
+
+// 异步日志 handler 的默认参数。
+const (
+	DefaultAsyncLogQueueSize = 1024 // DefaultAsyncLogQueueSize 为默认队列长度。
+	DefaultAsyncLogWorkers   = 1    // DefaultAsyncLogWorkers 为默认 worker 数。
+)
+
+// AsyncLogConf 为异步日志 handler 的配置，零值可用。
+type AsyncLogConf struct {
+	// QueueSize 为缓冲队列长度，<=0 时使用 DefaultAsyncLogQueueSize；队列满时丢弃最旧的日志。
+	QueueSize int
+	// Workers 为并发执行 handler 的 worker 数，<=0 时使用 DefaultAsyncLogWorkers；大于 1 时日志不保证按序处理。
+	Workers int
+	// OnError 接收 handler 返回的错误与 panic，为空时忽略。
+	OnError func(err error)
+}
+
+// AsyncLogHandler 将结构化命令日志写入有界队列，由后台 worker 调用 handler，命令监控回调不再等待 handler 执行。
+type AsyncLogHandler struct {
+	handler LogHandler
+	onError func(err error)
+
+	queue   chan asyncLogEntry
+	workers sync.WaitGroup
+	dropped atomic.Uint64
+
+	// mu 保护 closed，避免关闭队列后继续写入。
+	mu     sync.RWMutex
+	closed bool
+
+	// pending 为已入队但尚未处理完成的日志数，降为 0 时唤醒 Flush。
+	pendingMu sync.Mutex
+	pending   int
+	idle      []chan struct{}
+}
+
+type asyncLogEntry struct {
+	ctx context.Context
+	log OperationLog
+}
+
+// NewAsyncLogHandler 创建异步日志 handler 并启动 worker，通过 conf.WithLogHandler(h.Handle) 注册，
+// 服务退出时调用 Close 处理剩余日志。
+func NewAsyncLogHandler(handler LogHandler, conf AsyncLogConf) *AsyncLogHandler {
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = DefaultAsyncLogQueueSize
+	}
+	if conf.Workers <= 0 {
+		conf.Workers = DefaultAsyncLogWorkers
+	}
+
+	h := &AsyncLogHandler{
+		handler: handler,
+		onError: conf.OnError,
+		queue:   make(chan asyncLogEntry, conf.QueueSize),
+	}
+	for i := 0; i < conf.Workers; i++ {
+		h.workers.Add(1)
+		go h.work()
+	}
+	return h
+}
+
+// Handle 实现 LogHandler：深拷贝日志并入队后立即返回，队列满时丢弃最旧的一条。
+// ctx 只保留其中的值，不继承取消（命令结束后 ctx 通常已取消）。
+func (h *AsyncLogHandler) Handle(ctx context.Context, log *OperationLog) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return ErrLogHandlerClosed
+	}
+
+	h.addPending(1)
+	entry := asyncLogEntry{ctx: context.WithoutCancel(ctx), log: *log.Clone()}
+	for {
+		select {
+		case h.queue <- entry:
+			return nil
+		default:
+		}
+		// 队列已满：丢弃最旧的日志后重试。
+		select {
+		case <-h.queue:
+			h.dropped.Add(1)
+			h.addPending(-1)
+		default:
+		}
+	}
+}
+
+// Dropped 返回因队列已满被丢弃的日志数。
+func (h *AsyncLogHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Flush 等待已入队的日志全部处理完成，ctx 结束时返回 ctx.Err()。
+func (h *AsyncLogHandler) Flush(ctx context.Context) error {
+	h.pendingMu.Lock()
+	if h.pending == 0 {
+		h.pendingMu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	h.idle = append(h.idle, idle)
+	h.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收日志，等待队列中剩余的日志处理完成后退出 worker；ctx 结束时返回 ctx.Err()，worker 仍在后台处理剩余日志。
+// 重复调用时等待同一批 worker 退出。
+func (h *AsyncLogHandler) Close(ctx context.Context) error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *AsyncLogHandler) work() {
+	defer h.workers.Done()
+	for entry := range h.queue {
+		if err := h.handle(entry); err != nil && h.onError != nil {
+			h.onError(err)
+		}
+		h.addPending(-1)
+	}
+}
+
+func (h *AsyncLogHandler) handle(entry asyncLogEntry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("mongo: log handler panic: %v", r)
+		}
+	}()
+	return h.handler(entry.ctx, &entry.log)
+}
+
+func (h *AsyncLogHandler) addPending(delta int) {
+	h.pendingMu.Lock()
+	defer h.pendingMu.Unlock()
+
+	h.pending += delta
+	if h.pending == 0 {
+		for _, idle := range h.idle {
+			close(idle)
+		}
+		h.idle = nil
+	}
+}
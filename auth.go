@@ -0,0 +1,133 @@
+package mongo
+
+import (
+	"os"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// 认证机制。
+const (
+	AuthMechanismSCRAMSHA256 = "SCRAM-SHA-256" // AuthMechanismSCRAMSHA256 用户名密码认证（默认由服务端协商）。
+	AuthMechanismX509        = "MONGODB-X509"  // AuthMechanismX509 客户端证书认证。
+	AuthMechanismAWS         = "MONGODB-AWS"   // AuthMechanismAWS AWS IAM 认证。
+	AuthMechanismGSSAPI      = "GSSAPI"        // AuthMechanismGSSAPI Kerberos 认证（需以 -tags gssapi 且启用 cgo 编译）。
+	AuthMechanismPLAIN       = "PLAIN"         // AuthMechanismPLAIN LDAP 代理认证，密码以明文发送，应配合 TLS 使用。
+)
+
+// KerberosConf 为 GSSAPI（Kerberos）认证属性。
+type KerberosConf struct {
+	// ServiceName 为服务主体名称，为空时为 mongodb。
+	ServiceName string `json:"service_name"`
+	// ServiceRealm 为服务所在的 realm，跨 realm 认证时设置。
+	ServiceRealm string `json:"service_realm"`
+	// ServiceHost 为服务主体使用的主机名，为空时使用连接地址中的主机名。
+	ServiceHost string `json:"service_host"`
+	// CanonicalizeHostName 为 true 时通过 DNS 将主机名规范化后再拼接服务主体。
+	CanonicalizeHostName bool `json:"canonicalize_host_name"`
+}
+
+// properties 转换为 driver 的 AuthMechanismProperties。
+func (k *KerberosConf) properties(props map[string]string) map[string]string {
+	if k == nil {
+		return props
+	}
+	if props == nil {
+		props = map[string]string{}
+	}
+	if k.ServiceName != "" {
+		props["SERVICE_NAME"] = k.ServiceName
+	}
+	if k.ServiceRealm != "" {
+		props["SERVICE_REALM"] = k.ServiceRealm
+	}
+	if k.ServiceHost != "" {
+		props["SERVICE_HOST"] = k.ServiceHost
+	}
+	if k.CanonicalizeHostName {
+		props["CANONICALIZE_HOST_NAME"] = "true"
+	}
+	return props
+}
+
+// awsSessionTokenProperty 为 MONGODB-AWS 临时凭证的 session token 属性名。
+const awsSessionTokenProperty = "AWS_SESSION_TOKEN"
+
+// credential 根据认证机制构造 driver 凭证，无需认证时返回 nil。
+//
+// MONGODB-AWS 下 username/password 为 access key id/secret access key，可留空：
+// 留空时 driver 依次从环境变量（AWS_ACCESS_KEY_ID 等）、EKS IRSA（AWS_WEB_IDENTITY_TOKEN_FILE + AWS_ROLE_ARN）、
+// ECS 与 EC2 实例元数据获取凭证；显式提供 access key 时从 AWS_SESSION_TOKEN 环境变量补全 session token。
+// Credential 返回按 AuthMechanism 由 Username/Password 构造的 driver 认证配置（不含 SecretsProvider 提供的凭证），
+// 未配置认证时返回 nil；供需要自行创建原生连接的场景与本包保持一致。
+func (c *Conf) Credential() *options.Credential {
+	return c.credential(c.Username, c.Password)
+}
+
+func (c *Conf) credential(username, password string) *options.Credential {
+	switch c.AuthMechanism {
+	case "":
+		if username == "" {
+			return nil
+		}
+		return &options.Credential{Username: username, Password: password, AuthSource: c.AuthSource}
+	case AuthMechanismAWS:
+		credential := &options.Credential{
+			AuthMechanism:           AuthMechanismAWS,
+			AuthSource:              "$external",
+			Username:                username,
+			Password:                password,
+			AuthMechanismProperties: copyProperties(c.AuthMechanismProperties),
+		}
+		if username != "" && credential.AuthMechanismProperties[awsSessionTokenProperty] == "" {
+			if token := os.Getenv(awsSessionTokenProperty); token != "" {
+				if credential.AuthMechanismProperties == nil {
+					credential.AuthMechanismProperties = map[string]string{}
+				}
+				credential.AuthMechanismProperties[awsSessionTokenProperty] = token
+			}
+		}
+		return credential
+	case AuthMechanismGSSAPI:
+		// Username 为 Kerberos principal（user@REALM），Password 为空时使用 kinit/keytab 获取的票据。
+		return &options.Credential{
+			AuthMechanism:           AuthMechanismGSSAPI,
+			AuthSource:              "$external",
+			Username:                username,
+			Password:                password,
+			PasswordSet:             password != "",
+			AuthMechanismProperties: c.Kerberos.properties(copyProperties(c.AuthMechanismProperties)),
+		}
+	case AuthMechanismPLAIN:
+		// 由服务端转发至 LDAP 校验，AuthSource 默认为 $external。
+		source := c.AuthSource
+		if source == "" {
+			source = "$external"
+		}
+		return &options.Credential{
+			AuthMechanism: AuthMechanismPLAIN,
+			AuthSource:    source,
+			Username:      username,
+			Password:      password,
+		}
+	default:
+		return &options.Credential{
+			AuthMechanism:           c.AuthMechanism,
+			AuthSource:              c.AuthSource,
+			Username:                username,
+			Password:                password,
+			AuthMechanismProperties: copyProperties(c.AuthMechanismProperties),
+		}
+	}
+}
+
+func copyProperties(props map[string]string) map[string]string {
+	if len(props) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(props))
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// defaultMaxPoolSize 为 driver 默认的连接池大小。
+const defaultMaxPoolSize = 100
+
+// emitStartupLog 汇总服务端能力与生效的客户端配置，通过内部 logger 输出一条启动摘要。
+func emitStartupLog(ctx context.Context, c *Conf, client *mongo.Client, clientOptions *options.ClientOptions) {
+	logData := &internal.StartupLogger{
+		Database:    c.Database,
+		Address:     c.displayAddress(),
+		AppName:     c.appName(),
+		TLS:         clientOptions.TLSConfig != nil,
+		MaxPoolSize: defaultMaxPoolSize,
+	}
+	if clientOptions.MaxPoolSize != nil {
+		logData.MaxPoolSize = *clientOptions.MaxPoolSize
+	}
+	if clientOptions.MaxConnIdleTime != nil {
+		logData.MaxConnIdleSec = int64(clientOptions.MaxConnIdleTime.Seconds())
+	}
+	if clientOptions.TLSConfig != nil && clientOptions.TLSConfig.InsecureSkipVerify {
+		logData.Warnings = append(logData.Warnings, "TLS certificate verification is disabled (TlsInsecureSkipVerify), do not use in production")
+	}
+
+	info, err := Capabilities(ctx, client)
+	if err != nil {
+		logData.Warnings = append(logData.Warnings, "failed to describe server: "+err.Error())
+		internal.EmitStartupLog(ctx, c.output(), logData)
+		return
+	}
+
+	logData.ServerVersion = info.Version
+	logData.Topology = string(info.Topology)
+	logData.ReplicaSet = info.ReplicaSet
+	logData.Transactions = info.Transactions
+	logData.ChangeStreams = info.ChangeStreams
+
+	if !info.VersionAtLeast(4, 2) {
+		logData.Warnings = append(logData.Warnings, "server version "+info.Version+" is older than 4.2 and may not be supported by mongo-driver v2")
+	}
+	if info.Topology == TopologyStandalone {
+		logData.Warnings = append(logData.Warnings, "standalone topology: transactions and change streams are unavailable")
+	}
+	switch {
+	case c.AuthMechanism == AuthMechanismPLAIN && !logData.TLS:
+		logData.Warnings = append(logData.Warnings, "PLAIN authentication sends the password in clear text without TLS")
+	case c.Username != "" && !logData.TLS:
+		logData.Warnings = append(logData.Warnings, "authentication is enabled without TLS")
+	}
+
+	internal.EmitStartupLog(ctx, c.output(), logData)
+}
@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// BSONConf 为 driver 编解码行为配置，对应 options.BSONOptions，零值与 driver 默认行为一致。
+type BSONConf struct {
+	// UseJSONStructTags 在没有 bson tag 时使用 json tag。
+	UseJSONStructTags bool `json:"use_json_struct_tags"`
+	// ErrorOnInlineDuplicates 在 inline 结构体与外层字段重名时返回错误。
+	ErrorOnInlineDuplicates bool `json:"error_on_inline_duplicates"`
+	// IntMinSize 将 Go int 编码为能容纳该值的最小 BSON 整数类型。
+	IntMinSize bool `json:"int_min_size"`
+	// NilMapAsEmpty 将 nil map 编码为空文档而不是 null。
+	NilMapAsEmpty bool `json:"nil_map_as_empty"`
+	// NilSliceAsEmpty 将 nil slice 编码为空数组而不是 null。
+	NilSliceAsEmpty bool `json:"nil_slice_as_empty"`
+	// NilByteSliceAsEmpty 将 nil []byte 编码为空 binary 而不是 null。
+	NilByteSliceAsEmpty bool `json:"nil_byte_slice_as_empty"`
+	// OmitZeroStruct 使 omitempty 对零值结构体生效。
+	OmitZeroStruct bool `json:"omit_zero_struct"`
+	// OmitEmpty 对所有字段启用 omitempty。
+	OmitEmpty bool `json:"omit_empty"`
+	// StringifyMapKeysWithFmt 使用 fmt.Sprint 编码 map 的键。
+	StringifyMapKeysWithFmt bool `json:"stringify_map_keys_with_fmt"`
+	// AllowTruncatingDoubles 解码时允许将浮点数截断为整数。
+	AllowTruncatingDoubles bool `json:"allow_truncating_doubles"`
+	// BinaryAsSlice 将 binary 解码到 interface{} 时使用 []byte。
+	BinaryAsSlice bool `json:"binary_as_slice"`
+	// DefaultDocumentM 将嵌套文档解码到 interface{} 时使用 bson.M。
+	DefaultDocumentM bool `json:"default_document_m"`
+	// DefaultDocumentMap 将嵌套文档解码到 interface{} 时使用 map[string]any。
+	DefaultDocumentMap bool `json:"default_document_map"`
+	// ObjectIDAsHexString 将 ObjectID 解码到 string 字段时使用十六进制字符串。
+	ObjectIDAsHexString bool `json:"object_id_as_hex_string"`
+	// UseLocalTimeZone 将时间解码为本地时区，默认 false（与 driver 一致，按 UTC 解码）。
+	UseLocalTimeZone bool `json:"use_local_time_zone"`
+	// ZeroMaps 解码前清空目标 map。
+	ZeroMaps bool `json:"zero_maps"`
+	// ZeroStructs 解码前清空目标结构体。
+	ZeroStructs bool `json:"zero_structs"`
+}
+
+// bsonOptions 转换为 driver 的 BSONOptions，未配置时返回 nil（使用 driver 默认行为）。
+func (b *BSONConf) bsonOptions() *options.BSONOptions {
+	if b == nil {
+		return nil
+	}
+	return &options.BSONOptions{
+		UseJSONStructTags:       b.UseJSONStructTags,
+		ErrorOnInlineDuplicates: b.ErrorOnInlineDuplicates,
+		IntMinSize:              b.IntMinSize,
+		NilMapAsEmpty:           b.NilMapAsEmpty,
+		NilSliceAsEmpty:         b.NilSliceAsEmpty,
+		NilByteSliceAsEmpty:     b.NilByteSliceAsEmpty,
+		OmitZeroStruct:          b.OmitZeroStruct,
+		OmitEmpty:               b.OmitEmpty,
+		StringifyMapKeysWithFmt: b.StringifyMapKeysWithFmt,
+		AllowTruncatingDoubles:  b.AllowTruncatingDoubles,
+		BinaryAsSlice:           b.BinaryAsSlice,
+		DefaultDocumentM:        b.DefaultDocumentM,
+		DefaultDocumentMap:      b.DefaultDocumentMap,
+		ObjectIDAsHexString:     b.ObjectIDAsHexString,
+		UseLocalTimeZone:        b.UseLocalTimeZone,
+		ZeroMaps:                b.ZeroMaps,
+		ZeroStructs:             b.ZeroStructs,
+	}
+}
@@ -0,0 +1,416 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrBufferClosed 表示 BufferedInserter 已关闭，不再接受写入。
+var ErrBufferClosed = errors.New("mongo: buffered inserter is closed")
+
+// BufferPolicy 定义 flush 失败后的处理策略。
+type BufferPolicy uint8
+
+const (
+	// BufferPolicyRetry 网络错误、超时等暂时性失败后按 MaxRetries 重试，仍失败则丢弃；校验失败等确定性错误不重试。
+	BufferPolicyRetry BufferPolicy = iota
+	// BufferPolicyDrop 失败后直接丢弃本批文档。
+	BufferPolicyDrop
+)
+
+// BufferConf 为 BufferedInserter 的配置。
+type BufferConf struct {
+	// MaxSize 为单个集合累积多少条文档后触发 flush（<=0 时默认 500）。
+	MaxSize int
+	// MaxPending 为单个集合允许缓存的最大文档数，超出后新文档被丢弃（<=0 表示不限制）。
+	MaxPending int
+	// Interval 为定时 flush 间隔（<=0 时默认 1s）。
+	Interval time.Duration
+
+	// Policy 为 flush 失败后的处理策略。
+	Policy BufferPolicy
+	// MaxRetries 为 BufferPolicyRetry 下的最大重试次数（<=0 时默认 3）。
+	MaxRetries int
+	// RetryBackoff 为两次重试之间的等待时间（<=0 时默认 100ms）。
+	RetryBackoff time.Duration
+	// FlushTimeout 为单次 InsertMany 的超时（<=0 时默认 10s），下游无响应时后台 flush 与 Close 不会无限阻塞。
+	FlushTimeout time.Duration
+
+	// Registry 为入队时编码文档使用的注册表，应与连接的 WithRegistry/WithCodecs 一致（为空时使用 driver 默认注册表）。
+	Registry *bson.Registry
+
+	// OnDrop 在文档被丢弃时回调，可用于告警或落盘兜底；docs 为已分配 _id 的 bson.Raw。
+	OnDrop func(collection string, docs []any, err error)
+}
+
+// BufferMetrics 为 BufferedInserter 的累计指标快照。
+type BufferMetrics struct {
+	Buffered   uint64 `json:"buffered"`   // Buffered 为累计进入缓冲区的文档数。
+	Inserted   uint64 `json:"inserted"`   // Inserted 为累计成功写入的文档数。
+	Duplicates uint64 `json:"duplicates"` // Duplicates 为累计因重复主键/唯一键未写入的文档数（不重试也不丢弃）。
+	Dropped    uint64 `json:"dropped"`    // Dropped 为累计丢弃的文档数。
+	Flushes    uint64 `json:"flushes"`    // Flushes 为累计执行的 InsertMany 次数。
+	Retries    uint64 `json:"retries"`    // Retries 为累计重试次数。
+	Failures   uint64 `json:"failures"`   // Failures 为累计失败的 InsertMany 次数。
+	Pending    uint64 `json:"pending"`    // Pending 为当前缓冲区中等待写入的文档数。
+}
+
+// BufferedInserter 按集合累积文档，并在达到数量或时间阈值时通过 InsertMany 批量写入。
+type BufferedInserter struct {
+	db   *mongo.Database
+	conf BufferConf
+
+	mu      sync.Mutex
+	buffers map[string][]any
+	closed  bool
+
+	full chan string
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	buffered   atomic.Uint64
+	inserted   atomic.Uint64
+	duplicates atomic.Uint64
+	dropped    atomic.Uint64
+	flushes    atomic.Uint64
+	retries    atomic.Uint64
+	failures   atomic.Uint64
+}
+
+// NewBufferedInserter 创建 BufferedInserter 并启动后台 flush 协程。
+func NewBufferedInserter(db *mongo.Database, conf *BufferConf) *BufferedInserter {
+	c := BufferConf{}
+	if conf != nil {
+		c = *conf
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = 500
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = 3
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 100 * time.Millisecond
+	}
+	if c.FlushTimeout <= 0 {
+		c.FlushTimeout = 10 * time.Second
+	}
+
+	b := &BufferedInserter{
+		db:      db,
+		conf:    c,
+		buffers: make(map[string][]any),
+		full:    make(chan string, 64),
+		done:    make(chan struct{}),
+	}
+
+	b.wg.Add(1)
+	go b.loop()
+
+	return b
+}
+
+// Insert 将文档追加到指定集合的缓冲区，达到 MaxSize 时异步触发 flush。
+// 集合注册了访问策略时按无角色校验，需要按调用方角色校验时使用 InsertContext。
+func (b *BufferedInserter) Insert(collection string, docs ...any) error {
+	return b.InsertContext(context.Background(), collection, docs...)
+}
+
+// InsertContext 与 Insert 相同，入队前按 ctx 中的角色校验集合的访问策略（见 CheckInsert），违反时整批拒绝。
+// 入队以 insert 操作经过拦截器（After 收到的是入队结果而非写入结果），使用拦截器改写后的文档；
+// 文档在入队时按 BufferConf.Registry 编码并分配 _id，编码失败时整批拒绝。
+func (b *BufferedInserter) InsertContext(ctx context.Context, collection string, docs ...any) (err error) {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	op := &Operation{Name: "insert", Documents: docs}
+	ctx, finish, err := intercept(ctx, b.db.Collection(collection), op)
+	if err != nil {
+		return err
+	}
+	defer func() { finish(err) }()
+
+	if err := CheckInsert(ctx, b.db.Name()+"."+collection, op.Documents...); err != nil {
+		return err
+	}
+	docs, err = b.prepare(op.Documents)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBufferClosed
+	}
+
+	buf := b.buffers[collection]
+	var overflow []any
+	// 超出 MaxPending 的部分直接丢弃，避免下游不可用时内存无限增长。
+	if b.conf.MaxPending > 0 && len(buf)+len(docs) > b.conf.MaxPending {
+		keep := b.conf.MaxPending - len(buf)
+		if keep < 0 {
+			keep = 0
+		}
+		overflow = docs[keep:]
+		docs = docs[:keep]
+	}
+	buf = append(buf, docs...)
+	b.buffers[collection] = buf
+	reached := len(buf) >= b.conf.MaxSize
+	b.mu.Unlock()
+
+	b.buffered.Add(uint64(len(docs)))
+	if len(overflow) != 0 {
+		b.drop(collection, overflow, errors.New("mongo: buffer is full"))
+	}
+
+	if reached {
+		// 非阻塞通知后台协程，通知队列已满时交由定时 flush 兜底。
+		select {
+		case b.full <- collection:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush 立即写入所有集合的缓冲文档，返回遇到的错误（丢弃策略已生效）。
+func (b *BufferedInserter) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	names := make([]string, 0, len(b.buffers))
+	for name := range b.buffers {
+		names = append(names, name)
+	}
+	b.mu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if err := b.flushCollection(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close 停止后台协程并 flush 剩余文档，之后的 Insert 将返回 ErrBufferClosed。
+// 每次写入受 FlushTimeout 限制，ctx 可进一步缩短总的等待时间。
+func (b *BufferedInserter) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.done)
+	b.wg.Wait()
+
+	return b.Flush(ctx)
+}
+
+// Metrics 返回当前累计指标快照。
+func (b *BufferedInserter) Metrics() BufferMetrics {
+	b.mu.Lock()
+	var pending int
+	for _, buf := range b.buffers {
+		pending += len(buf)
+	}
+	b.mu.Unlock()
+
+	return BufferMetrics{
+		Buffered:   b.buffered.Load(),
+		Inserted:   b.inserted.Load(),
+		Duplicates: b.duplicates.Load(),
+		Dropped:    b.dropped.Load(),
+		Flushes:    b.flushes.Load(),
+		Retries:    b.retries.Load(),
+		Failures:   b.failures.Load(),
+		Pending:    uint64(pending),
+	}
+}
+
+func (b *BufferedInserter) loop() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case name := <-b.full:
+			_ = b.flushCollection(context.Background(), name)
+		case <-ticker.C:
+			_ = b.Flush(context.Background())
+		}
+	}
+}
+
+// flushCollection 取出指定集合的缓冲文档并按 MaxSize 分批写入。
+func (b *BufferedInserter) flushCollection(ctx context.Context, name string) error {
+	b.mu.Lock()
+	docs := b.buffers[name]
+	delete(b.buffers, name)
+	b.mu.Unlock()
+
+	var errs []error
+	for start := 0; start < len(docs); start += b.conf.MaxSize {
+		end := min(start+b.conf.MaxSize, len(docs))
+		if err := b.write(ctx, name, docs[start:end]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// write 执行一批 InsertMany，并按策略处理失败文档。文档的 _id 已在入队时分配，
+// 超时等结果未知的失败重试时，已写入的文档以重复主键返回，不会产生重复文档。
+func (b *BufferedInserter) write(ctx context.Context, name string, docs []any) error {
+	collection := b.db.Collection(name)
+	opts := options.InsertMany().SetOrdered(false)
+
+	attempts := 1
+	if b.conf.Policy == BufferPolicyRetry {
+		attempts += b.conf.MaxRetries
+	}
+
+	var err error
+	for i := 0; i < attempts && len(docs) != 0; i++ {
+		if i > 0 {
+			b.retries.Add(1)
+			select {
+			case <-ctx.Done():
+				b.drop(name, docs, ctx.Err())
+				return ctx.Err()
+			case <-time.After(b.conf.RetryBackoff):
+			}
+		}
+
+		b.flushes.Add(1)
+		total := len(docs)
+		insertCtx, cancel := context.WithTimeout(ctx, b.conf.FlushTimeout)
+		_, err = collection.InsertMany(insertCtx, docs, opts)
+		cancel()
+		if err == nil {
+			b.inserted.Add(uint64(total))
+			return nil
+		}
+
+		b.failures.Add(1)
+		// 只保留需要重试的文档：重复主键单独计数，确定性失败的文档立即丢弃，暂时性错误继续重试。
+		var rejected []any
+		var duplicates int
+		docs, rejected, duplicates = classifyFailure(docs, err)
+		b.duplicates.Add(uint64(duplicates))
+		b.inserted.Add(uint64(total - len(docs) - len(rejected) - duplicates))
+		if len(rejected) != 0 {
+			b.drop(name, rejected, err)
+		}
+	}
+
+	if len(docs) != 0 {
+		b.drop(name, docs, err)
+	}
+
+	return err
+}
+
+func (b *BufferedInserter) drop(name string, docs []any, err error) {
+	b.dropped.Add(uint64(len(docs)))
+	if b.conf.OnDrop != nil {
+		b.conf.OnDrop(name, docs, err)
+	}
+}
+
+// classifyFailure 将失败的批次分为需要重试的文档、确定性失败需要丢弃的文档与重复主键的文档数。
+// 单条文档的写错误（校验失败、文档过大等）重试也不会成功，直接丢弃。
+func classifyFailure(docs []any, err error) (retry, rejected []any, duplicates int) {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || len(bwe.WriteErrors) == 0 {
+		// 非写错误无法判断哪些已写入：暂时性错误全部重试（已写入的文档重试时以重复主键返回），其余全部丢弃。
+		if transientError(err) {
+			return docs, nil, 0
+		}
+		return nil, docs, 0
+	}
+
+	for _, we := range bwe.WriteErrors {
+		if we.HasErrorCode(11000) {
+			duplicates++
+			continue
+		}
+		if we.Index >= 0 && we.Index < len(docs) {
+			rejected = append(rejected, docs[we.Index])
+		}
+	}
+
+	return nil, rejected, duplicates
+}
+
+// transientError 判断错误是否为暂时性错误：网络错误、超时或带有 RetryableWriteError 标签的服务端错误。
+func transientError(err error) bool {
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorLabel("RetryableWriteError")
+}
+
+// prepare 将文档编码为 bson.Raw，并为缺少 _id 的文档分配 ObjectID，使重试沿用同一 _id。
+func (b *BufferedInserter) prepare(docs []any) ([]any, error) {
+	list := make([]any, 0, len(docs))
+	for i, doc := range docs {
+		raw, err := b.encode(doc)
+		if err != nil {
+			return nil, fmt.Errorf("mongo: encode buffered document %d: %w", i, err)
+		}
+		list = append(list, raw)
+	}
+	return list, nil
+}
+
+// encode 按 BufferConf.Registry 编码文档，缺少 _id 时在首位插入新的 ObjectID。
+func (b *BufferedInserter) encode(doc any) (bson.Raw, error) {
+	buf := new(bytes.Buffer)
+	enc := bson.NewEncoder(bson.NewDocumentWriter(buf))
+	if b.conf.Registry != nil {
+		enc.SetRegistry(b.conf.Registry)
+	}
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	raw := bson.Raw(buf.Bytes())
+	if _, err := raw.LookupErr("_id"); err == nil {
+		return raw, nil
+	}
+
+	id := bson.NewObjectID()
+	out := make([]byte, 4, len(raw)+len("_id")+14)
+	out = append(out, byte(bson.TypeObjectID))
+	out = append(out, "_id\x00"...)
+	out = append(out, id[:]...)
+	out = append(out, raw[4:]...)
+	binary.LittleEndian.PutUint32(out, uint32(len(out)))
+	return out, nil
+}
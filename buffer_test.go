@@ -0,0 +1,118 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	docs := []any{"a", "b", "c", "d"}
+	writeErrors := func(codes ...int) mongo.BulkWriteException {
+		var bwe mongo.BulkWriteException
+		for i, code := range codes {
+			if code != 0 {
+				bwe.WriteErrors = append(bwe.WriteErrors, mongo.BulkWriteError{WriteError: mongo.WriteError{Index: i, Code: code}})
+			}
+		}
+		return bwe
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		retry      int
+		rejected   []any
+		duplicates int
+	}{
+		{
+			name:  "timeout",
+			err:   fmt.Errorf("insert: %w", context.DeadlineExceeded),
+			retry: 4,
+		},
+		{
+			name:  "network error",
+			err:   mongo.CommandError{Code: 6, Labels: []string{"NetworkError"}},
+			retry: 4,
+		},
+		{
+			name:  "retryable write label",
+			err:   mongo.CommandError{Code: 189, Labels: []string{"RetryableWriteError"}},
+			retry: 4,
+		},
+		{
+			name:     "deterministic command error",
+			err:      mongo.CommandError{Code: 13, Name: "Unauthorized"},
+			rejected: docs,
+		},
+		{
+			name:     "unknown error",
+			err:      errors.New("boom"),
+			rejected: docs,
+		},
+		{
+			name:       "duplicates and validation failures",
+			err:        writeErrors(11000, 0, 121, 11000),
+			rejected:   []any{"c"},
+			duplicates: 2,
+		},
+		{
+			name:       "only duplicates",
+			err:        writeErrors(0, 11000),
+			duplicates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, rejected, duplicates := classifyFailure(docs, tt.err)
+			if len(retry) != tt.retry {
+				t.Errorf("retry = %v, want %d documents", retry, tt.retry)
+			}
+			if fmt.Sprint(rejected) != fmt.Sprint(tt.rejected) {
+				t.Errorf("rejected = %v, want %v", rejected, tt.rejected)
+			}
+			if duplicates != tt.duplicates {
+				t.Errorf("duplicates = %d, want %d", duplicates, tt.duplicates)
+			}
+		})
+	}
+}
+
+// TestBufferedInserterCloseBounded 确认下游不可用时 Close 受 FlushTimeout 限制，文档按策略丢弃。
+func TestBufferedInserterCloseBounded(t *testing.T) {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	var dropped int
+	b := NewBufferedInserter(client.Database("buffer_test"), &BufferConf{
+		Interval:     time.Hour,
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+		FlushTimeout: 50 * time.Millisecond,
+		OnDrop:       func(collection string, docs []any, err error) { dropped += len(docs) },
+	})
+	if err := b.Insert("events", bson.D{{Key: "n", Value: 1}}, bson.D{{Key: "n", Value: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := b.Close(context.Background()); !mongo.IsTimeout(err) {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close took %s", elapsed)
+	}
+	if m := b.Metrics(); dropped != 2 || m.Dropped != 2 || m.Retries != 1 {
+		t.Errorf("dropped = %d, metrics = %+v", dropped, m)
+	}
+}
@@ -0,0 +1,287 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Relation 声明父子集合关系：Child 集合的 ForeignKey 字段引用 Parent 集合的 _id。
+type Relation struct {
+	Parent     string `json:"parent"`
+	Child      string `json:"child"`
+	ForeignKey string `json:"foreign_key"`
+}
+
+// IntegrityIssue 为 VerifyIntegrity 发现的孤儿文档。
+type IntegrityIssue struct {
+	Relation Relation `json:"relation"`
+	ChildId  string   `json:"child_id"`
+	ParentId string   `json:"parent_id"`
+	// Reason 为 missing（父文档不存在）或 deleted（父文档已软删除）。
+	Reason string `json:"reason"`
+}
+
+const (
+	IntegrityParentMissing = "missing" // IntegrityParentMissing 父文档不存在。
+	IntegrityParentDeleted = "deleted" // IntegrityParentDeleted 父文档已软删除。
+)
+
+// Cascade 按声明的关系在集合之间传播软删除与恢复。
+type Cascade struct {
+	db        *mongo.Database
+	relations []Relation
+}
+
+// NewCascade 创建级联处理器。
+func NewCascade(db *mongo.Database, relations ...Relation) *Cascade {
+	return &Cascade{db: db, relations: relations}
+}
+
+// SoftDelete 软删除指定文档，并以相同的 deleted_at 递归软删除未删除的子文档。
+func (c *Cascade) SoftDelete(ctx context.Context, collection string, ids []string) error {
+	if err := ValidateIDs(ids); err != nil {
+		return err
+	}
+	return c.softDelete(ctx, collection, ids, time.Now().UTC())
+}
+
+func (c *Cascade) softDelete(ctx context.Context, collection string, ids []string, timer time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := c.updateMany(ctx, &Operation{Name: "cascade_soft_delete", Ids: ids, Filter: bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "deleted_at", Value: nil},
+	}, Update: bson.D{
+		{Key: "$set", Value: bson.M{
+			"updated_at": timer,
+			"deleted_at": timer,
+		}},
+	}}, collection)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range c.children(collection) {
+		childIds, err := c.ids(ctx, rel.Child, bson.D{
+			{Key: rel.ForeignKey, Value: bson.D{{Key: "$in", Value: ids}}},
+			{Key: "deleted_at", Value: nil},
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.softDelete(ctx, rel.Child, childIds, timer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore 恢复指定文档，并递归恢复与父文档同一时刻被级联删除的子文档（单独删除的子文档保持删除状态）。
+func (c *Cascade) Restore(ctx context.Context, collection string, ids []string) error {
+	if err := ValidateIDs(ids); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// 按 deleted_at 分组，便于只恢复同一次级联删除产生的子文档。
+	var docs []struct {
+		Id        string    `bson:"_id"`
+		DeletedAt time.Time `bson:"deleted_at"`
+	}
+	err := c.find(ctx, collection, &Operation{Name: "cascade_restore", Ids: ids, Filter: bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}},
+	}}, bson.D{{Key: "deleted_at", Value: 1}}, &docs)
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[time.Time][]string)
+	for _, doc := range docs {
+		groups[doc.DeletedAt] = append(groups[doc.DeletedAt], doc.Id)
+	}
+
+	for deletedAt, group := range groups {
+		if err := c.restore(ctx, collection, group, deletedAt, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cascade) restore(ctx context.Context, collection string, ids []string, deletedAt, timer time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := c.updateMany(ctx, &Operation{Name: "cascade_restore", Ids: ids, Filter: bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+	}, Update: bson.D{
+		{Key: "$set", Value: bson.M{"updated_at": timer}},
+		{Key: "$unset", Value: bson.M{"deleted_at": ""}},
+	}}, collection)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range c.children(collection) {
+		childIds, err := c.ids(ctx, rel.Child, bson.D{
+			{Key: rel.ForeignKey, Value: bson.D{{Key: "$in", Value: ids}}},
+			{Key: "deleted_at", Value: deletedAt},
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.restore(ctx, rel.Child, childIds, deletedAt, timer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyIntegrity 检查所有关系中未删除的子文档，报告父文档缺失或已软删除的情况；外键字段对 ctx 角色不可读时 ParentId 为空。
+func (c *Cascade) VerifyIntegrity(ctx context.Context) ([]IntegrityIssue, error) {
+	var issues []IntegrityIssue
+
+	for _, rel := range c.relations {
+		rows, err := c.orphans(ctx, rel)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			issue := IntegrityIssue{
+				Relation: rel,
+				ChildId:  row.Id,
+				ParentId: row.ParentId,
+				Reason:   IntegrityParentDeleted,
+			}
+			if row.Missing {
+				issue.Reason = IntegrityParentMissing
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// orphanRow 为 orphans 查询的单行结果。
+type orphanRow struct {
+	Id       string `bson:"_id"`
+	ParentId string `bson:"parent_id"`
+	Missing  bool   `bson:"missing"`
+}
+
+// orphans 以 verify_integrity 操作经过拦截器，查询关系中父文档缺失或已软删除的子文档。
+func (c *Cascade) orphans(ctx context.Context, rel Relation) (rows []orphanRow, err error) {
+	collection := c.db.Collection(rel.Child)
+	op := &Operation{Name: "verify_integrity", Filter: bson.D{{Key: "deleted_at", Value: nil}}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: op.Filter}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: rel.Parent},
+			{Key: "localField", Value: rel.ForeignKey},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "_parent"},
+			{Key: "pipeline", Value: mongo.Pipeline{
+				{{Key: "$project", Value: bson.D{{Key: "deleted_at", Value: 1}}}},
+			}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "_parent", Value: bson.D{{Key: "$size", Value: 0}}}},
+			bson.D{{Key: "_parent.deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}}},
+		}}}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "parent_id", Value: "$" + rel.ForeignKey},
+			{Key: "missing", Value: bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$size", Value: "$_parent"}}, 0}}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	// 外键字段对 ctx 角色不可读时不返回父文档 id。
+	if !readable(ctx, PolicyNamespace(collection), rel.ForeignKey) {
+		for i := range rows {
+			rows[i].ParentId = ""
+		}
+	}
+	return rows, nil
+}
+
+func (c *Cascade) children(parent string) []Relation {
+	var list []Relation
+	for _, rel := range c.relations {
+		if rel.Parent == parent {
+			list = append(list, rel)
+		}
+	}
+	return list
+}
+
+func (c *Cascade) ids(ctx context.Context, collection string, filter bson.D) ([]string, error) {
+	var docs []struct {
+		Id string `bson:"_id"`
+	}
+	if err := c.find(ctx, collection, &Operation{Name: "find", Filter: filter}, bson.D{{Key: "_id", Value: 1}}, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.Id)
+	}
+	return ids, nil
+}
+
+// find 经拦截器以 op.Filter 查询 collection，按 projection 解码到 out。
+func (c *Cascade) find(ctx context.Context, name string, op *Operation, projection bson.D, out any) (err error) {
+	collection := c.db.Collection(name)
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return err
+	}
+	defer func() { finish(err) }()
+
+	cursor, err := collection.Find(ctx, op.Filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, out)
+}
+
+// updateMany 经拦截器以 op.Filter/op.Update 更新 collection。
+func (c *Cascade) updateMany(ctx context.Context, op *Operation, name string) (err error) {
+	collection := c.db.Collection(name)
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	_, err = collection.UpdateMany(ctx, op.Filter, op.Update)
+	return err
+}
@@ -0,0 +1,223 @@
+package mongo
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// Client 封装 driver client 与默认数据库句柄，用于管理连接生命周期以及复用同一连接访问其他数据库。
+// Reconnect/RotateCredentials 会替换底层 driver client，需要长期持有时应持有 Client 并在使用时调用 DB()。
+type Client struct {
+	client   atomic.Pointer[mongo.Client]
+	database string
+	readPref *readpref.ReadPref
+	// readsPref 为 Reads 句柄的读偏好。
+	readsPref *readpref.ReadPref
+	// bson 为建立连接时的编解码配置，按库覆盖时区时在其基础上修改。
+	bson *BSONConf
+	// logger/output 为建立连接时的日志开关与本地输出目标，用于输出连接监护事件。
+	logger bool
+	output internal.Output
+
+	// conf 为建立连接时的配置副本，重新连接时复用。
+	conf *Conf
+	// reconnectMu 保证同一时刻只有一次重新连接。
+	reconnectMu sync.Mutex
+}
+
+// NewClient 根据配置创建 MongoDB 连接并返回 Client。
+func NewClient(c *Conf) (*Client, error) {
+	return NewClientWithContext(context.Background(), c)
+}
+
+// NewClientWithContext 与 NewClient 相同，建立连接受 ctx 控制，ctx 未设置截止时间时使用 10s 超时。
+// Conf.Shared 为 true 时，规范化后配置相同的调用返回同一个 Client。
+func NewClientWithContext(ctx context.Context, c *Conf) (*Client, error) {
+	if c != nil && c.Shared {
+		return acquireShared(ctx, c)
+	}
+	return newClient(ctx, c)
+}
+
+// newClient 建立新连接并封装为 Client。
+func newClient(ctx context.Context, c *Conf) (*Client, error) {
+	client, err := connect(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// connect 已校验过读偏好配置，这里不会出错。
+	rp, _ := c.readPref()
+	readsPref, _ := c.SplitReads.readPref()
+	conf := *c
+	wrapper := &Client{
+		database:  c.Database,
+		readPref:  rp,
+		readsPref: readsPref,
+		bson:      c.BSON,
+		logger:    c.Logger,
+		output:    c.output(),
+		conf:      &conf,
+	}
+	wrapper.client.Store(client)
+	return wrapper, nil
+}
+
+// Raw 返回底层 driver client。
+func (c *Client) Raw() *mongo.Client {
+	return c.client.Load()
+}
+
+// DB 返回 Conf.Database 对应的默认数据库句柄。
+func (c *Client) DB() *mongo.Database {
+	return c.Raw().Database(c.database)
+}
+
+// Database 返回同一连接下的其他数据库句柄。
+func (c *Client) Database(name string, opts ...options.Lister[options.DatabaseOptions]) *mongo.Database {
+	return c.Raw().Database(name, opts...)
+}
+
+// DatabaseWithTimeZone 返回按指定时区解码时间的数据库句柄：local 为 true 时解码为本地时区，否则为 UTC。
+// 其余编解码行为沿用 Conf.BSON。
+func (c *Client) DatabaseWithTimeZone(name string, local bool, opts ...options.Lister[options.DatabaseOptions]) *mongo.Database {
+	conf := BSONConf{}
+	if c.bson != nil {
+		conf = *c.bson
+	}
+	conf.UseLocalTimeZone = local
+
+	return c.Raw().Database(name, append(opts, options.Database().SetBSONOptions(conf.bsonOptions()))...)
+}
+
+// StartSession 开启会话，用于事务或因果一致性读。
+func (c *Client) StartSession(opts ...options.Lister[options.SessionOptions]) (*mongo.Session, error) {
+	return c.Raw().StartSession(opts...)
+}
+
+// Reconnect 按建立连接时的配置重新连接（会重新从 SecretsProvider 读取密码与证书），
+// 新连接 Ping 成功后替换底层 client，旧连接在后台等待进行中的操作完成后关闭（最多 DefaultDrainTimeout）。
+// 重新连接失败时继续使用旧连接并返回错误。适用于 Vault 等定期轮换密码的场景。
+func (c *Client) Reconnect(ctx context.Context) error {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	return c.redial(ctx, c.conf)
+}
+
+// RotateCredentials 使用新的用户名与密码重新连接，成功后后续 Reconnect 也使用新凭证；语义同 Reconnect。
+func (c *Client) RotateCredentials(ctx context.Context, username, password string) error {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	conf := *c.conf
+	conf.Username = username
+	conf.Password = password
+	if err := c.redial(ctx, &conf); err != nil {
+		return err
+	}
+	c.conf = &conf
+	return nil
+}
+
+// redial 建立新连接并替换旧连接，按 client 登记的拦截器随之迁移。
+func (c *Client) redial(ctx context.Context, conf *Conf) error {
+	next, err := connect(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	interceptorsMu.Lock()
+	prev := c.client.Swap(next)
+	if list, ok := interceptors.Load(weak.Make(prev)); ok {
+		interceptors.Store(trackClient(next), list)
+	}
+	if entry, ok := sharedByClient.LoadAndDelete(prev); ok {
+		sharedByClient.Store(next, entry)
+	}
+	interceptorsMu.Unlock()
+
+	go func() {
+		drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DefaultDrainTimeout)
+		defer cancel()
+		_ = disconnect(drainCtx, prev)
+	}()
+	return nil
+}
+
+// Disconnect 立即关闭连接池中的所有连接；Conf.Shared 复用的连接同样会被关闭，需要按引用计数释放时使用 Close。
+// 本包按 client 登记的状态（拦截器、能力缓存、日志文件等）随之清理；直接调用 driver 的 Disconnect 时，这些状态在 client 被回收后释放。
+func (c *Client) Disconnect(ctx context.Context) error {
+	return disconnect(ctx, c.Raw())
+}
+
+// Close 优雅关闭连接：等待进行中的操作归还连接后再关闭，超过 drain 时间仍未归还的连接被强制关闭。
+// ctx 未设置截止时间时使用 DefaultDrainTimeout。
+func (c *Client) Close(ctx context.Context) error {
+	return Close(ctx, c.DB())
+}
+
+// DefaultDrainTimeout 为 Close 等待进行中操作完成的默认时间。
+const DefaultDrainTimeout = 15 * time.Second
+
+// Close 优雅关闭 New 返回的数据库句柄所属的连接，语义同 Client.Close。
+// Conf.Shared 复用的连接每次 Close 释放一个引用，最后一个引用释放时才关闭。
+func Close(ctx context.Context, db *mongo.Database) error {
+	if !releaseClient(db.Client()) {
+		return nil
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultDrainTimeout)
+		defer cancel()
+	}
+	return disconnect(ctx, db.Client())
+}
+
+// disconnect 清理本包按 client 登记的状态并关闭连接；driver 会等待使用中的连接归还，直到 ctx 结束。
+func disconnect(ctx context.Context, client *mongo.Client) error {
+	key := weak.Make(client)
+	forgetShared(client)
+	ForgetCapabilities(client)
+	writeTimeouts.Delete(key)
+	interceptors.Delete(key)
+	err := client.Disconnect(ctx)
+	// 日志文件在连接关闭后释放，排空期间结束的命令仍可写入。
+	releaseLogFile(key)
+	return err
+}
+
+// clientKey 为按 driver client 登记状态（capabilities、writeTimeouts、interceptors、logFileByClient）的键。
+// 键为弱指针，不阻止 client 被回收：直接调用 driver 的 Disconnect 而未经过 Client.Disconnect/Close 时，
+// 状态在 client 被回收后由 trackClient 注册的清理函数删除，日志文件随之释放。
+type clientKey = weak.Pointer[mongo.Client]
+
+// tracked 记录已注册清理函数的 client。
+var tracked sync.Map
+
+// trackClient 返回 client 的键，首次登记状态时注册 client 被回收后的清理。
+func trackClient(client *mongo.Client) clientKey {
+	key := weak.Make(client)
+	if _, loaded := tracked.LoadOrStore(key, struct{}{}); !loaded {
+		runtime.AddCleanup(client, forgetClient, key)
+	}
+	return key
+}
+
+// forgetClient 删除按 client 登记的全部状态并释放日志文件。
+func forgetClient(key clientKey) {
+	tracked.Delete(key)
+	capabilities.Delete(key)
+	writeTimeouts.Delete(key)
+	interceptors.Delete(key)
+	releaseLogFile(key)
+}
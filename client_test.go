@@ -0,0 +1,72 @@
+package mongo
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+	"weak"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TestClientStateReleased 确认直接调用 driver 的 Disconnect 后，client 被回收时按 client 登记的状态随之删除。
+func TestClientStateReleased(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    *sync.Map
+		register func(client *mongo.Client)
+	}{
+		{
+			name:  "interceptors",
+			state: &interceptors,
+			register: func(client *mongo.Client) {
+				UseInterceptors(client, InterceptorFuncs{})
+			},
+		},
+		{
+			name:  "write timeouts",
+			state: &writeTimeouts,
+			register: func(client *mongo.Client) {
+				writeTimeouts.Store(trackClient(client), time.Second)
+			},
+		},
+		{
+			name:  "capabilities",
+			state: &capabilities,
+			register: func(client *mongo.Client) {
+				capabilities.Store(trackClient(client), &ServerInfo{})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := func() clientKey {
+				client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				tt.register(client)
+				if err := client.Disconnect(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				return weak.Make(client)
+			}()
+
+			if _, ok := tt.state.Load(key); !ok {
+				t.Fatal("state was not registered")
+			}
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+				runtime.GC()
+				if _, ok := tt.state.Load(key); !ok {
+					return
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			t.Error("state was not released after the client was collected")
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// mongogen 读取带 bson tag 的结构体，生成类型化字段（field.Field[T]），用于构造过滤、排序与投影。
+//
+// 用法：
+//
+//	//go:generate go run github.com/fireflycore/go-mongo/cmd/mongogen -type User
+//
+// 生成 user_fields.go，其中 UserFields.Email.Eq("a@b.c") 等价于 bson.E{Key: "email", Value: "a@b.c"}。
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const fieldPkg = "github.com/fireflycore/go-mongo/field"
+
+// maxDepth 为嵌套结构体展开的最大层数。
+const maxDepth = 4
+
+type genField struct {
+	name string
+	path string
+	typ  string
+}
+
+func main() {
+	typeNames := flag.String("type", "", "逗号分隔的结构体类型名（必填）")
+	output := flag.String("output", "", "输出文件名（默认 <type>_fields.go）")
+	dir := flag.String("dir", ".", "包目录")
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pkg, err := loadPackage(*dir, *output)
+	if err != nil {
+		log.Fatalf("mongogen: %v", err)
+	}
+
+	names := strings.Split(*typeNames, ",")
+	imports := map[string]string{"field": fieldPkg}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			log.Fatalf("mongogen: type %s not found", name)
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			log.Fatalf("mongogen: %s is not a struct", name)
+		}
+
+		qualifier := func(p *types.Package) string {
+			if p == pkg {
+				return ""
+			}
+			imports[p.Name()] = p.Path()
+			return p.Name()
+		}
+
+		var fields []genField
+		collect(st, "", "", qualifier, 0, map[types.Type]bool{}, &fields)
+		writeFields(&body, name, fields)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by mongogen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg.Name())
+	// 标准库在前、第三方在后，与 goimports 分组一致。
+	aliases := make([]string, 0, len(imports))
+	for alias := range imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		a, b := imports[aliases[i]], imports[aliases[j]]
+		if isStd(a) != isStd(b) {
+			return isStd(a)
+		}
+		return a < b
+	})
+	for i, alias := range aliases {
+		path := imports[alias]
+		if i > 0 && isStd(imports[aliases[i-1]]) && !isStd(path) {
+			buf.WriteString("\n")
+		}
+		if filepath.Base(path) == alias {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", alias, path)
+		}
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("mongogen: format generated code: %v\n%s", err, buf.String())
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.ToLower(names[0]) + "_fields.go"
+	}
+	if err := os.WriteFile(filepath.Join(*dir, out), src, 0o644); err != nil {
+		log.Fatalf("mongogen: %v", err)
+	}
+}
+
+// loadPackage 解析并类型检查目录下的包，跳过测试文件与上一次生成的输出文件。
+func loadPackage(dir, output string) (*types.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != output && !strings.HasSuffix(name, "_fields.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range pkgs {
+		files := make([]*ast.File, 0, len(p.Files))
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		return conf.Check(p.Name, fset, files, nil)
+	}
+	return nil, fmt.Errorf("no Go package in %s", dir)
+}
+
+// collect 递归收集结构体字段：inline/匿名嵌入的结构体平铺，具名嵌套结构体同时生成自身与子字段。
+func collect(st *types.Struct, namePrefix, pathPrefix string, qualifier types.Qualifier, depth int, seen map[types.Type]bool, out *[]genField) {
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		if !v.Exported() {
+			continue
+		}
+
+		key, inline, skip := bsonKey(v, reflect.StructTag(st.Tag(i)))
+		if skip {
+			continue
+		}
+
+		if inline {
+			if inner, ok := structOf(v.Type()); ok && !seen[v.Type()] {
+				seen[v.Type()] = true
+				collect(inner, namePrefix, pathPrefix, qualifier, depth, seen, out)
+				delete(seen, v.Type())
+			}
+			continue
+		}
+
+		name := namePrefix + v.Name()
+		path := key
+		if pathPrefix != "" {
+			path = pathPrefix + "." + key
+		}
+		*out = append(*out, genField{name: name, path: path, typ: types.TypeString(v.Type(), qualifier)})
+
+		if inner, ok := structOf(v.Type()); ok && depth < maxDepth && !seen[v.Type()] {
+			seen[v.Type()] = true
+			collect(inner, name, path, qualifier, depth+1, seen, out)
+			delete(seen, v.Type())
+		}
+	}
+}
+
+// bsonKey 按 mongo-driver 规则解析字段名：tag 名优先，否则为字段名小写；匿名字段未指定名称时视为 inline。
+func bsonKey(v *types.Var, tag reflect.StructTag) (key string, inline, skip bool) {
+	bsonTag, hasTag := tag.Lookup("bson")
+	if bsonTag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(bsonTag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	if v.Anonymous() && (!hasTag || name == "") {
+		inline = true
+	}
+	if name == "" {
+		name = strings.ToLower(v.Name())
+	}
+	return name, inline, false
+}
+
+// structOf 返回可展开的结构体类型（time.Time 等外部值类型视为标量）。
+func structOf(t types.Type) (*types.Struct, bool) {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" {
+		return nil, false
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || st.NumFields() == 0 {
+		return nil, false
+	}
+	return st, true
+}
+
+func writeFields(buf *bytes.Buffer, typeName string, fields []genField) {
+	varName := typeName + "Fields"
+	fmt.Fprintf(buf, "\n// %s 为 %s 的类型化字段。\n", varName, typeName)
+	fmt.Fprintf(buf, "var %s = struct {\n", varName)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\t%s field.Field[%s]\n", f.name, f.typ)
+	}
+	buf.WriteString("}{\n")
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\t%s: %q,\n", f.name, f.path)
+	}
+	buf.WriteString("}\n")
+}
+
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func init() {
+	log.SetFlags(0)
+}
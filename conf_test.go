@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TestBsonRegistry 确认注册回调只执行一次，重连与复制的 Conf 复用同一个注册表。
+func TestBsonRegistry(t *testing.T) {
+	custom := bson.NewRegistry()
+
+	tests := []struct {
+		name     string
+		setup    func(c *Conf, register func(reg *bson.Registry))
+		calls    int
+		none     bool
+		shared   bool
+		isCustom bool
+	}{
+		{name: "default", setup: func(*Conf, func(*bson.Registry)) {}, none: true},
+		{
+			name:  "decimal only",
+			setup: func(c *Conf, _ func(*bson.Registry)) { c.Decimal128 = true },
+		},
+		{
+			name:   "codecs",
+			setup:  func(c *Conf, register func(*bson.Registry)) { c.WithCodecs(register) },
+			calls:  1,
+			shared: true,
+		},
+		{
+			name:     "custom registry",
+			setup:    func(c *Conf, _ func(*bson.Registry)) { c.WithRegistry(custom) },
+			shared:   true,
+			isCustom: true,
+		},
+		{
+			name: "codecs on a custom registry",
+			setup: func(c *Conf, register func(*bson.Registry)) {
+				c.WithRegistry(custom)
+				c.WithCodecs(register)
+			},
+			calls:    1,
+			shared:   true,
+			isCustom: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			conf := &Conf{}
+			tt.setup(conf, func(*bson.Registry) { calls++ })
+
+			first := conf.bsonRegistry()
+			copied := *conf
+			second := copied.bsonRegistry()
+			third := conf.bsonRegistry()
+
+			if tt.none {
+				if first != nil || second != nil {
+					t.Fatalf("registry = %p, want nil", first)
+				}
+				return
+			}
+			if first == nil {
+				t.Fatal("registry = nil")
+			}
+			if (first == second && first == third) != tt.shared {
+				t.Errorf("registries = %p %p %p, shared want %v", first, second, third, tt.shared)
+			}
+			if (first == custom) != tt.isCustom {
+				t.Errorf("registry = %p, custom = %p", first, custom)
+			}
+			if calls != tt.calls {
+				t.Errorf("register calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -3,73 +3,180 @@ package mongo
 import (
 	"context"
 	"errors"
-	"fmt"
-	"net"
-	"sync"
 	"time"
 
 	"github.com/fireflycore/go-mongo/internal"
-	"github.com/fireflycore/go-utils/network"
-	"github.com/fireflycore/go-utils/tlsx"
 	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
-	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/v2/mongo/otelmongo"
 )
 
-// New 根据配置创建 MongoDB 连接并返回数据库句柄。
+// defaultConnectTimeout 为 ctx 未设置截止时间时，建立连接与 Ping 的超时时间。
+const defaultConnectTimeout = 10 * time.Second
+
+// lazyStartupLogTimeout 为 LazyConnect 时后台输出启动摘要前等待服务端可用的时间。
+const lazyStartupLogTimeout = time.Minute
+
+// New 根据配置创建 MongoDB 连接并返回数据库句柄，等价于 NewWithContext(context.Background(), c)。
+// 需要管理连接生命周期或访问其他数据库时使用 NewClient。
 func New(c *Conf) (*mongo.Database, error) {
+	return NewWithContext(context.Background(), c)
+}
+
+// NewWithContext 与 New 相同，但读取密钥、建立连接与 Ping 均受 ctx 控制；
+// ctx 未设置截止时间时使用 10s 超时。
+func NewWithContext(ctx context.Context, c *Conf) (*mongo.Database, error) {
+	client, err := NewClientWithContext(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.DB(), nil
+}
+
+// connect 根据配置建立连接并完成 Ping 校验。
+func connect(ctx context.Context, c *Conf) (*mongo.Client, error) {
 	if c == nil {
 		return nil, errors.New("mongo: conf is nil")
 	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
 
-	host, port, err := network.SplitHostPort(c.Address, "27017")
+	uri, err := c.URI()
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultConnectTimeout)
+		defer cancel()
+	}
 
-	clientOptions := options.Client()
+	// 先把 URI 应用到 clientOptions（mongodb+srv 会在连接时解析 SRV/TXT 记录），再写入 Conf 中的认证与 TLS 配置：
+	// mongodb+srv 隐含 tls=true，ApplyURI 会以空 tls.Config 覆盖此前设置的 TLSConfig。
+	clientOptions := options.Client().ApplyURI(uri)
 
 	// 启用 otelmongo 插件（Tracing），自动记录 Mongo 命令 Span
-	clientOptions.Monitor = otelmongo.NewMonitor(otelmongo.WithCommandAttributeDisabled(false))
+	clientOptions.Monitor = c.tracingMonitor()
 
-	if c.Username != "" {
-		credential := options.Credential{
-			Username: c.Username,
+	// username/password 默认取自 Conf，配置了 SecretsProvider 时以其返回的非空值为准。
+	username, password := c.Username, c.Password
+	var secrets *Secrets
+	if c.secrets != nil {
+		if secrets, err = c.secrets.Secrets(ctx); err != nil {
+			return nil, err
+		}
+		if secrets == nil {
+			secrets = &Secrets{}
 		}
-		if c.Password != "" {
-			credential.Password = c.Password
+		if secrets.Username != "" {
+			username = secrets.Username
 		}
-		clientOptions.SetAuth(credential)
+		if secrets.Password != "" {
+			password = secrets.Password
+		}
+	}
+
+	if credential := c.credential(username, password); credential != nil {
+		clientOptions.SetAuth(*credential)
 	}
 
 	// 从配置生成 TLSConfig；tlsEnabled 表示是否启用 TLS。
-	tlsConfig, tlsEnabled, err := tlsx.NewTLSConfig(c.Tls)
+	tlsConfig, err := c.TLSConfig()
 	// TLS 配置构造失败时直接返回错误。
 	if err != nil {
 		return nil, err
 	}
+	tlsEnabled := tlsConfig != nil
+	// SecretsProvider 提供了完整证书时，优先使用其 PEM 内容。
+	if secrets != nil && secrets.hasTLS() {
+		if tlsConfig, err = secrets.tlsConfig(); err != nil {
+			return nil, err
+		}
+		tlsConfig.InsecureSkipVerify = c.TlsInsecureSkipVerify
+		tlsEnabled = true
+	} else if tlsEnabled && len(tlsConfig.Certificates) != 0 && c.TlsReload {
+		// 客户端证书由 cert-manager 等续期时，新建连接自动使用新证书。
+		reloader, err := newCertReloader(c.Tls.ClientCert, c.Tls.ClientCertKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = nil
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
 	// 启用 TLS 时，将 TLSConfig 写入 clientOptions。
 	if tlsEnabled {
 		// 由 driver 使用该 TLS 配置建立安全连接。
 		clientOptions.TLSConfig = tlsConfig
-		clientOptions.TLSConfig.ServerName = host
+		clientOptions.TLSConfig.ServerName = c.tlsServerName()
+	}
+
+	if name := c.appName(); name != "" {
+		clientOptions.SetAppName(name)
+	}
+
+	autoEncryption, err := c.Encryption.autoEncryptionOptions()
+	if err != nil {
+		return nil, err
+	}
+	if autoEncryption != nil {
+		clientOptions.SetAutoEncryptionOptions(autoEncryption)
+	}
+
+	serverAPI, err := c.ServerAPI.serverAPIOptions()
+	if err != nil {
+		return nil, err
+	}
+	if serverAPI != nil {
+		clientOptions.SetServerAPIOptions(serverAPI)
+	}
+
+	if len(c.Compressors) != 0 {
+		clientOptions.SetCompressors(c.Compressors)
+		if c.ZlibLevel != 0 {
+			clientOptions.SetZlibLevel(c.ZlibLevel)
+		}
+		if c.ZstdLevel != 0 {
+			clientOptions.SetZstdLevel(c.ZstdLevel)
+		}
+	}
+
+	if c.RetryWrites != nil {
+		clientOptions.SetRetryWrites(*c.RetryWrites)
+	}
+	if c.RetryReads != nil {
+		clientOptions.SetRetryReads(*c.RetryReads)
+	}
+
+	if wc := c.WriteConcern.writeConcern(); wc != nil {
+		clientOptions.SetWriteConcern(wc)
 	}
 
-	// 将 address 组装为 MongoDB 标准 URI。
-	uri := fmt.Sprintf("mongodb://%s", net.JoinHostPort(host, port))
-	// 把 URI 应用到 clientOptions。
-	clientOptions.ApplyURI(uri)
+	// 配置了读偏好时同时用于启动 Ping，避免 secondaryPreferred 等模式在无主节点时启动失败。
+	pingPref := readpref.Primary()
+	rp, err := c.readPref()
+	if err != nil {
+		return nil, err
+	}
+	if rp != nil {
+		clientOptions.SetReadPreference(rp)
+		pingPref = rp
+	}
 
-	// 设置 BSON 编解码行为。
-	clientOptions.SetBSONOptions(&options.BSONOptions{
-		UseLocalTimeZone: false, // 关闭本地时区，减少环境差异带来的时间解析偏差。
-	})
+	// 设置 BSON 编解码行为；未配置 BSON 时使用 driver 默认行为（时间按 UTC 解码）。
+	if bsonOptions := c.BSON.bsonOptions(); bsonOptions != nil {
+		clientOptions.SetBSONOptions(bsonOptions)
+	}
+	if registry := c.bsonRegistry(); registry != nil {
+		clientOptions.SetRegistry(registry)
+	}
 
+	if c.MinOpenConnects > 0 {
+		clientOptions.SetMinPoolSize(uint64(c.MinOpenConnects))
+	}
 	if c.MaxOpenConnects > 0 {
 		// 设置连接池最大连接数。
 		clientOptions.SetMaxPoolSize(uint64(c.MaxOpenConnects))
@@ -78,84 +185,90 @@ func New(c *Conf) (*mongo.Database, error) {
 		// 设置最大空闲时间。
 		clientOptions.SetMaxConnIdleTime(time.Second * time.Duration(c.ConnMaxLifeTime))
 	}
+	if c.Timeout > 0 {
+		// 客户端级超时（CSOT）：ctx 未设置截止时间的操作同样受该超时约束。
+		clientOptions.SetTimeout(time.Millisecond * time.Duration(c.Timeout))
+	}
+	if c.ServerSelectionTimeout > 0 {
+		// 没有满足读偏好的可用节点时，最多等待该时长后返回 server selection 错误。
+		clientOptions.SetServerSelectionTimeout(time.Millisecond * time.Duration(c.ServerSelectionTimeout))
+	}
+	if c.ConnectTimeout > 0 {
+		clientOptions.SetConnectTimeout(time.Millisecond * time.Duration(c.ConnectTimeout))
+	}
+	dialer, err := c.contextDialer()
+	if err != nil {
+		return nil, err
+	}
+	if dialer != nil {
+		clientOptions.SetDialer(dialer)
+	}
+	if c.LoadBalanced {
+		// 经由支持 MongoDB 协议的 L4 负载均衡器连接，driver 不再做节点发现与心跳。
+		clientOptions.SetLoadBalanced(true)
+	}
+	if c.HeartbeatInterval > 0 {
+		// 缩短节点心跳间隔可更快发现主节点切换（driver 要求不小于 500ms）。
+		clientOptions.SetHeartbeatInterval(time.Millisecond * time.Duration(c.HeartbeatInterval))
+	}
 
 	// 启用日志时，安装命令监控器以采集 Mongo 命令执行信息。
 	// 注意：go-mongo 原有 Logger 是通过 Monitor 实现的。
 	// 由于 clientOptions.Monitor 只能设置一个，而 otelmongo 也是一个 Monitor。
-	// 为了同时支持 Logs 和 Traces，我们需要把 otelmongo 和原有 Logger Monitor 串联起来。
-	// 但 mongo-driver 目前没有 ChainMonitor 的公开方法。
-	// 幸好 otelmongo.NewMonitor 返回的是 *event.CommandMonitor。
-	// 我们可以在原有 Monitor 的基础上，把 otelmongo 的回调函数合并进去。
-	otelMonitor := clientOptions.Monitor // 这是上面刚设置的 otelmongo monitor
+	// 为了同时支持 Logs 和 Traces（以及通过 WithCommandMonitor 追加的监控器），
+	// 这里把所有 Monitor 收集起来，再由 chainCommandMonitors 串联为一个。
+	monitors := []*event.CommandMonitor{clientOptions.Monitor} // 第一个是上面刚设置的 otelmongo monitor（关闭 Tracing 时为 nil）
+
+	metricsMonitor, err := c.metricsMonitor()
+	if err != nil {
+		return nil, err
+	}
+	monitors = append(monitors, metricsMonitor)
+
+	// 配置了日志文件时追加文件 handler；连接建立失败时释放文件，成功后登记到 client，断开连接时释放。
+	var file *logFile
+	handlers := c.handlers()
+	if c.Logger && c.LogFile != nil {
+		if file, err = acquireLogFile(c.LogFile); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if file != nil {
+				file.release()
+			}
+		}()
+		handlers = append(handlers, file.handle)
+	}
 
 	if c.Logger {
 		logger := internal.NewLogger(&internal.Conf{ // 构造内部 logger 配置并返回 logger 实例。
-			SlowThreshold: 200 * time.Millisecond, // 慢查询阈值，超过则按 warn 输出。
-			Colorful:      true,                   // 是否开启彩色控制台输出。
-			Database:      c.Database,             // 写入日志字段，用于区分数据库实例。
-			Console:       c.loggerConsole,        // 是否输出到控制台。
+			SlowThreshold: c.slowThreshold(), // 慢查询阈值，超过则按 warn 输出，为 0 时不检测。
+			SampleRate:    c.LogSampleRate,   // 成功命令日志的采样比例，为 0 时全部记录。
+			Colorful:      c.colorful(),      // 是否开启彩色控制台输出，默认按终端与 NO_COLOR 自动检测。
+			Database:      c.Database,        // 写入日志字段，用于区分数据库实例。
+			Console:       c.loggerConsole,   // 是否输出到控制台。
+			Slog:          c.slog,            // 配置了 slog 时写入 slog，替代控制台输出。
+			Extractors:    c.logExtractors(), // 日志关联字段提取器。
+			Handlers:      handlers,          // 额外接收结构化日志的 handler（含日志文件）。
 		})
 
-		// stmts 用于缓存 RequestID 对应的命令文本，供结束事件读取。
-		var stmts sync.Map
-
-		// 保存 otelmongo 的原始回调
-		otelStarted := otelMonitor.Started
-		otelSucceeded := otelMonitor.Succeeded
-		otelFailed := otelMonitor.Failed
-
-		// 重新绑定命令监控回调（串联 otelmongo + internal logger）。
-		clientOptions.Monitor = &event.CommandMonitor{
-			// Started 在命令开始时触发。
-			Started: func(ctx context.Context, e *event.CommandStartedEvent) {
-				// 先执行 otelmongo 的逻辑 (Tracing)
-				if otelStarted != nil {
-					otelStarted(ctx, e)
-				}
-				// 再执行 internal logger 的逻辑 (Logging)
-				stmts.Store(e.RequestID, e.Command.String())
-			},
-			// Succeeded 在命令成功时触发。
-			Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
-				// 先执行 otelmongo 的逻辑
-				if otelSucceeded != nil {
-					otelSucceeded(ctx, e)
-				}
-				// 再执行 internal logger 的逻辑
-				// smt 用于保存命令字符串（若能从 map 中取到）。
-				var smt string
-				// 通过 RequestID 找到对应的命令文本。
-				if v, ok := stmts.Load(e.RequestID); ok {
-					// 做类型断言并赋值（失败则保持空字符串）。
-					smt, _ = v.(string)
-					// 取出后删除，避免 map 增长。
-					stmts.Delete(e.RequestID)
-				}
-				// 记录成功 Trace，err 字符串为空。
-				logger.Trace(ctx, e.RequestID, e.Duration, smt, "")
-			},
-			// Failed 在命令失败时触发。
-			Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
-				// 先执行 otelmongo 的逻辑
-				if otelFailed != nil {
-					otelFailed(ctx, e)
-				}
-				// 再执行 internal logger 的逻辑
-				// smt 用于保存命令字符串（若能从 map 中取到）。
-				var smt string
-				// 通过 RequestID 找到对应的命令文本。
-				if v, ok := stmts.Load(e.RequestID); ok {
-					smt, _ = v.(string)
-					stmts.Delete(e.RequestID)
-				}
-				// 记录失败 Trace，err 为 driver 提供的失败信息。
-				if e.Failure != nil {
-					logger.Trace(ctx, e.RequestID, e.Duration, smt, e.Failure.Error())
-				} else {
-					logger.Trace(ctx, e.RequestID, e.Duration, smt, "")
-				}
-			},
-		}
+		monitors = append(monitors, newLoggerMonitor(logger, c.redactor(), c.MaxStatementLength, c.commandFilter()))
+	}
+
+	monitors = append(monitors, c.monitors...)
+	clientOptions.Monitor = chainCommandMonitors(monitors...)
+
+	if pool := chainPoolMonitors(c.poolMonitors...); pool != nil {
+		clientOptions.SetPoolMonitor(pool)
+	}
+
+	// 启用日志时同时记录节点类型变化与主节点切换，便于从日志定位故障转移。
+	serverMonitors := c.serverMonitors
+	if c.Logger {
+		serverMonitors = append([]*event.ServerMonitor{newTopologyLogMonitor(c.Database, c.output())}, serverMonitors...)
+	}
+	if server := chainServerMonitors(serverMonitors...); server != nil {
+		clientOptions.SetServerMonitor(server)
 	}
 
 	// 用构造好的 options 建立客户端连接。
@@ -164,13 +277,39 @@ func New(c *Conf) (*mongo.Database, error) {
 		return nil, err
 	}
 
-	// Ping 用于验证连接可用与认证正确。
-	if err := client.Ping(ctx, readpref.Primary()); err != nil {
-		return nil, err
+	// Ping 用于验证连接可用与认证正确；LazyConnect 时跳过，由 driver 在首次操作时选择节点并重试。
+	if !c.LazyConnect {
+		if err := client.Ping(ctx, pingPref); err != nil {
+			_ = client.Disconnect(context.WithoutCancel(ctx))
+			return nil, err
+		}
+		if c.WarmUp {
+			warmUp(ctx, client, pingPref, c.MinOpenConnects)
+		}
+	}
+
+	if file != nil {
+		logFileByClient.Store(trackClient(client), file)
+		file = nil
 	}
 
-	// 选择默认数据库并返回对应句柄。
-	db := client.Database(c.Database)
+	if c.WriteConcern != nil && c.WriteConcern.WTimeout > 0 {
+		writeTimeouts.Store(trackClient(client), time.Millisecond*time.Duration(c.WriteConcern.WTimeout))
+	}
+
+	// 启用日志时输出一条启动摘要，便于从第一条日志发现环境配置问题。
+	// LazyConnect 时服务端可能暂不可用，改为后台等待节点可用后输出，不阻塞启动。
+	if c.Logger {
+		if c.LazyConnect {
+			go func() {
+				logCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lazyStartupLogTimeout)
+				defer cancel()
+				emitStartupLog(logCtx, c, client, clientOptions)
+			}()
+		} else {
+			emitStartupLog(ctx, c, client, clientOptions)
+		}
+	}
 
-	return db, nil
+	return client, nil
 }
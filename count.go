@@ -0,0 +1,123 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// approxZ 为 95% 置信水平对应的 z 值。
+const approxZ = 1.96
+
+// ApproxCountResult 为 ApproxCount 的估算结果。
+type ApproxCountResult struct {
+	// Estimate 为匹配文档数的估算值。
+	Estimate int64 `json:"estimate"`
+	// ErrorBound 为 95% 置信水平下的误差范围，取 Wilson 区间两侧到 Estimate 距离的较大值。
+	ErrorBound int64 `json:"error_bound"`
+	// Lower/Upper 为 95% 置信水平下的 Wilson 区间；匹配比例接近 0 或 1 时区间不对称，且不会退化为零宽度。
+	Lower int64 `json:"lower"`
+	Upper int64 `json:"upper"`
+	// Total 为集合文档总数（来自集合元数据）。
+	Total int64 `json:"total"`
+	// SampleSize 为实际抽样文档数。
+	SampleSize int64 `json:"sample_size"`
+	// Exact 为 true 时集合规模小于抽样量，已退化为精确计数。
+	Exact bool `json:"exact"`
+}
+
+// ApproxCount 通过 $sample 抽样匹配比例并按集合总量放大，估算大集合中满足 filter 的文档数。
+// accuracy 为期望的相对误差（占总量的比例，例如 0.01），取值范围 (0, 1)，越小抽样越多。
+func ApproxCount(ctx context.Context, collection *mongo.Collection, filter any, accuracy float64) (_ *ApproxCountResult, err error) {
+	if accuracy <= 0 || accuracy >= 1 {
+		return nil, errors.New("mongo: accuracy must be in (0, 1)")
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if err := CheckFilter(filter); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "approx_count", Filter: filter}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+	filter = op.Filter
+
+	ctx, collection, cancel := withProfile(ctx, collection)
+	defer cancel()
+
+	total, err := collection.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按最坏情况 p=0.5 计算所需样本量：n = z² · 0.25 / accuracy²。
+	size := int64(math.Ceil(approxZ * approxZ * 0.25 / (accuracy * accuracy)))
+
+	if total <= size {
+		count, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return &ApproxCountResult{Estimate: count, Lower: count, Upper: count, Total: total, SampleSize: total, Exact: true}, nil
+	}
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: size}}}},
+		{{Key: "$match", Value: filter}},
+		{{Key: "$count", Value: "matched"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Matched int64 `bson:"matched"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	var matched int64
+	if len(rows) != 0 {
+		matched = rows[0].Matched
+	}
+
+	estimate := int64(math.Round(float64(matched) / float64(size) * float64(total)))
+	lo, hi := wilsonInterval(matched, size, total)
+	lower := int64(math.Floor(lo * float64(total)))
+	upper := int64(math.Ceil(hi * float64(total)))
+
+	return &ApproxCountResult{
+		Estimate:   estimate,
+		ErrorBound: max(estimate-lower, upper-estimate),
+		Lower:      lower,
+		Upper:      upper,
+		Total:      total,
+		SampleSize: size,
+	}, nil
+}
+
+// wilsonInterval 返回样本中 matched/size 的匹配比例在 95% 置信水平下的 Wilson 区间，并按总体 total 做有限总体修正。
+// 与正态近似（Wald）区间不同，匹配比例为 0 或 1 时区间宽度仍不为零。
+func wilsonInterval(matched, size, total int64) (lo, hi float64) {
+	p := float64(matched) / float64(size)
+	if total <= size {
+		return p, p
+	}
+
+	// 有限总体修正：以有效样本量 n·(N-1)/(N-n) 代入，样本占总量比例越大区间越窄。
+	n := float64(size) * float64(total-1) / float64(total-size)
+	z2 := approxZ * approxZ
+
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	half := approxZ / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return max(center-half, 0), min(center+half, 1)
+}
@@ -0,0 +1,35 @@
+package mongo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		matched, size int64
+		total         int64
+		lo, hi        float64
+	}{
+		// 参考值按 Wilson 公式（z=1.96）计算，总体远大于样本时有限总体修正可忽略。
+		{name: "no matches", matched: 0, size: 100, total: 1 << 40, lo: 0, hi: 0.0370},
+		{name: "all match", matched: 100, size: 100, total: 1 << 40, lo: 0.9630, hi: 1},
+		{name: "half", matched: 50, size: 100, total: 1 << 40, lo: 0.4038, hi: 0.5962},
+		{name: "rare", matched: 1, size: 1000, total: 1 << 40, lo: 0.0002, hi: 0.0057},
+		{name: "sample is the whole population", matched: 30, size: 100, total: 100, lo: 0.3, hi: 0.3},
+		{name: "most of the population", matched: 0, size: 900, total: 1000, lo: 0, hi: 0.0004},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi := wilsonInterval(tt.matched, tt.size, tt.total)
+			if math.Abs(lo-tt.lo) > 1e-4 || math.Abs(hi-tt.hi) > 1e-4 {
+				t.Errorf("interval = [%.4f, %.4f], want [%.4f, %.4f]", lo, hi, tt.lo, tt.hi)
+			}
+			if tt.total > tt.size && hi <= lo {
+				t.Errorf("interval [%.4f, %.4f] has no width", lo, hi)
+			}
+		})
+	}
+}
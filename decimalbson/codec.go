@@ -0,0 +1,325 @@
+package decimalbson
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInexact 表示数值无法无损表示为 Decimal128（如 1/3 或超过 34 位有效数字）。
+var ErrInexact = errors.New("decimalbson: value cannot be represented exactly as decimal128")
+
+var (
+	tRat    = reflect.TypeOf(big.Rat{})
+	tRatPtr = reflect.TypeOf(&big.Rat{})
+	tInt    = reflect.TypeOf(big.Int{})
+	tIntPtr = reflect.TypeOf(&big.Int{})
+
+	tTextMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	tTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	ten = big.NewInt(10)
+)
+
+// NewRegistry 返回注册了 Decimal128 编解码器的默认 Registry。
+func NewRegistry() *bson.Registry {
+	reg := bson.NewRegistry()
+	Register(reg)
+	return reg
+}
+
+// Register 向已有 Registry 注册 big.Rat、big.Int（值与指针）与 Decimal128 之间的编解码器。
+// 无法无损转换的值编码时返回 ErrInexact；解码时兼容 double、int32、int64 与数字字符串，便于迁移旧数据。
+func Register(reg *bson.Registry) {
+	for _, t := range []reflect.Type{tRat, tRatPtr} {
+		reg.RegisterTypeEncoder(t, bson.ValueEncoderFunc(encodeRat))
+		reg.RegisterTypeDecoder(t, bson.ValueDecoderFunc(decodeRat))
+	}
+	for _, t := range []reflect.Type{tInt, tIntPtr} {
+		reg.RegisterTypeEncoder(t, bson.ValueEncoderFunc(encodeInt))
+		reg.RegisterTypeDecoder(t, bson.ValueDecoderFunc(decodeInt))
+	}
+}
+
+// RegisterText 为实现了 encoding.TextMarshaler/TextUnmarshaler 的十进制类型（如 decimal.Decimal）注册 Decimal128 编解码器，
+// 文本形式需为十进制数字（可带符号与小数点）。
+func RegisterText(reg *bson.Registry, types ...reflect.Type) error {
+	for _, t := range types {
+		ptr := t
+		if t.Kind() != reflect.Pointer {
+			ptr = reflect.PointerTo(t)
+		}
+		if !t.Implements(tTextMarshaler) && !ptr.Implements(tTextMarshaler) {
+			return fmt.Errorf("decimalbson: %v does not implement encoding.TextMarshaler", t)
+		}
+		if !ptr.Implements(tTextUnmarshaler) {
+			return fmt.Errorf("decimalbson: %v does not implement encoding.TextUnmarshaler", t)
+		}
+		reg.RegisterTypeEncoder(t, bson.ValueEncoderFunc(encodeText))
+		reg.RegisterTypeDecoder(t, bson.ValueDecoderFunc(decodeText))
+	}
+	return nil
+}
+
+func encodeRat(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	r, ok := ratOf(val)
+	if !ok {
+		return vw.WriteNull()
+	}
+	d, err := FromRat(r)
+	if err != nil {
+		return err
+	}
+	return vw.WriteDecimal128(d)
+}
+
+func decodeRat(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	if vr.Type() == bson.TypeNull {
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	}
+	r, err := readRat(vr)
+	if err != nil {
+		return err
+	}
+	if val.Kind() == reflect.Pointer {
+		val.Set(reflect.ValueOf(r))
+	} else {
+		val.Set(reflect.ValueOf(r).Elem())
+	}
+	return nil
+}
+
+func encodeInt(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	var i *big.Int
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return vw.WriteNull()
+		}
+		i = val.Interface().(*big.Int)
+	} else {
+		v := val.Interface().(big.Int)
+		i = &v
+	}
+	d, ok := bson.ParseDecimal128FromBigInt(i, 0)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInexact, i.String())
+	}
+	return vw.WriteDecimal128(d)
+}
+
+func decodeInt(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	if vr.Type() == bson.TypeNull {
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	}
+	r, err := readRat(vr)
+	if err != nil {
+		return err
+	}
+	if !r.IsInt() {
+		return fmt.Errorf("decimalbson: %s is not an integer", r.RatString())
+	}
+	i := new(big.Int).Set(r.Num())
+	if val.Kind() == reflect.Pointer {
+		val.Set(reflect.ValueOf(i))
+	} else {
+		val.Set(reflect.ValueOf(i).Elem())
+	}
+	return nil
+}
+
+func encodeText(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	if val.Kind() == reflect.Pointer && val.IsNil() {
+		return vw.WriteNull()
+	}
+	m, ok := val.Interface().(encoding.TextMarshaler)
+	if !ok && val.CanAddr() {
+		m, ok = val.Addr().Interface().(encoding.TextMarshaler)
+	}
+	if !ok {
+		return fmt.Errorf("decimalbson: %v does not implement encoding.TextMarshaler", val.Type())
+	}
+	text, err := m.MarshalText()
+	if err != nil {
+		return err
+	}
+	// 优先按字面解析，保留 12.50 这类末尾的 0。
+	if d, err := bson.ParseDecimal128(string(text)); err == nil {
+		return vw.WriteDecimal128(d)
+	}
+	r, ok := new(big.Rat).SetString(string(text))
+	if !ok {
+		return fmt.Errorf("decimalbson: %q is not a decimal number", text)
+	}
+	d, err := FromRat(r)
+	if err != nil {
+		return err
+	}
+	return vw.WriteDecimal128(d)
+}
+
+func decodeText(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	if vr.Type() == bson.TypeNull {
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	}
+	r, scale, err := readRatScale(vr)
+	if err != nil {
+		return err
+	}
+
+	t := val.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if min, ok := decimalScale(r.Denom()); ok {
+		scale = max(scale, min)
+	}
+	ptr := reflect.New(t)
+	if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(r.FloatString(scale))); err != nil {
+		return err
+	}
+	if val.Kind() == reflect.Pointer {
+		val.Set(ptr)
+	} else {
+		val.Set(ptr.Elem())
+	}
+	return nil
+}
+
+// FromRat 将有理数无损转换为 Decimal128：分母只含因子 2 与 5 且有效数字不超过 34 位，否则返回 ErrInexact。
+func FromRat(r *big.Rat) (bson.Decimal128, error) {
+	scale, ok := decimalScale(r.Denom())
+	if !ok {
+		return bson.Decimal128{}, fmt.Errorf("%w: %s", ErrInexact, r.RatString())
+	}
+
+	// value = num * 10^scale / denom 为整数，Decimal128 = value * 10^-scale。
+	value := new(big.Int).Mul(r.Num(), new(big.Int).Exp(ten, big.NewInt(int64(scale)), nil))
+	value.Quo(value, r.Denom())
+	d, ok := bson.ParseDecimal128FromBigInt(value, -scale)
+	if !ok {
+		return bson.Decimal128{}, fmt.Errorf("%w: %s", ErrInexact, r.RatString())
+	}
+	return d, nil
+}
+
+// decimalScale 返回分母为 denom 的有理数写成有限小数所需的最少小数位数，分母含 2、5 以外的因子时返回 false。
+func decimalScale(denom *big.Int) (int, bool) {
+	d := new(big.Int).Set(denom)
+	twos, fives := 0, 0
+	for d.Bit(0) == 0 {
+		d.Rsh(d, 1)
+		twos++
+	}
+	five := big.NewInt(5)
+	for {
+		q, m := new(big.Int).QuoRem(d, five, new(big.Int))
+		if m.Sign() != 0 {
+			break
+		}
+		d = q
+		fives++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	return max(twos, fives), true
+}
+
+// ToRat 将 Decimal128 转换为有理数，NaN 与无穷大返回错误。
+func ToRat(d bson.Decimal128) (*big.Rat, error) {
+	r, _, err := toRatScale(d)
+	return r, err
+}
+
+func toRatScale(d bson.Decimal128) (*big.Rat, int, error) {
+	value, exp, err := d.BigInt()
+	if err != nil {
+		return nil, 0, fmt.Errorf("decimalbson: %s: %w", d.String(), err)
+	}
+	r := new(big.Rat).SetInt(value)
+	pow := new(big.Int).Exp(ten, big.NewInt(int64(abs(exp))), nil)
+	if exp >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(pow))
+		return r, 0, nil
+	}
+	r.Quo(r, new(big.Rat).SetInt(pow))
+	return r, -exp, nil
+}
+
+func readRat(vr bson.ValueReader) (*big.Rat, error) {
+	r, _, err := readRatScale(vr)
+	return r, err
+}
+
+// readRatScale 读取数值并返回原始小数位数，保证 1.50 解码为文本类型时仍为 1.50。
+func readRatScale(vr bson.ValueReader) (*big.Rat, int, error) {
+	switch vr.Type() {
+	case bson.TypeDecimal128:
+		d, err := vr.ReadDecimal128()
+		if err != nil {
+			return nil, 0, err
+		}
+		return toRatScale(d)
+	case bson.TypeInt32:
+		v, err := vr.ReadInt32()
+		return big.NewRat(int64(v), 1), 0, err
+	case bson.TypeInt64:
+		v, err := vr.ReadInt64()
+		return big.NewRat(v, 1), 0, err
+	case bson.TypeDouble:
+		// 按最短十进制表示解析，0.1 解码为 1/10 而不是其二进制近似值。
+		v, err := vr.ReadDouble()
+		if err != nil {
+			return nil, 0, err
+		}
+		return parseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case bson.TypeString:
+		s, err := vr.ReadString()
+		if err != nil {
+			return nil, 0, err
+		}
+		return parseDecimal(s)
+	default:
+		return nil, 0, fmt.Errorf("decimalbson: cannot decode %v into a decimal", vr.Type())
+	}
+}
+
+// parseDecimal 解析十进制字符串，返回数值与字面小数位数。
+func parseDecimal(s string) (*big.Rat, int, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") {
+		return nil, 0, fmt.Errorf("decimalbson: %q is not a decimal number", s)
+	}
+	scale := 0
+	if _, frac, ok := strings.Cut(s, "."); ok && !strings.ContainsAny(frac, "eE") {
+		scale = len(frac)
+	}
+	return r, scale, nil
+}
+
+func ratOf(val reflect.Value) (*big.Rat, bool) {
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil, false
+		}
+		return val.Interface().(*big.Rat), true
+	}
+	r := val.Interface().(big.Rat)
+	return &r, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -9,47 +9,103 @@ import (
 )
 
 // DeleteById 按id删除单条文档，并返回 driver 的 DeleteResult。
-func Delete(ctx context.Context, collection *mongo.Collection, id string) (*mongo.DeleteResult, error) {
-	return collection.DeleteOne(ctx, bson.D{
+func Delete(ctx context.Context, collection *mongo.Collection, id string) (result *mongo.DeleteResult, err error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "delete", Ids: []string{id}, Filter: bson.D{
 		{Key: "_id", Value: id},
-	})
+	}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.DeleteOne(ctx, op.Filter)
 }
 
 // DeleteManyByIds 按id列表批量删除文档，并返回 driver 的 DeleteResult。
-func DeleteManyByIds(ctx context.Context, collection *mongo.Collection, ids []string) (*mongo.DeleteResult, error) {
-	return collection.DeleteMany(ctx, bson.D{
+func DeleteManyByIds(ctx context.Context, collection *mongo.Collection, ids []string) (result *mongo.DeleteResult, err error) {
+	if err := ValidateIDs(ids); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "delete_many", Ids: ids, Filter: bson.D{
 		{Key: "_id", Value: bson.D{
 			{Key: "$in", Value: ids},
 		}},
-	})
+	}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.DeleteMany(ctx, op.Filter)
 }
 
 // SoftDeleteById 软删除单条文档：写入 updated_at 与 deleted_at，并返回 UpdateResult。
-func SoftDeleteById(ctx context.Context, collection *mongo.Collection, id string) (*mongo.UpdateResult, error) {
+func SoftDeleteById(ctx context.Context, collection *mongo.Collection, id string) (result *mongo.UpdateResult, err error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
 	timer := time.Now().UTC()
 
-	return collection.UpdateOne(ctx, bson.D{
+	op := &Operation{Name: "soft_delete", Ids: []string{id}, Filter: bson.D{
 		{Key: "_id", Value: id},
-	}, bson.D{
+	}, Update: bson.D{
 		{Key: "$set", Value: bson.M{
 			"updated_at": timer,
 			"deleted_at": timer,
 		}},
-	})
+	}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.UpdateOne(ctx, op.Filter, op.Update)
 }
 
 // SoftDeleteManyByIds 软删除多条文档：批量写入 updated_at 与 deleted_at，并返回 UpdateResult。
-func SoftDeleteManyByIds(ctx context.Context, collection *mongo.Collection, ids []string) (*mongo.UpdateResult, error) {
+func SoftDeleteManyByIds(ctx context.Context, collection *mongo.Collection, ids []string) (result *mongo.UpdateResult, err error) {
+	if err := ValidateIDs(ids); err != nil {
+		return nil, err
+	}
+
 	timer := time.Now().UTC()
 
-	return collection.UpdateMany(ctx, bson.D{
+	op := &Operation{Name: "soft_delete_many", Ids: ids, Filter: bson.D{
 		{Key: "_id", Value: bson.D{
 			{Key: "$in", Value: ids},
 		}},
-	}, bson.D{
+	}, Update: bson.D{
 		{Key: "$set", Value: bson.M{
 			"updated_at": timer,
 			"deleted_at": timer,
 		}},
-	})
+	}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.UpdateMany(ctx, op.Filter, op.Update)
 }
@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrDestructiveNotConfirmed 表示破坏性操作未通过 WithDestructiveConfirm 确认。
+var ErrDestructiveNotConfirmed = errors.New("mongo: destructive operation requires confirmation")
+
+type destructiveConfirmKey struct{}
+
+// WithDestructiveConfirm 返回携带破坏性操作确认标记的 ctx，DeleteWhere/DropCollection/PurgeDeleted 只接受该 ctx。
+func WithDestructiveConfirm(ctx context.Context) context.Context {
+	return context.WithValue(ctx, destructiveConfirmKey{}, true)
+}
+
+// DestructiveConfirmed 判断 ctx 是否已确认破坏性操作。
+func DestructiveConfirmed(ctx context.Context) bool {
+	v, _ := ctx.Value(destructiveConfirmKey{}).(bool)
+	return v
+}
+
+// interceptDestructive 校验确认标记并执行拦截器，全部通过后以改写后的过滤条件上报审计日志，
+// 被拒绝的操作不会记录为已执行。
+func interceptDestructive(ctx context.Context, collection *mongo.Collection, op *Operation) (context.Context, func(error), error) {
+	if !DestructiveConfirmed(ctx) {
+		return ctx, nil, ErrDestructiveNotConfirmed
+	}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return ctx, nil, err
+	}
+	auditDestructive(ctx, op.Name, collection, op.Filter)
+	return ctx, finish, nil
+}
+
+// auditDestructive 在执行前上报破坏性操作的审计日志。
+func auditDestructive(ctx context.Context, operation string, collection *mongo.Collection, filter any) {
+	record := &internal.DestructiveLogger{
+		Operation:  operation,
+		Database:   collection.Database().Name(),
+		Collection: collection.Name(),
+	}
+	if filter != nil {
+		if b, err := bson.MarshalExtJSON(filter, false, false); err == nil {
+			record.Filter = string(b)
+		}
+	}
+	internal.EmitDestructiveLog(ctx, record)
+}
+
+// DeleteWhere 按条件物理删除多条文档，需要 WithDestructiveConfirm 确认。
+func DeleteWhere(ctx context.Context, collection *mongo.Collection, filter any) (result *mongo.DeleteResult, err error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if err := CheckFilter(filter); err != nil {
+		return nil, err
+	}
+	op := &Operation{Name: "delete_where", Filter: filter}
+	ctx, finish, err := interceptDestructive(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.DeleteMany(ctx, op.Filter)
+}
+
+// DropCollection 删除整个集合（含索引），需要 WithDestructiveConfirm 确认。
+func DropCollection(ctx context.Context, collection *mongo.Collection) (err error) {
+	op := &Operation{Name: "drop_collection"}
+	ctx, finish, err := interceptDestructive(ctx, collection, op)
+	if err != nil {
+		return err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.Drop(ctx)
+}
+
+// PurgeDeleted 物理删除 deleted_at 早于 before 的软删除文档，需要 WithDestructiveConfirm 确认。
+func PurgeDeleted(ctx context.Context, collection *mongo.Collection, before time.Time) (result *mongo.DeleteResult, err error) {
+	filter := bson.D{
+		{Key: "deleted_at", Value: bson.D{
+			{Key: "$ne", Value: nil},
+			{Key: "$lte", Value: before.UTC()},
+		}},
+	}
+	op := &Operation{Name: "purge_deleted", Filter: filter}
+	ctx, finish, err := interceptDestructive(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.DeleteMany(ctx, op.Filter)
+}
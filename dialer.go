@@ -0,0 +1,112 @@
+package mongo
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// contextDialer 返回建立连接使用的拨号器，未配置 WithDialer、KeepAlive、Proxy 与 SocketTimeout 时返回 nil（使用 driver 默认拨号器）。
+func (c *Conf) contextDialer() (options.ContextDialer, error) {
+	dialer := c.dialer
+	if dialer == nil && c.KeepAlive > 0 {
+		dialer = &net.Dialer{KeepAlive: time.Second * time.Duration(c.KeepAlive)}
+	}
+	if c.Proxy != "" {
+		if dialer == nil {
+			dialer = &net.Dialer{}
+		}
+		proxy, err := newProxyDialer(c.Proxy, dialer)
+		if err != nil {
+			return nil, err
+		}
+		dialer = proxy
+	}
+	if c.SocketTimeout > 0 {
+		if dialer == nil {
+			dialer = &net.Dialer{}
+		}
+		dialer = &socketTimeoutDialer{dialer: dialer, timeout: time.Millisecond * time.Duration(c.SocketTimeout)}
+	}
+	return dialer, nil
+}
+
+// socketTimeoutDialer 为建立的每个连接设置单次读写超时。
+type socketTimeoutDialer struct {
+	dialer  options.ContextDialer
+	timeout time.Duration
+}
+
+func (d *socketTimeoutDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &deadlineConn{Conn: conn, timeout: d.timeout}, nil
+}
+
+// deadlineConn 在每次 Read/Write 前将截止时间设置为 now+timeout，
+// driver 按 ctx 设置了更早的截止时间时以较早者为准。
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.bound(c.readDeadline)
+	c.mu.Unlock()
+
+	if err := c.Conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
+
+func (c *deadlineConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.bound(c.writeDeadline)
+	c.mu.Unlock()
+
+	if err := c.Conn.SetWriteDeadline(deadline); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *deadlineConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline, c.writeDeadline = t, t
+	c.mu.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *deadlineConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *deadlineConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// bound 返回 now+timeout 与调用方截止时间中较早的一个。
+func (c *deadlineConn) bound(deadline time.Time) time.Time {
+	next := time.Now().Add(c.timeout)
+	if !deadline.IsZero() && deadline.Before(next) {
+		return deadline
+	}
+	return next
+}
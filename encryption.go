@@ -0,0 +1,183 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// localMasterKeySize 为 local KMS 主密钥长度。
+const localMasterKeySize = 96
+
+// EncryptionConf 为客户端字段级加密（CSFLE）与 Queryable Encryption 配置，需以 -tags cse 编译并安装 libmongocrypt。
+type EncryptionConf struct {
+	// KeyVaultNamespace 为数据密钥集合，格式为 db.collection，例如 encryption.__keyVault。
+	KeyVaultNamespace string `json:"key_vault_namespace"`
+
+	// Local 为本地主密钥，适用于开发测试环境。
+	Local *LocalKMSConf `json:"local"`
+	// AWS 为 AWS KMS 凭证。
+	AWS *AWSKMSConf `json:"aws"`
+	// GCP 为 GCP KMS 凭证。
+	GCP *GCPKMSConf `json:"gcp"`
+
+	// SchemaMap 为 namespace（db.collection）到 $jsonSchema 的映射，声明需要自动加密的字段；
+	// 为空时使用服务端集合上的校验规则。
+	SchemaMap map[string]any `json:"schema_map"`
+	// EncryptedFieldsMap 为 namespace 到 encryptedFields 的映射，用于 Queryable Encryption（equality/range 查询），
+	// 与 SchemaMap 不能配置同一 namespace。
+	EncryptedFieldsMap map[string]any `json:"encrypted_fields_map"`
+	// BypassQueryAnalysis 为 true 时跳过查询分析，只自动解密；Queryable Encryption 下配合显式加密使用。
+	BypassQueryAnalysis bool `json:"bypass_query_analysis"`
+	// BypassAutoEncryption 为 true 时只自动解密、不自动加密（配合显式加密使用）。
+	BypassAutoEncryption bool `json:"bypass_auto_encryption"`
+
+	// CryptSharedLibPath 为 crypt_shared 动态库路径，为空时使用 mongocryptd。
+	CryptSharedLibPath string `json:"crypt_shared_lib_path"`
+	// CryptSharedLibRequired 为 true 时找不到 crypt_shared 直接报错，不回退 mongocryptd。
+	CryptSharedLibRequired bool `json:"crypt_shared_lib_required"`
+	// MongocryptdURI 为 mongocryptd 地址，为空时使用 driver 默认值。
+	MongocryptdURI string `json:"mongocryptd_uri"`
+}
+
+// LocalKMSConf 为本地主密钥，Key 与 KeyFile 二选一。
+type LocalKMSConf struct {
+	// Key 为 base64 编码的 96 字节主密钥。
+	Key string `json:"key"`
+	// KeyFile 为保存 96 字节原始主密钥的文件路径。
+	KeyFile string `json:"key_file"`
+}
+
+// AWSKMSConf 为 AWS KMS 凭证，AccessKeyId 为空时由 driver 从环境变量与实例元数据获取。
+type AWSKMSConf struct {
+	AccessKeyId     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token"`
+}
+
+// GCPKMSConf 为 GCP KMS 服务账号凭证，Email 为空时由 driver 从元数据服务获取。
+type GCPKMSConf struct {
+	Email string `json:"email"`
+	// PrivateKey 为 base64 编码的服务账号私钥。
+	PrivateKey string `json:"private_key"`
+	// Endpoint 为 OAuth 端点，为空时使用 oauth2.googleapis.com。
+	Endpoint string `json:"endpoint"`
+}
+
+// autoEncryptionOptions 构造 driver 的 AutoEncryptionOptions，未配置时返回 nil。
+func (e *EncryptionConf) autoEncryptionOptions() (*options.AutoEncryptionOptions, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	db, coll, ok := strings.Cut(e.KeyVaultNamespace, ".")
+	if !ok || db == "" || coll == "" {
+		return nil, fmt.Errorf("mongo: key vault namespace %q must be db.collection", e.KeyVaultNamespace)
+	}
+
+	providers, err := e.kmsProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.AutoEncryption().
+		SetKeyVaultNamespace(e.KeyVaultNamespace).
+		SetKmsProviders(providers).
+		SetBypassAutoEncryption(e.BypassAutoEncryption)
+	if len(e.SchemaMap) != 0 {
+		opts.SetSchemaMap(e.SchemaMap)
+	}
+	if len(e.EncryptedFieldsMap) != 0 {
+		for ns := range e.EncryptedFieldsMap {
+			if _, ok := e.SchemaMap[ns]; ok {
+				return nil, fmt.Errorf("mongo: namespace %q is in both schema_map and encrypted_fields_map", ns)
+			}
+		}
+		opts.SetEncryptedFieldsMap(e.EncryptedFieldsMap)
+	}
+	if e.BypassQueryAnalysis {
+		opts.SetBypassQueryAnalysis(true)
+	}
+
+	extra := map[string]any{}
+	if e.CryptSharedLibPath != "" {
+		extra["cryptSharedLibPath"] = e.CryptSharedLibPath
+	}
+	if e.CryptSharedLibRequired {
+		extra["cryptSharedLibRequired"] = true
+	}
+	if e.MongocryptdURI != "" {
+		extra["mongocryptdURI"] = e.MongocryptdURI
+	}
+	if len(extra) != 0 {
+		opts.SetExtraOptions(extra)
+	}
+
+	return opts, nil
+}
+
+// kmsProviders 转换为 driver 的 KMS 提供者配置，至少需要配置一个。
+func (e *EncryptionConf) kmsProviders() (map[string]map[string]any, error) {
+	providers := map[string]map[string]any{}
+
+	if e.Local != nil {
+		key, err := e.Local.masterKey()
+		if err != nil {
+			return nil, err
+		}
+		providers["local"] = map[string]any{"key": key}
+	}
+	if e.AWS != nil {
+		aws := map[string]any{}
+		if e.AWS.AccessKeyId != "" {
+			aws["accessKeyId"] = e.AWS.AccessKeyId
+			aws["secretAccessKey"] = e.AWS.SecretAccessKey
+			if e.AWS.SessionToken != "" {
+				aws["sessionToken"] = e.AWS.SessionToken
+			}
+		}
+		providers["aws"] = aws
+	}
+	if e.GCP != nil {
+		gcp := map[string]any{}
+		if e.GCP.Email != "" {
+			gcp["email"] = e.GCP.Email
+			gcp["privateKey"] = e.GCP.PrivateKey
+			if e.GCP.Endpoint != "" {
+				gcp["endpoint"] = e.GCP.Endpoint
+			}
+		}
+		providers["gcp"] = gcp
+	}
+
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("mongo: encryption requires at least one kms provider")
+	}
+	return providers, nil
+}
+
+// masterKey 读取并校验本地主密钥。
+func (l *LocalKMSConf) masterKey() ([]byte, error) {
+	var (
+		key []byte
+		err error
+	)
+	switch {
+	case l.Key != "":
+		key, err = base64.StdEncoding.DecodeString(l.Key)
+	case l.KeyFile != "":
+		key, err = os.ReadFile(l.KeyFile)
+	default:
+		return nil, fmt.Errorf("mongo: local kms requires key or key_file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mongo: local kms key: %w", err)
+	}
+	if len(key) != localMasterKeySize {
+		return nil, fmt.Errorf("mongo: local kms key must be %d bytes, got %d", localMasterKeySize, len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,165 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// beforeInserter 由 Table 等模型实现，用于在插入前初始化主键与时间字段。
+type beforeInserter interface {
+	BeforeInsert()
+}
+
+// EnsureDocuments 按 field 查找 keys 对应的文档，缺失的通过 factory 构造后批量插入，返回与 keys 顺序一致的完整结果。
+// 并发场景下依赖 field 上的唯一索引：插入时的重复主键/唯一键错误会被忽略，并重新读取胜出的文档。
+// factory 返回的文档（或其指针）若实现 BeforeInsert 会在插入前被调用；field 支持点路径（如 "profile.email"）。
+// 查询以 ensure 操作、插入以 insert 操作经过拦截器，拦截器改写后的过滤条件同样用于重新读取胜出的文档；
+// 插入的文档按 CheckInsert 校验，已存在的文档剔除 ctx 角色无权读取的字段。
+func EnsureDocuments[K comparable, T any](ctx context.Context, collection *mongo.Collection, field string, keys []K, factory func(K) T) (_ []T, err error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	op := &Operation{Name: "ensure", Filter: bson.D{
+		{Key: field, Value: bson.D{{Key: "$in", Value: keys}}},
+	}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	found, err := findByKeys[K, T](ctx, collection, field, op.Filter, len(keys))
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []K
+	var docs []any
+	for _, k := range keys {
+		if _, ok := found[k]; ok {
+			continue
+		}
+		doc := factory(k)
+		// T 为值类型（如内嵌 Table 的结构体）时 BeforeInsert 定义在指针上，通过 &doc 调用。
+		if bi, ok := any(doc).(beforeInserter); ok {
+			bi.BeforeInsert()
+		} else if bi, ok := any(&doc).(beforeInserter); ok {
+			bi.BeforeInsert()
+		}
+		found[k] = doc
+		missing = append(missing, k)
+		docs = append(docs, doc)
+	}
+
+	if len(docs) != 0 {
+		docs, err := insertMissing(ctx, collection, docs)
+		// 拦截器可能改写了插入的文档（如补上租户字段），返回写入的版本。
+		for i, k := range missing {
+			if i < len(docs) {
+				if doc, ok := docs[i].(T); ok {
+					found[k] = doc
+				}
+			}
+		}
+		if err != nil {
+			raced, err := duplicateKeys(missing, err)
+			if err != nil {
+				return nil, err
+			}
+			// 被其他请求抢先插入的文档以数据库中的版本为准。
+			if len(raced) != 0 {
+				winners, err := findByKeys[K, T](ctx, collection, field, bson.D{{Key: "$and", Value: bson.A{
+					op.Filter,
+					bson.D{{Key: field, Value: bson.D{{Key: "$in", Value: raced}}}},
+				}}}, len(raced))
+				if err != nil {
+					return nil, err
+				}
+				for k, doc := range winners {
+					found[k] = doc
+				}
+			}
+		}
+	}
+
+	list := make([]T, 0, len(keys))
+	for _, k := range keys {
+		doc, ok := found[k]
+		if !ok {
+			return nil, fmt.Errorf("mongo: document for key %v not found after insert", k)
+		}
+		list = append(list, doc)
+	}
+
+	return list, nil
+}
+
+// insertMissing 经 insert 拦截器与访问策略校验后批量插入，返回实际写入的文档。
+func insertMissing(ctx context.Context, collection *mongo.Collection, docs []any) (_ []any, err error) {
+	op := &Operation{Name: "insert", Documents: docs}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	if len(op.Documents) != len(docs) {
+		return nil, errors.New("mongo: interceptor changed the number of documents")
+	}
+	if err := CheckInsert(ctx, PolicyNamespace(collection), op.Documents...); err != nil {
+		return nil, err
+	}
+	_, err = collection.InsertMany(ctx, op.Documents, options.InsertMany().SetOrdered(false))
+	return op.Documents, err
+}
+
+func findByKeys[K comparable, T any](ctx context.Context, collection *mongo.Collection, field string, filter any, size int) (map[K]T, error) {
+	cursor, err := collection.Find(ctx, filter, profileFind(collection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	found := make(map[K]T, size)
+	for cursor.Next(ctx) {
+		var k K
+		if err := cursor.Current.Lookup(strings.Split(field, ".")...).Unmarshal(&k); err != nil {
+			return nil, err
+		}
+		var doc T
+		if err := DecodeRestricted(ctx, PolicyNamespace(collection), cursor.Current, &doc); err != nil {
+			return nil, err
+		}
+		found[k] = doc
+	}
+
+	return found, cursor.Err()
+}
+
+// duplicateKeys 从 InsertMany 错误中取出因重复键失败的 key，存在其他错误时原样返回。
+func duplicateKeys[K any](keys []K, err error) ([]K, error) {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil {
+		return nil, err
+	}
+
+	raced := make([]K, 0, len(bwe.WriteErrors))
+	for _, we := range bwe.WriteErrors {
+		if !we.HasErrorCode(11000) || we.Index < 0 || we.Index >= len(keys) {
+			return nil, err
+		}
+		raced = append(raced, keys[we.Index])
+	}
+
+	return raced, nil
+}
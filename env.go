@@ -0,0 +1,188 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fireflycore/go-utils/tlsx"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrInvalidEnv 表示环境变量缺失或格式非法。
+var ErrInvalidEnv = errors.New("mongo: invalid environment")
+
+// envPrefix 为 NewFromEnv 读取的环境变量前缀。
+const envPrefix = "MONGO_"
+
+// NewFromEnv 从环境变量构造 Conf 并创建连接，变量说明见 ConfFromEnv。
+func NewFromEnv() (*mongo.Database, error) {
+	c, err := ConfFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(c)
+}
+
+// ConfFromEnv 从 MONGO_ 前缀的环境变量构造 Conf，全部错误合并后返回（均包装 ErrInvalidEnv）。
+//
+// 连接：MONGO_ADDRESS 或 MONGO_ADDRESSES（逗号分隔，二者至少一个）、MONGO_REPLICA_SET、MONGO_SRV、MONGO_LOAD_BALANCED、MONGO_DATABASE（必填）、MONGO_APP_NAME；
+// 认证：MONGO_USERNAME、MONGO_PASSWORD、MONGO_AUTH_SOURCE、MONGO_AUTH_MECHANISM；
+// TLS：MONGO_TLS_CA_CERT、MONGO_TLS_CLIENT_CERT、MONGO_TLS_CLIENT_CERT_KEY（客户端证书与私钥需同时设置）、
+// MONGO_TLS_SYSTEM_CA、MONGO_TLS_INSECURE_SKIP_VERIFY（仅开发环境）；
+// 连接池与超时：MONGO_MAX_OPEN_CONNECTS、MONGO_MIN_OPEN_CONNECTS、MONGO_WARM_UP、MONGO_CONN_MAX_LIFE_TIME（秒）、MONGO_TIMEOUT（毫秒）、MONGO_HEARTBEAT_INTERVAL（毫秒）、
+// MONGO_SERVER_SELECTION_TIMEOUT、MONGO_CONNECT_TIMEOUT、MONGO_SOCKET_TIMEOUT（毫秒）、MONGO_KEEP_ALIVE（秒）、MONGO_PROXY；
+// 其他：MONGO_READ_PREFERENCE、MONGO_COMPRESSORS（逗号分隔）、MONGO_RETRY_WRITES、MONGO_RETRY_READS、
+// MONGO_LAZY_CONNECT、MONGO_LOGGER、MONGO_LOGGER_CONSOLE、MONGO_LOGGER_COLORFUL、MONGO_SLOW_THRESHOLD（时长，如 200ms，负数如 -1ms 表示不检测）。
+func ConfFromEnv() (*Conf, error) {
+	e := &envReader{lookup: os.LookupEnv}
+	c := &Conf{
+		Address:        e.string("ADDRESS"),
+		Addresses:      e.list("ADDRESSES"),
+		ReplicaSet:     e.string("REPLICA_SET"),
+		Srv:            e.bool("SRV"),
+		LoadBalanced:   e.bool("LOAD_BALANCED"),
+		Database:       e.string("DATABASE"),
+		AppName:        e.string("APP_NAME"),
+		Username:       e.string("USERNAME"),
+		Password:       e.string("PASSWORD"),
+		AuthSource:     e.string("AUTH_SOURCE"),
+		AuthMechanism:  e.string("AUTH_MECHANISM"),
+		ReadPreference: e.string("READ_PREFERENCE"),
+		Compressors:    e.list("COMPRESSORS"),
+		RetryWrites:    e.optionalBool("RETRY_WRITES"),
+		RetryReads:     e.optionalBool("RETRY_READS"),
+		LazyConnect:    e.bool("LAZY_CONNECT"),
+		Logger:         e.bool("LOGGER"),
+		Colorful:       e.optionalBool("LOGGER_COLORFUL"),
+		SlowThreshold:  e.duration("SLOW_THRESHOLD"),
+
+		MaxOpenConnects:   e.int("MAX_OPEN_CONNECTS"),
+		MinOpenConnects:   e.int("MIN_OPEN_CONNECTS"),
+		WarmUp:            e.bool("WARM_UP"),
+		ConnMaxLifeTime:   e.int("CONN_MAX_LIFE_TIME"),
+		Timeout:           e.int("TIMEOUT"),
+		HeartbeatInterval: e.int("HEARTBEAT_INTERVAL"),
+
+		ServerSelectionTimeout: e.int("SERVER_SELECTION_TIMEOUT"),
+		ConnectTimeout:         e.int("CONNECT_TIMEOUT"),
+		SocketTimeout:          e.int("SOCKET_TIMEOUT"),
+		KeepAlive:              e.int("KEEP_ALIVE"),
+		Proxy:                  e.string("PROXY"),
+	}
+	c.WithLoggerConsole(e.bool("LOGGER_CONSOLE"))
+
+	if c.Address == "" && len(c.Addresses) == 0 {
+		e.fail("%sADDRESS or %sADDRESSES is required", envPrefix, envPrefix)
+	}
+	if c.Database == "" {
+		e.fail("%sDATABASE is required", envPrefix)
+	}
+
+	tls := &tlsx.TLS{
+		CaCert:        e.string("TLS_CA_CERT"),
+		ClientCert:    e.string("TLS_CLIENT_CERT"),
+		ClientCertKey: e.string("TLS_CLIENT_CERT_KEY"),
+	}
+	if countNonEmpty(tls.CaCert, tls.ClientCert, tls.ClientCertKey) != 0 {
+		c.Tls = tls
+	}
+	if countNonEmpty(tls.ClientCert, tls.ClientCertKey) == 1 {
+		e.fail("%sTLS_CLIENT_CERT and %sTLS_CLIENT_CERT_KEY must be set together", envPrefix, envPrefix)
+	}
+	c.TlsSystemCA = e.bool("TLS_SYSTEM_CA")
+	c.TlsInsecureSkipVerify = e.bool("TLS_INSECURE_SKIP_VERIFY")
+
+	if _, err := c.readPref(); err != nil {
+		e.fail("%sREAD_PREFERENCE: %v", envPrefix, err)
+	}
+
+	if err := errors.Join(e.errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// envReader 读取带前缀的环境变量并收集解析错误。
+type envReader struct {
+	lookup func(key string) (string, bool)
+	errs   []error
+}
+
+func (e *envReader) fail(format string, args ...any) {
+	e.errs = append(e.errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidEnv}, args...)...))
+}
+
+func (e *envReader) string(key string) string {
+	v, _ := e.lookup(envPrefix + key)
+	return strings.TrimSpace(v)
+}
+
+func (e *envReader) list(key string) []string {
+	var list []string
+	for _, item := range strings.Split(e.string(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (e *envReader) int(key string) int {
+	v := e.string(key)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		e.fail("%s%s must be a non-negative integer, got %q", envPrefix, key, v)
+		return 0
+	}
+	return n
+}
+
+func (e *envReader) duration(key string) time.Duration {
+	v := e.string(key)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.fail("%s%s must be a duration, got %q", envPrefix, key, v)
+		return 0
+	}
+	return d
+}
+
+func (e *envReader) bool(key string) bool {
+	if b := e.optionalBool(key); b != nil {
+		return *b
+	}
+	return false
+}
+
+func (e *envReader) optionalBool(key string) *bool {
+	v := e.string(key)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.fail("%s%s must be a boolean, got %q", envPrefix, key, v)
+		return nil
+	}
+	return &b
+}
+
+func countNonEmpty(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
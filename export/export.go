@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Options 为导出配置。
+type Options struct {
+	// Profile 为脱敏规则，为空时原样导出。
+	Profile MaskProfile
+	// Salt 为 hash/fake 策略的 HMAC 密钥，使用这两种策略时必填，应与导出文件分开保管；跨环境导出时保持一致可保留关联关系。
+	Salt string
+	// Canonical 为 true 时输出 Canonical Extended JSON，否则输出 Relaxed 格式。
+	Canonical bool
+	// BatchSize 为游标批大小（<=0 时使用集合 Profile 或 driver 默认值）。
+	BatchSize int32
+}
+
+// Export 将满足 filter 的文档按行写出为 Extended JSON（mongoimport 兼容），返回导出的文档数。
+// 集合注册了访问策略时，按 ctx 中的角色剔除无权读取的字段后再脱敏。
+// 导出以 export 操作经过拦截器，使用拦截器改写后的过滤条件；脱敏规则使用 hash/fake 策略但未设置 Salt 时返回 ErrMissingSalt。
+func Export(ctx context.Context, collection *mongo.Collection, filter any, w io.Writer, opts *Options) (count int64, err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if err := gomongo.CheckFilter(filter); err != nil {
+		return 0, err
+	}
+	if err := opts.Profile.check(opts.Salt); err != nil {
+		return 0, err
+	}
+
+	op := &gomongo.Operation{Name: "export", Filter: filter}
+	ctx, finish, err := gomongo.Intercept(ctx, collection, op)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { finish(err) }()
+
+	// 未显式指定时沿用集合 Profile 的批大小与读偏好；导出为长时间游标，不应用 MaxTime。
+	batchSize := opts.BatchSize
+	if profile, ok := gomongo.LookupProfile(gomongo.PolicyNamespace(collection)); ok {
+		if batchSize <= 0 {
+			batchSize = profile.BatchSize
+		}
+		if profile.ReadPreference != nil {
+			collection = collection.Clone(options.Collection().SetReadPreference(profile.ReadPreference))
+		}
+	}
+
+	findOptions := options.Find()
+	if batchSize > 0 {
+		findOptions.SetBatchSize(batchSize)
+	}
+
+	cursor, err := collection.Find(ctx, op.Filter, findOptions)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	bw := bufio.NewWriter(w)
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			return count, err
+		}
+
+		doc = gomongo.StripRestricted(ctx, gomongo.PolicyNamespace(collection), doc)
+
+		doc, err = Mask(doc, opts.Profile, opts.Salt)
+		if err != nil {
+			return count, err
+		}
+		line, err := bson.MarshalExtJSON(doc, opts.Canonical, false)
+		if err != nil {
+			return count, err
+		}
+		if _, err := bw.Write(append(line, '\n')); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return count, err
+	}
+
+	return count, bw.Flush()
+}
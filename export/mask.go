@@ -0,0 +1,184 @@
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrMissingSalt 表示脱敏规则使用了 hash/fake 策略但未提供密钥。
+var ErrMissingSalt = errors.New("export: salt is required for hash and fake strategies")
+
+// Strategy 为字段脱敏策略。
+type Strategy string
+
+const (
+	// StrategyRedact 将字段值替换为 RedactedValue。
+	StrategyRedact Strategy = "redact"
+	// StrategyHash 将字段值替换为以 salt 为密钥的 HMAC-SHA256 十六进制摘要，相同输入得到相同输出，可保留关联关系；
+	// 不知道 salt 时无法通过枚举常见值（邮箱、手机号）反查原值。
+	StrategyHash Strategy = "hash"
+	// StrategyFake 将字段值替换为由摘要派生的伪造值（保留邮箱、手机号等格式）。
+	StrategyFake Strategy = "fake"
+)
+
+// RedactedValue 为 StrategyRedact 的替换值。
+const RedactedValue = "[REDACTED]"
+
+// MaskProfile 为字段点路径到脱敏策略的映射，路径穿过数组时作用于每个元素。
+type MaskProfile map[string]Strategy
+
+// Mask 按 profile 对文档脱敏并返回新文档，salt 为 hash/fake 策略的密钥，使用这两种策略时不能为空，否则返回 ErrMissingSalt。
+func Mask(doc bson.D, profile MaskProfile, salt string) (bson.D, error) {
+	if len(profile) == 0 {
+		return doc, nil
+	}
+	if err := profile.check(salt); err != nil {
+		return nil, err
+	}
+	out := cloneD(doc)
+	for path, strategy := range profile {
+		maskPath(out, strings.Split(path, "."), strategy, salt)
+	}
+	return out, nil
+}
+
+// check 校验使用 hash/fake 策略时提供了密钥。
+func (p MaskProfile) check(salt string) error {
+	if salt != "" {
+		return nil
+	}
+	for _, strategy := range p {
+		if strategy == StrategyHash || strategy == StrategyFake {
+			return ErrMissingSalt
+		}
+	}
+	return nil
+}
+
+func maskPath(doc bson.D, parts []string, strategy Strategy, salt string) {
+	for i := range doc {
+		if doc[i].Key != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			doc[i].Value = maskValue(doc[i].Value, strategy, salt)
+			return
+		}
+		maskNested(doc[i].Value, parts[1:], strategy, salt)
+		return
+	}
+}
+
+func maskNested(value any, parts []string, strategy Strategy, salt string) {
+	switch v := value.(type) {
+	case bson.D:
+		maskPath(v, parts, strategy, salt)
+	case bson.A:
+		for _, item := range v {
+			maskNested(item, parts, strategy, salt)
+		}
+	}
+}
+
+func maskValue(value any, strategy Strategy, salt string) any {
+	if value == nil {
+		return nil
+	}
+	if arr, ok := value.(bson.A); ok {
+		out := make(bson.A, len(arr))
+		for i, item := range arr {
+			out[i] = maskValue(item, strategy, salt)
+		}
+		return out
+	}
+
+	switch strategy {
+	case StrategyHash:
+		return digest(value, salt)
+	case StrategyFake:
+		return fake(value, digest(value, salt))
+	default:
+		return RedactedValue
+	}
+}
+
+func digest(value any, salt string) string {
+	h := hmac.New(sha256.New, []byte(salt))
+	h.Write([]byte(fmt.Sprint(value)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fake 根据原值类型与格式生成确定性的伪造值。
+func fake(value any, hash string) any {
+	switch v := value.(type) {
+	case string:
+		switch {
+		case strings.Contains(v, "@"):
+			return "user_" + hash[:10] + "@example.com"
+		case isPhone(v):
+			return "1" + digits(hash, len(v)-1)
+		default:
+			return "fake_" + hash[:12]
+		}
+	case int32:
+		return int32(0)
+	case int64:
+		return int64(0)
+	case float64:
+		return float64(0)
+	case bool:
+		return false
+	default:
+		return "fake_" + hash[:12]
+	}
+}
+
+func isPhone(s string) bool {
+	if len(s) < 7 || len(s) > 15 {
+		return false
+	}
+	for _, r := range strings.TrimPrefix(s, "+") {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// digits 从十六进制摘要中派生 n 位数字。
+func digits(hash string, n int) string {
+	var sb strings.Builder
+	for i := 0; sb.Len() < n; i++ {
+		sb.WriteByte('0' + hash[i%len(hash)]%10)
+	}
+	return sb.String()
+}
+
+func cloneD(doc bson.D) bson.D {
+	out := make(bson.D, len(doc))
+	for i, e := range doc {
+		out[i] = bson.E{Key: e.Key, Value: cloneValue(e.Value)}
+	}
+	return out
+}
+
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case bson.D:
+		return cloneD(v)
+	case bson.A:
+		out := make(bson.A, len(v))
+		for i, item := range v {
+			out[i] = cloneValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
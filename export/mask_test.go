@@ -0,0 +1,133 @@
+package export
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMask(t *testing.T) {
+	doc := bson.D{
+		{Key: "name", Value: "ann"},
+		{Key: "email", Value: "ann@corp.com"},
+		{Key: "phone", Value: "13800138000"},
+		{Key: "age", Value: int32(30)},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "items", Value: bson.A{
+			bson.D{{Key: "sku", Value: "x"}, {Key: "owner", Value: "bob"}},
+			bson.D{{Key: "sku", Value: "y"}},
+		}},
+		{Key: "note", Value: nil},
+	}
+
+	tests := []struct {
+		name    string
+		profile MaskProfile
+		salt    string
+		check   func(t *testing.T, out bson.D)
+		err     error
+	}{
+		{
+			name:  "empty profile",
+			check: func(t *testing.T, out bson.D) { equal(t, out, doc) },
+		},
+		{
+			name:    "redact",
+			profile: MaskProfile{"name": StrategyRedact, "tags": StrategyRedact, "note": StrategyRedact},
+			check: func(t *testing.T, out bson.D) {
+				equal(t, field(out, "name"), RedactedValue)
+				equal(t, field(out, "tags"), bson.A{RedactedValue, RedactedValue})
+				equal(t, field(out, "note"), nil)
+			},
+		},
+		{
+			name:    "hash is keyed",
+			profile: MaskProfile{"name": StrategyHash},
+			salt:    "k1",
+			check: func(t *testing.T, out bson.D) {
+				hash, _ := field(out, "name").(string)
+				if len(hash) != 64 || hash == digest("ann", "k2") {
+					t.Errorf("hash = %q", hash)
+				}
+				equal(t, hash, digest("ann", "k1"))
+			},
+		},
+		{
+			name:    "fake keeps formats",
+			profile: MaskProfile{"email": StrategyFake, "phone": StrategyFake, "age": StrategyFake},
+			salt:    "k1",
+			check: func(t *testing.T, out bson.D) {
+				email, _ := field(out, "email").(string)
+				if !strings.HasSuffix(email, "@example.com") {
+					t.Errorf("email = %q", email)
+				}
+				phone, _ := field(out, "phone").(string)
+				if len(phone) != len("13800138000") || !isPhone(phone) {
+					t.Errorf("phone = %q", phone)
+				}
+				equal(t, field(out, "age"), int32(0))
+			},
+		},
+		{
+			name:    "path through array",
+			profile: MaskProfile{"items.owner": StrategyRedact},
+			check: func(t *testing.T, out bson.D) {
+				equal(t, field(out, "items"), bson.A{
+					bson.D{{Key: "sku", Value: "x"}, {Key: "owner", Value: RedactedValue}},
+					bson.D{{Key: "sku", Value: "y"}},
+				})
+			},
+		},
+		{
+			name:    "missing path",
+			profile: MaskProfile{"profile.city": StrategyRedact},
+			check:   func(t *testing.T, out bson.D) { equal(t, out, doc) },
+		},
+		{
+			name:    "hash without salt",
+			profile: MaskProfile{"name": StrategyHash},
+			err:     ErrMissingSalt,
+		},
+		{
+			name:    "fake without salt",
+			profile: MaskProfile{"name": StrategyRedact, "email": StrategyFake},
+			err:     ErrMissingSalt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Mask(doc, tt.profile, tt.salt)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			tt.check(t, out)
+		})
+	}
+
+	if field(doc, "name") != "ann" || field(field(doc, "items").(bson.A)[0].(bson.D), "owner") != "bob" {
+		t.Errorf("input document was modified: %v", doc)
+	}
+}
+
+func field(doc bson.D, key string) any {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+func equal(t *testing.T, got, want any) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/fireflycore/go-mongo/internal"
+)
+
+// 提取器返回的字段名：写入命令日志对应的独立字段，其余字段写入 fields。
+const (
+	LogFieldTraceId     = internal.FieldTraceId
+	LogFieldParentId    = internal.FieldParentId
+	LogFieldUserId      = internal.FieldUserId
+	LogFieldAppId       = internal.FieldAppId
+	LogFieldTenantId    = internal.FieldTenantId
+	LogFieldInvokeAppId = internal.FieldInvokeAppId
+	LogFieldTargetAppId = internal.FieldTargetAppId
+)
+
+// ContextExtractor 从 ctx 中提取写入命令日志的关联字段（如 session id、请求路径、region）。
+// 返回 LogField* 字段名时写入日志对应的独立字段，其余字段写入 fields；空值忽略。
+type ContextExtractor interface {
+	Extract(ctx context.Context) map[string]string
+}
+
+// ContextExtractorFunc 为函数形式的 ContextExtractor。
+type ContextExtractorFunc func(ctx context.Context) map[string]string
+
+// Extract 实现 ContextExtractor。
+func (f ContextExtractorFunc) Extract(ctx context.Context) map[string]string {
+	return f(ctx)
+}
+
+// LogMetadataConf 为命令日志链路字段的 metadata key 映射，为空的字段使用 Firefly 默认头部。
+// key 依次从 incoming metadata、outgoing metadata 与同名 context 值中读取，不区分大小写。
+type LogMetadataConf struct {
+	// TraceId 为 trace id 的 key（默认 x-trace-id），设置为 "traceparent" 时按 W3C Trace Context 解析。
+	// 仅在 ctx 中没有有效 Span 时使用。
+	TraceId     string `json:"trace_id"`
+	UserId      string `json:"user_id"`
+	AppId       string `json:"app_id"`
+	TenantId    string `json:"tenant_id"`
+	InvokeAppId string `json:"invoke_app_id"`
+	TargetAppId string `json:"target_app_id"`
+}
+
+// MetadataExtractor 返回按 keys 从 gRPC metadata 读取 trace/user/app/tenant id 的提取器（keys 为空时使用 Firefly 默认头部），
+// 即未设置 WithContextExtractor 时的默认行为，便于与自定义提取器组合使用。
+func MetadataExtractor(keys *LogMetadataConf) ContextExtractor {
+	var k internal.MetadataKeys
+	if keys != nil {
+		k = internal.MetadataKeys(*keys)
+	}
+	return ContextExtractorFunc(internal.MetadataExtractor(k))
+}
+
+// WithContextExtractor 设置命令日志关联字段提取器，替代默认的 metadata 提取（需要保留时传入 MetadataExtractor）；
+// 多个提取器按顺序执行，后者覆盖前者的同名字段。ctx 中存在有效 Span 时 trace_id/parent_id 以 Span 为准。
+func (c *Conf) WithContextExtractor(extractors ...ContextExtractor) {
+	c.extractors = extractors
+}
+
+// logExtractors 返回传给内部 logger 的提取器，未设置时按 LogMetadata 读取 metadata。
+func (c *Conf) logExtractors() []internal.Extractor {
+	extractors := c.extractors
+	if len(extractors) == 0 {
+		extractors = []ContextExtractor{MetadataExtractor(c.LogMetadata)}
+	}
+
+	list := make([]internal.Extractor, 0, len(extractors))
+	for _, e := range extractors {
+		if e != nil {
+			list = append(list, e.Extract)
+		}
+	}
+	return list
+}
@@ -0,0 +1,117 @@
+package field
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Field 为带值类型的字段路径，通常由 mongogen 生成，避免在查询中手写字段名。
+type Field[T any] string
+
+// Path 返回字段的点路径。
+func (f Field[T]) Path() string {
+	return string(f)
+}
+
+// Eq 返回等值条件。
+func (f Field[T]) Eq(v T) bson.E {
+	return bson.E{Key: string(f), Value: v}
+}
+
+// Ne 返回不等条件。
+func (f Field[T]) Ne(v T) bson.E {
+	return f.op("$ne", v)
+}
+
+// Gt 返回大于条件。
+func (f Field[T]) Gt(v T) bson.E {
+	return f.op("$gt", v)
+}
+
+// Gte 返回大于等于条件。
+func (f Field[T]) Gte(v T) bson.E {
+	return f.op("$gte", v)
+}
+
+// Lt 返回小于条件。
+func (f Field[T]) Lt(v T) bson.E {
+	return f.op("$lt", v)
+}
+
+// Lte 返回小于等于条件。
+func (f Field[T]) Lte(v T) bson.E {
+	return f.op("$lte", v)
+}
+
+// Between 返回 [lo, hi) 范围条件。
+func (f Field[T]) Between(lo, hi T) bson.E {
+	return bson.E{Key: string(f), Value: bson.D{{Key: "$gte", Value: lo}, {Key: "$lt", Value: hi}}}
+}
+
+// In 返回 $in 条件。
+func (f Field[T]) In(vs ...T) bson.E {
+	return f.op("$in", vs)
+}
+
+// Nin 返回 $nin 条件。
+func (f Field[T]) Nin(vs ...T) bson.E {
+	return f.op("$nin", vs)
+}
+
+// Exists 返回 $exists 条件。
+func (f Field[T]) Exists(exists bool) bson.E {
+	return f.op("$exists", exists)
+}
+
+// IsNull 返回字段为 null 或不存在的条件（例如未软删除：DeletedAt.IsNull()）。
+func (f Field[T]) IsNull() bson.E {
+	return bson.E{Key: string(f), Value: nil}
+}
+
+// Set 返回用于 $set 文档的赋值项。
+func (f Field[T]) Set(v T) bson.E {
+	return bson.E{Key: string(f), Value: v}
+}
+
+// Asc 返回升序排序项。
+func (f Field[T]) Asc() bson.E {
+	return bson.E{Key: string(f), Value: 1}
+}
+
+// Desc 返回降序排序项。
+func (f Field[T]) Desc() bson.E {
+	return bson.E{Key: string(f), Value: -1}
+}
+
+// Include 返回包含该字段的投影项。
+func (f Field[T]) Include() bson.E {
+	return bson.E{Key: string(f), Value: 1}
+}
+
+// Exclude 返回排除该字段的投影项。
+func (f Field[T]) Exclude() bson.E {
+	return bson.E{Key: string(f), Value: 0}
+}
+
+func (f Field[T]) op(op string, v any) bson.E {
+	return bson.E{Key: string(f), Value: bson.D{{Key: op, Value: v}}}
+}
+
+// Filter 将条件组合为过滤文档。
+func Filter(conds ...bson.E) bson.D {
+	return bson.D(conds)
+}
+
+// Sort 将排序项组合为排序文档。
+func Sort(items ...bson.E) bson.D {
+	return bson.D(items)
+}
+
+// Projection 将投影项组合为投影文档。
+func Projection(items ...bson.E) bson.D {
+	return bson.D(items)
+}
+
+// Set 将赋值项组合为 $set 更新文档。
+func Set(items ...bson.E) bson.D {
+	return bson.D{{Key: "$set", Value: bson.D(items)}}
+}
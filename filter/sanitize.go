@@ -0,0 +1,116 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrUnsafeKey 表示不可信输入中包含操作符键（以 $ 开头）或空字节。
+var ErrUnsafeKey = errors.New("filter: unsafe key in untrusted input")
+
+// Sanitize 递归移除不可信输入中的操作符键（$gt、$where、$ne 等）与包含空字节的键，返回新的 map，不修改 input。
+// 适用于把请求体直接绑定为查询条件的场景：{"password": {"$ne": ""}} 会变为 {"password": {}}，只能按字面量匹配。
+func Sanitize(input map[string]any) map[string]any {
+	if input == nil {
+		return nil
+	}
+	out := make(map[string]any, len(input))
+	for k, v := range input {
+		if unsafeKey(k) {
+			continue
+		}
+		out[k] = sanitizeValue(v)
+	}
+	return out
+}
+
+// Validate 与 Sanitize 检查同样的键，但发现时返回包装了 ErrUnsafeKey 的错误，便于 API 层直接返回 400。
+func Validate(input map[string]any) error {
+	return validateValue("", input)
+}
+
+func sanitizeValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return Sanitize(val)
+	case bson.M:
+		return bson.M(Sanitize(val))
+	case bson.D:
+		out := make(bson.D, 0, len(val))
+		for _, e := range val {
+			if unsafeKey(e.Key) {
+				continue
+			}
+			out = append(out, bson.E{Key: e.Key, Value: sanitizeValue(e.Value)})
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = sanitizeValue(item)
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(val))
+		for i, item := range val {
+			out[i] = sanitizeValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func validateValue(path string, v any) error {
+	check := func(k string, child any) error {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		if unsafeKey(k) {
+			return fmt.Errorf("%w: %q", ErrUnsafeKey, p)
+		}
+		return validateValue(p, child)
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if err := check(k, child); err != nil {
+				return err
+			}
+		}
+	case bson.M:
+		for k, child := range val {
+			if err := check(k, child); err != nil {
+				return err
+			}
+		}
+	case bson.D:
+		for _, e := range val {
+			if err := check(e.Key, e.Value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if err := validateValue(path, item); err != nil {
+				return err
+			}
+		}
+	case bson.A:
+		for _, item := range val {
+			if err := validateValue(path, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func unsafeKey(k string) bool {
+	return strings.HasPrefix(k, "$") || strings.ContainsRune(k, 0)
+}
@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  map[string]any
+		// paths 为 Validate 可能报告的键路径（map 遍历顺序不定），为空表示输入安全。
+		paths []string
+	}{
+		{name: "nil"},
+		{
+			name:  "literal values",
+			input: map[string]any{"name": "ann", "age": 30, "note": "$where"},
+			want:  map[string]any{"name": "ann", "age": 30, "note": "$where"},
+		},
+		{
+			name:  "top-level operator",
+			input: map[string]any{"$where": "true", "name": "ann"},
+			want:  map[string]any{"name": "ann"},
+			paths: []string{`"$where"`},
+		},
+		{
+			name:  "operator inside a field",
+			input: map[string]any{"password": map[string]any{"$ne": ""}},
+			want:  map[string]any{"password": map[string]any{}},
+			paths: []string{`"password.$ne"`},
+		},
+		{
+			name:  "bson types",
+			input: map[string]any{"p": bson.M{"$gt": 1, "a": 1}, "d": bson.D{{Key: "$in", Value: bson.A{1}}, {Key: "b", Value: 2}}},
+			want:  map[string]any{"p": bson.M{"a": 1}, "d": bson.D{{Key: "b", Value: 2}}},
+			paths: []string{`"p.$gt"`, `"d.$in"`},
+		},
+		{
+			name:  "inside arrays",
+			input: map[string]any{"or": []any{map[string]any{"$where": "1"}, "x"}, "tags": bson.A{bson.M{"$regex": ".*"}}},
+			want:  map[string]any{"or": []any{map[string]any{}, "x"}, "tags": bson.A{bson.M{}}},
+			paths: []string{`"or.$where"`, `"tags.$regex"`},
+		},
+		{
+			name:  "null byte",
+			input: map[string]any{"a\x00b": 1, "c": 2},
+			want:  map[string]any{"c": 2},
+			paths: []string{`"a\x00b"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sanitize(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sanitize = %v, want %v", got, tt.want)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("Validate(Sanitize) = %v", err)
+			}
+
+			err := Validate(tt.input)
+			if len(tt.paths) == 0 {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnsafeKey) || !slices.ContainsFunc(tt.paths, func(p string) bool { return strings.HasSuffix(err.Error(), p) }) {
+				t.Errorf("Validate = %v, want ErrUnsafeKey at one of %v", err, tt.paths)
+			}
+		})
+	}
+}
+
+func TestSanitizeKeepsInput(t *testing.T) {
+	input := map[string]any{"password": map[string]any{"$ne": ""}}
+	Sanitize(input)
+	if _, ok := input["password"].(map[string]any)["$ne"]; !ok {
+		t.Errorf("input was modified: %v", input)
+	}
+}
@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FilterCache 按调用方提供的 key 缓存静态过滤条件的序列化结果（bson.Raw），
+// driver 编码 bson.Raw 时只复制字节，省去热点读路径上每次请求的反射序列化。
+// 只应缓存与请求无关的静态条件（例如 deleted_at: null），缓存不会失效。
+type FilterCache struct {
+	entries sync.Map
+}
+
+// NewFilterCache 创建 FilterCache。
+func NewFilterCache() *FilterCache {
+	return &FilterCache{}
+}
+
+// DefaultFilterCache 为 CachedFilter 使用的全局缓存。
+var DefaultFilterCache = NewFilterCache()
+
+// Get 返回 key 对应的序列化结果，未命中时调用 build 构造过滤条件并序列化后缓存。
+// 并发首次访问时 build 可能被调用多次，最终只保留一份结果。
+func (c *FilterCache) Get(key string, build func() any) (bson.Raw, error) {
+	if v, ok := c.entries.Load(key); ok {
+		return v.(bson.Raw), nil
+	}
+
+	raw, err := bson.Marshal(build())
+	if err != nil {
+		return nil, err
+	}
+
+	v, _ := c.entries.LoadOrStore(key, bson.Raw(raw))
+	return v.(bson.Raw), nil
+}
+
+// Invalidate 移除 key 对应的缓存。
+func (c *FilterCache) Invalidate(key string) {
+	c.entries.Delete(key)
+}
+
+// Reset 清空缓存。
+func (c *FilterCache) Reset() {
+	c.entries.Clear()
+}
+
+// CachedFilter 使用 DefaultFilterCache 获取静态过滤条件的序列化结果。
+func CachedFilter(key string, build func() any) (bson.Raw, error) {
+	return DefaultFilterCache.Get(key, build)
+}
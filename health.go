@@ -0,0 +1,66 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// Health 为一次健康检查的结果，可直接序列化后作为就绪探针的响应体。
+type Health struct {
+	// Healthy 表示按指定读偏好 Ping 成功。
+	Healthy bool `json:"healthy"`
+	// Latency 为 Ping 的往返耗时。
+	Latency    time.Duration `json:"latency"`
+	Topology   Topology      `json:"topology"`
+	ReplicaSet string        `json:"replica_set"`
+	// PrimaryAvailable 表示当前存在可写主节点（单节点与 mongos 可达即视为可写）。
+	PrimaryAvailable bool `json:"primary_available"`
+	// Primary 为副本集主节点地址，无主或非副本集时为空。
+	Primary string `json:"primary"`
+	// Error 为检查失败的原因。
+	Error string `json:"error,omitempty"`
+}
+
+// HealthCheck 按 Conf.ReadPreference（未配置时为 primary）执行健康检查。
+func (c *Client) HealthCheck(ctx context.Context) (*Health, error) {
+	return CheckHealth(ctx, c.Raw(), c.readPref)
+}
+
+// CheckHealth 按读偏好 rp（为空时为 primary）Ping 服务端并测量往返耗时，再通过 hello 获取拓扑与主节点状态。
+// Ping 失败时返回的 Health 中 Healthy 为 false，同时返回该错误。
+func CheckHealth(ctx context.Context, client *mongo.Client, rp *readpref.ReadPref) (*Health, error) {
+	if rp == nil {
+		rp = readpref.Primary()
+	}
+
+	health := &Health{}
+	start := time.Now()
+	err := client.Ping(ctx, rp)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Error = err.Error()
+		return health, err
+	}
+	health.Healthy = true
+
+	// hello 发往任一可达节点，无主时仍能获取拓扑信息。
+	var hello helloReply
+	err = client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}},
+		options.RunCmd().SetReadPreference(readpref.Nearest())).Decode(&hello)
+	if err != nil {
+		health.Error = err.Error()
+		return health, nil
+	}
+
+	health.Topology = hello.topology()
+	health.ReplicaSet = hello.SetName
+	health.Primary = hello.Primary
+	health.PrimaryAvailable = hello.Primary != "" || hello.IsWritablePrimary
+
+	return health, nil
+}
@@ -0,0 +1,457 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrVersionNotFound 表示指定文档不存在该版本的历史记录。
+var ErrVersionNotFound = errors.New("mongo: document version not found")
+
+// historySuffix 为历史集合后缀：users 的历史保存在 users_history。
+const historySuffix = "_history"
+
+// snapshotInterval 为完整快照的间隔：版本 1、1+snapshotInterval、1+2*snapshotInterval… 保存完整文档，
+// 重建任意版本只需从不晚于它的最近快照开始回放。
+const snapshotInterval = 20
+
+// recordAttempts 为 RecordVersion 因并发写入同一版本号（唯一索引冲突）而重试的次数。
+const recordAttempts = 3
+
+// Revision 为文档的一次变更记录，Set/Unset 为相对上一版本的差异；Snapshot 为 true 时 Set 为该版本的完整文档。
+type Revision struct {
+	Id        string    `json:"id" bson:"_id"`
+	DocId     string    `json:"doc_id" bson:"doc_id"`
+	Version   int64     `json:"version" bson:"version"`
+	Snapshot  bool      `json:"snapshot" bson:"snapshot,omitempty"`
+	Set       bson.Raw  `json:"set" bson:"set,omitempty"`
+	Unset     []string  `json:"unset" bson:"unset,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// HistoryEntry 为 History 返回的单个版本：差异记录与该版本的完整文档。
+type HistoryEntry[T any] struct {
+	Revision
+	Document T `json:"document"`
+}
+
+// HistoryCollection 返回集合对应的历史集合。
+func HistoryCollection(collection *mongo.Collection) *mongo.Collection {
+	return collection.Database().Collection(collection.Name() + historySuffix)
+}
+
+// EnsureHistoryIndexes 在历史集合上创建 (doc_id, version) 唯一索引，保证并发记录时版本号不重复。
+func EnsureHistoryIndexes(ctx context.Context, collection *mongo.Collection) error {
+	_, err := HistoryCollection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "doc_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// RecordVersion 将文档当前状态与最新版本比较，按顶层字段计算差异并写入历史集合，返回新版本号。
+// 与最新版本相同时不写入，返回最新版本号；每 snapshotInterval 个版本写入一次完整快照。
+// 并发记录同一文档时依赖 EnsureHistoryIndexes 创建的唯一索引，版本号冲突时重新读取最新版本后重试。
+func RecordVersion(ctx context.Context, collection *mongo.Collection, id string, doc any) (_ int64, err error) {
+	if err := ValidateID(id); err != nil {
+		return 0, err
+	}
+
+	ctx, finish, err := interceptHistory(ctx, collection, "record_version", id)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { finish(err) }()
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		version, err := recordVersion(ctx, collection, id, raw)
+		if err == nil || !mongo.IsDuplicateKeyError(err) || attempt == recordAttempts {
+			return version, err
+		}
+	}
+}
+
+// recordVersion 基于最新版本计算差异并写入下一版本。
+func recordVersion(ctx context.Context, collection *mongo.Collection, id string, raw bson.Raw) (int64, error) {
+	version, err := latestVersion(ctx, collection, id)
+	if err != nil {
+		return 0, err
+	}
+	revisions, err := loadRevisions(ctx, collection, id, version)
+	if err != nil {
+		return 0, err
+	}
+	current, err := replay(revisions)
+	if err != nil {
+		return 0, err
+	}
+
+	rev, changed, err := nextRevision(current, version, raw)
+	if err != nil || !changed {
+		return version, err
+	}
+	rev.Id = NewUUIDv7()
+	rev.DocId = id
+	rev.CreatedAt = time.Now().UTC()
+	if _, err := HistoryCollection(collection).InsertOne(ctx, rev); err != nil {
+		return 0, err
+	}
+
+	return rev.Version, nil
+}
+
+// nextRevision 计算 current（版本 version）到 raw 的下一版本记录，快照版本保存完整文档；无变化时 changed 为 false。
+func nextRevision(current bson.M, version int64, raw bson.Raw) (rev Revision, changed bool, err error) {
+	set, unset, err := diffTopLevel(current, raw)
+	if err != nil {
+		return rev, false, err
+	}
+	if len(set) == 0 && len(unset) == 0 && version != 0 {
+		return rev, false, nil
+	}
+
+	rev.Version = version + 1
+	if isSnapshot(rev.Version) {
+		rev.Snapshot = true
+		rev.Set = raw
+		return rev, true, nil
+	}
+
+	rev.Set, err = bson.Marshal(set)
+	rev.Unset = unset
+	return rev, true, err
+}
+
+// isSnapshot 判断版本是否保存完整快照。
+func isSnapshot(version int64) bool {
+	return (version-1)%snapshotInterval == 0
+}
+
+// History 分页返回文档的历史版本（按版本号倒序，page 从 1 开始），每个版本附带重建后的完整文档；
+// 差异记录与文档均剔除 ctx 角色无权读取的字段。
+func History[T any](ctx context.Context, collection *mongo.Collection, id string, page, size uint64) (_ []HistoryEntry[T], err error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	ctx, finish, err := interceptHistory(ctx, collection, "history", id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = 5
+	}
+	if size > 100 {
+		size = 100
+	}
+
+	latest, err := latestVersion(ctx, collection, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 本页的版本范围为 (last-size, last]，只读取从最近快照到 last 的记录。
+	last := latest - int64((page-1)*size)
+	if last <= 0 {
+		return nil, nil
+	}
+	first := max(last-int64(size)+1, 1)
+	revisions, err := loadRevisions(ctx, collection, id, last)
+	if err != nil {
+		return nil, err
+	}
+
+	// 顺序回放到每个版本，再按倒序输出本页。
+	entries := make([]HistoryEntry[T], 0, last-first+1)
+	state := bson.M{}
+	for i := range revisions {
+		if err := applyRevision(state, revisions[i]); err != nil {
+			return nil, err
+		}
+		if revisions[i].Version < first {
+			continue
+		}
+
+		rev, err := restrictRevision(ctx, collection, revisions[i])
+		if err != nil {
+			return nil, err
+		}
+		entry := HistoryEntry[T]{Revision: rev}
+		if err := decodeState(ctx, collection, state, &entry.Document); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, nil
+}
+
+// AtVersion 从不晚于 version 的最近快照开始回放差异，重建文档在指定版本时的状态。
+func AtVersion[T any](ctx context.Context, collection *mongo.Collection, id string, version int64) (doc T, err error) {
+	if err := ValidateID(id); err != nil {
+		return doc, err
+	}
+
+	ctx, finish, err := interceptHistory(ctx, collection, "at_version", id)
+	if err != nil {
+		return doc, err
+	}
+	defer func() { finish(err) }()
+
+	if version <= 0 {
+		return doc, ErrVersionNotFound
+	}
+	revisions, err := loadRevisions(ctx, collection, id, version)
+	if err != nil {
+		return doc, err
+	}
+	if len(revisions) == 0 || revisions[len(revisions)-1].Version != version {
+		return doc, ErrVersionNotFound
+	}
+
+	state, err := replay(revisions)
+	if err != nil {
+		return doc, err
+	}
+
+	err = decodeState(ctx, collection, state, &doc)
+	return doc, err
+}
+
+// interceptHistory 以源集合上按 _id 的操作经过拦截器。历史记录不含源文档的其他字段，拦截器改写了过滤条件
+// （如追加租户条件）时，先确认源文档在改写后的条件下可见，否则返回 mongo.ErrNoDocuments；此时已物理删除的文档的历史不可读。
+func interceptHistory(ctx context.Context, collection *mongo.Collection, name, id string) (context.Context, func(error), error) {
+	filter := bson.D{{Key: "_id", Value: id}}
+	op := &Operation{Name: name, Ids: []string{id}, Filter: filter}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return ctx, nil, err
+	}
+	if reflect.DeepEqual(op.Filter, filter) {
+		return ctx, finish, nil
+	}
+
+	n, err := collection.CountDocuments(ctx, op.Filter, options.Count().SetLimit(1))
+	if err == nil && n == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	if err != nil {
+		finish(err)
+		return ctx, nil, err
+	}
+	return ctx, finish, nil
+}
+
+// latestVersion 返回文档的最新版本号，没有历史记录时为 0。
+func latestVersion(ctx context.Context, collection *mongo.Collection, id string) (int64, error) {
+	var rev struct {
+		Version int64 `bson:"version"`
+	}
+	err := HistoryCollection(collection).FindOne(ctx, bson.D{{Key: "doc_id", Value: id}}, options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.D{{Key: "version", Value: 1}})).Decode(&rev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return rev.Version, err
+}
+
+// loadRevisions 读取从不晚于 upTo 的最近快照到 upTo 的历史记录（按版本升序），回放结果即版本 upTo 的文档。
+func loadRevisions(ctx context.Context, collection *mongo.Collection, id string, upTo int64) ([]Revision, error) {
+	if upTo <= 0 {
+		return nil, nil
+	}
+
+	// 快照按固定间隔写入，缺失时（如早于快照机制的历史）从版本 1 开始回放。
+	from := upTo - (upTo-1)%snapshotInterval
+	var snapshot struct {
+		Version int64 `bson:"version"`
+	}
+	err := HistoryCollection(collection).FindOne(ctx, bson.D{
+		{Key: "doc_id", Value: id},
+		{Key: "version", Value: from},
+		{Key: "snapshot", Value: true},
+	}, options.FindOne().SetProjection(bson.D{{Key: "version", Value: 1}})).Decode(&snapshot)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		from = 1
+	} else if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "doc_id", Value: id},
+		{Key: "version", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lte", Value: upTo}}},
+	}
+	cursor, err := HistoryCollection(collection).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Revision
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func replay(revisions []Revision) (bson.M, error) {
+	state := bson.M{}
+	for _, rev := range revisions {
+		if err := applyRevision(state, rev); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// applyRevision 将差异应用到 state：Set 中的键支持点路径，Unset 为需要删除的点路径；快照先清空 state。
+func applyRevision(state bson.M, rev Revision) error {
+	if rev.Snapshot {
+		clear(state)
+	}
+	if len(rev.Set) != 0 {
+		elems, err := rev.Set.Elements()
+		if err != nil {
+			return err
+		}
+		for _, elem := range elems {
+			var value any
+			if err := elem.Value().Unmarshal(&value); err != nil {
+				return err
+			}
+			setPath(state, strings.Split(elem.Key(), "."), value)
+		}
+	}
+	for _, path := range rev.Unset {
+		unsetPath(state, strings.Split(path, "."))
+	}
+	return nil
+}
+
+func setPath(state bson.M, parts []string, value any) {
+	if len(parts) == 1 {
+		state[parts[0]] = value
+		return
+	}
+	setPath(childMap(state, parts[0]), parts[1:], value)
+}
+
+func unsetPath(state bson.M, parts []string) {
+	if len(parts) == 1 {
+		delete(state, parts[0])
+		return
+	}
+	if _, ok := state[parts[0]]; !ok {
+		return
+	}
+	unsetPath(childMap(state, parts[0]), parts[1:])
+}
+
+// childMap 返回 key 对应的子文档，必要时将 bson.D 转换为 bson.M 以便修改。
+func childMap(state bson.M, key string) bson.M {
+	switch v := state[key].(type) {
+	case bson.M:
+		return v
+	case bson.D:
+		m := make(bson.M, len(v))
+		for _, e := range v {
+			m[e.Key] = e.Value
+		}
+		state[key] = m
+		return m
+	default:
+		m := bson.M{}
+		state[key] = m
+		return m
+	}
+}
+
+// diffTopLevel 比较两份文档的顶层字段，返回需要 set 的字段与需要 unset 的字段名。
+func diffTopLevel(current bson.M, next bson.Raw) (bson.D, []string, error) {
+	prev, err := bson.Marshal(current)
+	if err != nil {
+		return nil, nil, err
+	}
+	prevRaw := bson.Raw(prev)
+
+	elems, err := next.Elements()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set := bson.D{}
+	seen := make(map[string]bool, len(elems))
+	for _, elem := range elems {
+		seen[elem.Key()] = true
+		old, err := prevRaw.LookupErr(elem.Key())
+		if err == nil && old.Type == elem.Value().Type && bytes.Equal(old.Value, elem.Value().Value) {
+			continue
+		}
+		set = append(set, bson.E{Key: elem.Key(), Value: elem.Value()})
+	}
+
+	var unset []string
+	for key := range current {
+		if !seen[key] {
+			unset = append(unset, key)
+		}
+	}
+	slices.Sort(unset)
+
+	return set, unset, nil
+}
+
+// restrictRevision 从差异记录中剔除 ctx 角色无权读取的字段。
+func restrictRevision(ctx context.Context, collection *mongo.Collection, rev Revision) (Revision, error) {
+	namespace := PolicyNamespace(collection)
+	if len(restrictedFields(ctx, namespace)) == 0 {
+		return rev, nil
+	}
+
+	if len(rev.Set) != 0 {
+		var set bson.D
+		if err := bson.Unmarshal(rev.Set, &set); err != nil {
+			return rev, err
+		}
+		raw, err := bson.Marshal(StripRestricted(ctx, namespace, set))
+		if err != nil {
+			return rev, err
+		}
+		rev.Set = raw
+	}
+	rev.Unset = slices.DeleteFunc(slices.Clone(rev.Unset), func(field string) bool {
+		return !readable(ctx, namespace, field)
+	})
+	return rev, nil
+}
+
+// decodeState 将回放得到的状态解码到 out，剔除 ctx 角色无权读取的字段。
+func decodeState(ctx context.Context, collection *mongo.Collection, state bson.M, out any) error {
+	raw, err := bson.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return DecodeRestricted(ctx, PolicyNamespace(collection), raw, out)
+}
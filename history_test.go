@@ -0,0 +1,173 @@
+package mongo
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func mustRaw(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestDiffTopLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		current bson.M
+		next    bson.D
+		set     []string
+		unset   []string
+	}{
+		{
+			name: "first version",
+			next: bson.D{{Key: "a", Value: 1}, {Key: "b", Value: "x"}},
+			set:  []string{"a", "b"},
+		},
+		{
+			name:    "unchanged",
+			current: bson.M{"a": int32(1), "b": "x"},
+			next:    bson.D{{Key: "a", Value: 1}, {Key: "b", Value: "x"}},
+		},
+		{
+			name:    "changed and removed",
+			current: bson.M{"a": int32(1), "b": "x", "c": true},
+			next:    bson.D{{Key: "a", Value: 2}, {Key: "b", Value: "x"}},
+			set:     []string{"a"},
+			unset:   []string{"c"},
+		},
+		{
+			name:    "same value with another type",
+			current: bson.M{"a": int32(1)},
+			next:    bson.D{{Key: "a", Value: int64(1)}},
+			set:     []string{"a"},
+		},
+		{
+			name:    "nested change replaces the field",
+			current: bson.M{"p": bson.D{{Key: "city", Value: "x"}}},
+			next:    bson.D{{Key: "p", Value: bson.D{{Key: "city", Value: "y"}}}},
+			set:     []string{"p"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current
+			if current == nil {
+				current = bson.M{}
+			}
+			set, unset, err := diffTopLevel(current, mustRaw(t, tt.next))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, e := range set {
+				keys = append(keys, e.Key)
+			}
+			if !slices.Equal(keys, tt.set) {
+				t.Errorf("set = %v, want %v", keys, tt.set)
+			}
+			if !slices.Equal(unset, tt.unset) {
+				t.Errorf("unset = %v, want %v", unset, tt.unset)
+			}
+		})
+	}
+}
+
+func TestApplyRevision(t *testing.T) {
+	tests := []struct {
+		name  string
+		state bson.M
+		rev   Revision
+		want  bson.M
+	}{
+		{
+			name:  "set and unset",
+			state: bson.M{"a": int32(1), "b": int32(2)},
+			rev:   Revision{Set: mustRaw(t, bson.D{{Key: "a", Value: 3}}), Unset: []string{"b"}},
+			want:  bson.M{"a": int32(3)},
+		},
+		{
+			name:  "dotted paths",
+			state: bson.M{"p": bson.D{{Key: "city", Value: "x"}, {Key: "zip", Value: "1"}}},
+			rev:   Revision{Set: mustRaw(t, bson.D{{Key: "p.city", Value: "y"}}), Unset: []string{"p.zip", "q.r"}},
+			want:  bson.M{"p": bson.M{"city": "y"}},
+		},
+		{
+			name:  "snapshot replaces the state",
+			state: bson.M{"a": int32(1), "b": int32(2)},
+			rev:   Revision{Snapshot: true, Set: mustRaw(t, bson.D{{Key: "c", Value: 3}})},
+			want:  bson.M{"c": int32(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := applyRevision(tt.state, tt.rev); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.state, tt.want) {
+				t.Errorf("state = %v, want %v", tt.state, tt.want)
+			}
+		})
+	}
+}
+
+// TestNextRevision 连续记录多个版本，确认快照位置正确，且从任一快照开始回放与从版本 1 回放结果一致。
+func TestNextRevision(t *testing.T) {
+	docs := make([]bson.D, 0, 2*snapshotInterval+3)
+	for i := range cap(docs) {
+		doc := bson.D{{Key: "n", Value: int32(i)}}
+		if i%3 == 0 {
+			doc = append(doc, bson.E{Key: "even", Value: true})
+		}
+		docs = append(docs, doc)
+	}
+
+	var revisions []Revision
+	state := bson.M{}
+	for i, doc := range docs {
+		rev, changed, err := nextRevision(state, int64(len(revisions)), mustRaw(t, doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !changed {
+			t.Fatalf("doc %d: no change detected", i)
+		}
+		if rev.Version != int64(i+1) {
+			t.Fatalf("doc %d: version = %d, want %d", i, rev.Version, i+1)
+		}
+		if want := i%snapshotInterval == 0; rev.Snapshot != want {
+			t.Errorf("version %d: snapshot = %v, want %v", rev.Version, rev.Snapshot, want)
+		}
+		revisions = append(revisions, rev)
+		if state, err = replay(revisions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, changed, err := nextRevision(state, int64(len(revisions)), mustRaw(t, docs[len(docs)-1])); err != nil || changed {
+		t.Errorf("unchanged document: changed = %v, err = %v", changed, err)
+	}
+
+	for _, version := range []int64{1, snapshotInterval, snapshotInterval + 1, int64(len(docs))} {
+		from := version - (version-1)%snapshotInterval
+		fromSnapshot, err := replay(revisions[from-1 : version])
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := replay(revisions[:version])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fromSnapshot, full) {
+			t.Errorf("version %d: replay from snapshot = %v, want %v", version, fromSnapshot, full)
+		}
+	}
+}
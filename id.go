@@ -0,0 +1,105 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrInvalidID 表示 id 未通过校验，helper 会在发起查询前直接返回该错误。
+var ErrInvalidID = errors.New("mongo: invalid id")
+
+// IDValidator 校验单个 id，返回非 nil 时视为非法。
+type IDValidator func(id string) error
+
+// idValidator 保存当前生效的 IDValidator，默认不校验。
+var idValidator atomic.Pointer[IDValidator]
+
+// SetIDValidator 设置全局 id 校验器，传入 nil 关闭校验。
+func SetIDValidator(v IDValidator) {
+	if v == nil {
+		idValidator.Store(nil)
+		return
+	}
+	idValidator.Store(&v)
+}
+
+// ValidateID 使用当前校验器校验 id，失败时返回包装了 ErrInvalidID 的错误。
+func ValidateID(id string) error {
+	v := idValidator.Load()
+	if v == nil {
+		return nil
+	}
+	if err := (*v)(id); err != nil {
+		if errors.Is(err, ErrInvalidID) {
+			return err
+		}
+		return fmt.Errorf("%w: %q: %v", ErrInvalidID, id, err)
+	}
+	return nil
+}
+
+// ValidateIDs 校验 id 列表，返回第一个非法 id 的错误。
+func ValidateIDs(ids []string) error {
+	for _, id := range ids {
+		if err := ValidateID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateUUID 校验标准 36 位 UUID 字符串（Table 默认使用 UUIDv7）。
+func ValidateUUID(id string) error {
+	if len(id) != 36 {
+		return errors.New("uuid must be 36 characters")
+	}
+	_, err := uuid.Parse(id)
+	return err
+}
+
+// ValidateObjectID 校验 24 位十六进制 ObjectID 字符串。
+func ValidateObjectID(id string) error {
+	_, err := bson.ObjectIDFromHex(id)
+	return err
+}
+
+// crockford 为 ULID 使用的 Crockford Base32 字符集。
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ValidateULID 校验 26 位 Crockford Base32 ULID 字符串（不区分大小写）。
+func ValidateULID(id string) error {
+	if len(id) != 26 {
+		return errors.New("ulid must be 26 characters")
+	}
+	upper := strings.ToUpper(id)
+	// 首字符超过 7 时时间戳溢出 48 位。
+	if upper[0] > '7' {
+		return errors.New("ulid timestamp overflow")
+	}
+	for i := 0; i < len(upper); i++ {
+		if strings.IndexByte(crockford, upper[i]) < 0 {
+			return fmt.Errorf("invalid ulid character %q", upper[i])
+		}
+	}
+	return nil
+}
+
+// AnyOf 组合多个校验器，任意一个通过即视为合法。
+func AnyOf(validators ...IDValidator) IDValidator {
+	return func(id string) error {
+		var errs []error
+		for _, v := range validators {
+			err := v(id)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,236 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"weak"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrTenantFilter 表示过滤条件不是 bson.D/[]bson.D，TenantInterceptor 无法追加租户条件。
+var ErrTenantFilter = errors.New("mongo: tenant interceptor requires a bson.D filter")
+
+// ErrTenantMismatch 表示插入的文档不属于 ctx 中的租户，或操作无法按租户限定（如删除整个集合）。
+var ErrTenantMismatch = errors.New("mongo: operation is not scoped to the current tenant")
+
+// Operation 描述一次 helper 操作（Delete、Find、ApplyJSONPatch 等），供 Interceptor 读取或改写。
+type Operation struct {
+	// Name 为操作名，例如 delete、soft_delete、find、patch。
+	Name       string
+	Database   string
+	Collection string
+	// Ids 为按 id 操作时的目标 id。
+	Ids []string
+	// Filter 为过滤条件，Before 可改写（例如追加租户条件），helper 使用改写后的值执行。
+	// 逐条执行的批量操作（patch_many）为 []bson.D，与 Ids 一一对应。
+	Filter any
+	// Update 为更新文档，Before 可改写。
+	Update any
+	// Documents 为插入的文档（insert），Before 可改写。
+	Documents []any
+	// Start 为操作开始时间。
+	Start time.Time
+}
+
+// Interceptor 为 helper 操作的横切扩展点：Before 在执行前按注册顺序调用，返回错误时操作不执行；
+// After 在执行后按相反顺序调用（仅对 Before 成功的拦截器）。
+type Interceptor interface {
+	Before(ctx context.Context, op *Operation) (context.Context, error)
+	After(ctx context.Context, op *Operation, err error)
+}
+
+// InterceptorFuncs 以函数形式实现 Interceptor，未设置的回调视为空操作。
+type InterceptorFuncs struct {
+	BeforeFunc func(ctx context.Context, op *Operation) (context.Context, error)
+	AfterFunc  func(ctx context.Context, op *Operation, err error)
+}
+
+func (f InterceptorFuncs) Before(ctx context.Context, op *Operation) (context.Context, error) {
+	if f.BeforeFunc == nil {
+		return ctx, nil
+	}
+	return f.BeforeFunc(ctx, op)
+}
+
+func (f InterceptorFuncs) After(ctx context.Context, op *Operation, err error) {
+	if f.AfterFunc != nil {
+		f.AfterFunc(ctx, op, err)
+	}
+}
+
+// interceptors 按 client（见 clientKey）保存已注册的拦截器，写入时加锁、读取无锁。
+var (
+	interceptors   sync.Map
+	interceptorsMu sync.Mutex
+)
+
+// Use 为 client 注册拦截器，作用于该连接上所有集合的 helper 操作。
+func (c *Client) Use(list ...Interceptor) {
+	UseInterceptors(c.Raw(), list...)
+}
+
+// UseInterceptors 为 driver client 注册拦截器，供通过 New 获取数据库句柄的调用方使用（db.Client()）。
+func UseInterceptors(client *mongo.Client, list ...Interceptor) {
+	interceptorsMu.Lock()
+	defer interceptorsMu.Unlock()
+
+	key := trackClient(client)
+	prev, _ := interceptors.Load(key)
+	next, _ := prev.([]Interceptor)
+	interceptors.Store(key, append(append([]Interceptor{}, next...), list...))
+}
+
+// Intercept 供子包（export、partition 等）中的 helper 接入拦截器，语义与包内 helper 相同：
+// 依次调用 Before，返回改写后的 ctx 与结束回调，调用方须使用 op 中改写后的 Filter/Update/Documents 执行，并在结束时调用回调。
+func Intercept(ctx context.Context, collection *mongo.Collection, op *Operation) (context.Context, func(error), error) {
+	return intercept(ctx, collection, op)
+}
+
+// intercept 依次调用 Before，返回改写后的 ctx 与结束回调；Before 失败时已成功的拦截器会收到该错误。
+func intercept(ctx context.Context, collection *mongo.Collection, op *Operation) (context.Context, func(error), error) {
+	op.Database = collection.Database().Name()
+	op.Collection = collection.Name()
+	op.Start = time.Now()
+
+	v, ok := interceptors.Load(weak.Make(collection.Database().Client()))
+	if !ok {
+		return ctx, func(error) {}, nil
+	}
+	list := v.([]Interceptor)
+
+	ran := 0
+	finish := func(err error) {
+		for i := ran - 1; i >= 0; i-- {
+			list[i].After(ctx, op, err)
+		}
+	}
+
+	for _, in := range list {
+		next, err := in.Before(ctx, op)
+		if err != nil {
+			finish(err)
+			return ctx, nil, err
+		}
+		if next != nil {
+			ctx = next
+		}
+		ran++
+	}
+	return ctx, finish, nil
+}
+
+// ValidateInterceptor 在执行前校验 id 与过滤条件/更新文档中的服务端 JavaScript。
+// helper 已内置相同校验，该拦截器用于在其他拦截器改写 Filter/Update 后再次校验。
+func ValidateInterceptor() Interceptor {
+	return InterceptorFuncs{BeforeFunc: func(ctx context.Context, op *Operation) (context.Context, error) {
+		if err := ValidateIDs(op.Ids); err != nil {
+			return ctx, err
+		}
+		if err := CheckFilter(op.Filter); err != nil {
+			return ctx, err
+		}
+		return ctx, CheckFilter(op.Update)
+	}}
+}
+
+// TenantInterceptor 从 ctx 读取租户 id 并追加到过滤条件的 field 字段上，实现多租户隔离；
+// tenant 返回 false 时不追加。过滤条件须为 bson.D 或 []bson.D（为空时视为 bson.D{}），其他类型无法追加条件，返回 ErrTenantFilter。
+// 插入的文档为 bson.D/bson.M/map[string]any 时缺少 field 会被补上，其他类型须已写入相同的租户 id，否则返回 ErrTenantMismatch；
+// 无法按租户限定的 drop_collection 在存在租户时直接拒绝。
+func TenantInterceptor(field string, tenant func(ctx context.Context) (string, bool)) Interceptor {
+	return InterceptorFuncs{BeforeFunc: func(ctx context.Context, op *Operation) (context.Context, error) {
+		id, ok := tenant(ctx)
+		if !ok {
+			return ctx, nil
+		}
+		if op.Name == "drop_collection" {
+			return ctx, fmt.Errorf("%w: %s", ErrTenantMismatch, op.Name)
+		}
+		if len(op.Documents) != 0 {
+			docs := make([]any, len(op.Documents))
+			for i, doc := range op.Documents {
+				scoped, err := tenantDocument(doc, field, id)
+				if err != nil {
+					return ctx, err
+				}
+				docs[i] = scoped
+			}
+			op.Documents = docs
+			return ctx, nil
+		}
+
+		scope := bson.E{Key: field, Value: id}
+		switch filter := op.Filter.(type) {
+		case nil:
+			op.Filter = bson.D{scope}
+		case bson.D:
+			op.Filter = append(append(bson.D{}, filter...), scope)
+		case []bson.D:
+			scoped := make([]bson.D, len(filter))
+			for i, f := range filter {
+				scoped[i] = append(append(bson.D{}, f...), scope)
+			}
+			op.Filter = scoped
+		default:
+			return ctx, fmt.Errorf("%w: %s %T", ErrTenantFilter, op.Name, op.Filter)
+		}
+		return ctx, nil
+	}}
+}
+
+// tenantDocument 返回写入了租户 id 的文档副本；无法修改的类型校验其 field 是否为 id。
+func tenantDocument(doc any, field, id string) (any, error) {
+	switch d := doc.(type) {
+	case bson.D:
+		for _, e := range d {
+			if e.Key == field {
+				if e.Value != id {
+					return nil, fmt.Errorf("%w: %s=%v", ErrTenantMismatch, field, e.Value)
+				}
+				return d, nil
+			}
+		}
+		return append(append(bson.D{}, d...), bson.E{Key: field, Value: id}), nil
+	case bson.M:
+		return tenantMap(d, field, id)
+	case map[string]any:
+		return tenantMap(d, field, id)
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	value := bson.Raw(raw).Lookup(field)
+	if v, ok := value.StringValueOK(); !ok || v != id {
+		return nil, fmt.Errorf("%w: %s=%v", ErrTenantMismatch, field, value)
+	}
+	return doc, nil
+}
+
+func tenantMap[M ~map[string]any](m M, field, id string) (M, error) {
+	if v, ok := m[field]; ok {
+		if v != id {
+			return nil, fmt.Errorf("%w: %s=%v", ErrTenantMismatch, field, v)
+		}
+		return m, nil
+	}
+	out := make(M, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	out[field] = id
+	return out, nil
+}
+
+// ObserveInterceptor 在每次操作结束后回调 fn，可用于 helper 级别的日志与指标。
+func ObserveInterceptor(fn func(ctx context.Context, op *Operation, elapsed time.Duration, err error)) Interceptor {
+	return InterceptorFuncs{AfterFunc: func(ctx context.Context, op *Operation, err error) {
+		fn(ctx, op, time.Since(op.Start), err)
+	}}
+}
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestTenantInterceptor(t *testing.T) {
+	in := TenantInterceptor("tenant_id", func(ctx context.Context) (string, bool) { return "t1", true })
+	scope := bson.E{Key: "tenant_id", Value: "t1"}
+
+	type owned struct {
+		Tenant string `bson:"tenant_id"`
+	}
+
+	tests := []struct {
+		name string
+		op   Operation
+		want Operation
+		err  error
+	}{
+		{
+			name: "nil filter",
+			op:   Operation{Name: "find"},
+			want: Operation{Name: "find", Filter: bson.D{scope}},
+		},
+		{
+			name: "bson.D filter",
+			op:   Operation{Name: "delete", Filter: bson.D{{Key: "_id", Value: "a"}}},
+			want: Operation{Name: "delete", Filter: bson.D{{Key: "_id", Value: "a"}, scope}},
+		},
+		{
+			name: "per-patch filters",
+			op:   Operation{Name: "patch_many", Filter: []bson.D{{{Key: "_id", Value: "a"}}, {{Key: "_id", Value: "b"}}}},
+			want: Operation{Name: "patch_many", Filter: []bson.D{{{Key: "_id", Value: "a"}, scope}, {{Key: "_id", Value: "b"}, scope}}},
+		},
+		{
+			name: "unsupported filter",
+			op:   Operation{Name: "find", Filter: bson.M{"_id": "a"}},
+			err:  ErrTenantFilter,
+		},
+		{
+			name: "documents are stamped",
+			op:   Operation{Name: "insert", Documents: []any{bson.D{{Key: "a", Value: 1}}, bson.M{"a": 2}, owned{Tenant: "t1"}}},
+			want: Operation{Name: "insert", Documents: []any{bson.D{{Key: "a", Value: 1}, scope}, bson.M{"a": 2, "tenant_id": "t1"}, owned{Tenant: "t1"}}},
+		},
+		{
+			name: "document of another tenant",
+			op:   Operation{Name: "insert", Documents: []any{bson.D{{Key: "tenant_id", Value: "t2"}}}},
+			err:  ErrTenantMismatch,
+		},
+		{
+			name: "struct without tenant",
+			op:   Operation{Name: "insert", Documents: []any{owned{}}},
+			err:  ErrTenantMismatch,
+		},
+		{
+			name: "drop collection",
+			op:   Operation{Name: "drop_collection"},
+			err:  ErrTenantMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.op
+			_, err := in.Before(context.Background(), &op)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && !reflect.DeepEqual(op, tt.want) {
+				t.Errorf("op = %+v, want %+v", op, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantInterceptorWithoutTenant(t *testing.T) {
+	in := TenantInterceptor("tenant_id", func(ctx context.Context) (string, bool) { return "", false })
+	op := Operation{Name: "drop_collection"}
+	if _, err := in.Before(context.Background(), &op); err != nil {
+		t.Fatal(err)
+	}
+	if op.Filter != nil {
+		t.Errorf("filter = %v, want nil", op.Filter)
+	}
+}
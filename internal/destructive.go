@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DestructiveLogger 表示破坏性操作（批量删除、删集合等）的审计日志。
+type DestructiveLogger struct {
+	Operation  string `json:"operation"`
+	Database   string `json:"database"`
+	Collection string `json:"collection"`
+	Filter     string `json:"filter"`
+	Path       string `json:"path"`
+
+	TraceId  string `json:"trace_id"`
+	ParentId string `json:"parent_id"`
+}
+
+// EmitDestructiveLog 在破坏性操作执行前上报一条 WARN 级别的审计日志。
+func EmitDestructiveLog(ctx context.Context, logData *DestructiveLogger) {
+	if logData == nil {
+		return
+	}
+	logData.Path = fileWithLineNum()
+
+	spanCtx := trace.SpanFromContext(ctx).SpanContext()
+	if spanCtx.IsValid() {
+		logData.TraceId = spanCtx.TraceID().String()
+		logData.ParentId = spanCtx.SpanID().String()
+	}
+
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(log.SeverityWarn)
+	record.SetSeverityText("WARN")
+
+	if b, err := json.Marshal(logData); err == nil {
+		record.SetBody(log.StringValue(string(b)))
+	} else {
+		record.SetBody(log.StringValue(logData.Operation))
+	}
+
+	record.AddAttributes(
+		log.String("log_type", "destructive"),
+		log.String("operation", logData.Operation),
+		log.String("database", logData.Database),
+		log.String("collection", logData.Collection),
+		log.String("filter", logData.Filter),
+		log.String("path", logData.Path),
+	)
+
+	global.Logger("go-mongo").Emit(ctx, record)
+}
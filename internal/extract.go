@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fireflycore/go-micro/constant"
+	"google.golang.org/grpc/metadata"
+)
+
+// 提取器返回的字段名，写入 OperationLogger 对应的独立字段，其余字段写入 Fields。
+const (
+	FieldTraceId     = "trace_id"
+	FieldParentId    = "parent_id"
+	FieldUserId      = "user_id"
+	FieldAppId       = "app_id"
+	FieldTenantId    = "tenant_id"
+	FieldInvokeAppId = "invoke_app_id"
+	FieldTargetAppId = "target_app_id"
+)
+
+// Extractor 从 ctx 中提取写入操作日志的关联字段。
+type Extractor func(ctx context.Context) map[string]string
+
+// MetadataKeys 为提取链路字段使用的 metadata key（小写）。
+type MetadataKeys struct {
+	// TraceId 为 trace id 的 key，值可以是 trace id 本身或 W3C traceparent（如 "traceparent"）。
+	TraceId     string
+	UserId      string
+	AppId       string
+	TenantId    string
+	InvokeAppId string
+	TargetAppId string
+}
+
+// withDefaults 为未设置的 key 填充 Firefly 默认头部。
+func (k MetadataKeys) withDefaults() MetadataKeys {
+	for _, f := range []struct {
+		key *string
+		def string
+	}{
+		{&k.TraceId, constant.TraceId},
+		{&k.UserId, constant.UserId},
+		{&k.AppId, constant.AppId},
+		{&k.TenantId, constant.TenantId},
+		{&k.InvokeAppId, constant.InvokeServiceAppId},
+		{&k.TargetAppId, constant.TargetServiceAppId},
+	} {
+		if *f.key == "" {
+			*f.key = f.def
+		} else {
+			*f.key = strings.ToLower(*f.key)
+		}
+	}
+	return k
+}
+
+// MetadataExtractor 返回按 keys 读取链路字段的提取器：依次从 incoming metadata、outgoing metadata
+// 与以 key 为键的 context 值中读取，trace id 为 W3C traceparent 时拆出 parent id。
+func MetadataExtractor(keys MetadataKeys) Extractor {
+	keys = keys.withDefaults()
+	return func(ctx context.Context) map[string]string {
+		md := newMetadataFields(ctx)
+		fields := make(map[string]string, 7)
+		put := func(name, value string) {
+			if value != "" {
+				fields[name] = value
+			}
+		}
+
+		traceId, parentId := parseTraceId(md.get(keys.TraceId))
+		put(FieldTraceId, traceId)
+		put(FieldParentId, parentId)
+		put(FieldUserId, md.get(keys.UserId))
+		put(FieldAppId, md.get(keys.AppId))
+		put(FieldTenantId, md.get(keys.TenantId))
+		put(FieldInvokeAppId, md.get(keys.InvokeAppId))
+		put(FieldTargetAppId, md.get(keys.TargetAppId))
+		return fields
+	}
+}
+
+// setFields 将提取器返回的字段写入日志，空值忽略。
+func (o *OperationLogger) setFields(fields map[string]string) {
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		switch name {
+		case FieldTraceId:
+			o.TraceId = value
+		case FieldParentId:
+			o.ParentId = value
+		case FieldUserId:
+			o.UserId = value
+		case FieldAppId:
+			o.AppId = value
+		case FieldTenantId:
+			o.TenantId = value
+		case FieldInvokeAppId:
+			o.InvokeAppId = value
+		case FieldTargetAppId:
+			o.TargetAppId = value
+		default:
+			if o.Fields == nil {
+				o.Fields = make(map[string]string)
+			}
+			o.Fields[name] = value
+		}
+	}
+}
+
+// parseTraceId 解析 trace id 字段：W3C traceparent（version-traceid-parentid-flags）拆出 trace id 与 parent id，
+// 其余值原样作为 trace id。
+func parseTraceId(v string) (traceId, parentId string) {
+	parts := strings.Split(v, "-")
+	if len(parts) == 4 && len(parts[0]) == 2 && len(parts[1]) == 32 && len(parts[2]) == 16 && len(parts[3]) == 2 {
+		return parts[1], parts[2]
+	}
+	return v, ""
+}
+
+// metadataFields 按 incoming metadata、outgoing metadata、context 值的顺序读取链路字段。
+type metadataFields struct {
+	ctx      context.Context
+	incoming metadata.MD
+	outgoing metadata.MD
+}
+
+func newMetadataFields(ctx context.Context) metadataFields {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	return metadataFields{ctx: ctx, incoming: incoming, outgoing: outgoing}
+}
+
+func (f metadataFields) get(key string) string {
+	if gd := f.incoming.Get(key); len(gd) != 0 && gd[0] != "" {
+		return gd[0]
+	}
+	if gd := f.outgoing.Get(key); len(gd) != 0 && gd[0] != "" {
+		return gd[0]
+	}
+	if v, ok := f.ctx.Value(key).(string); ok {
+		return v
+	}
+	return ""
+}
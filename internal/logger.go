@@ -4,17 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"os"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/fireflycore/go-micro/constant"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/trace"
-	"google.golang.org/grpc/metadata"
 )
 
 // ANSI Color Constants
@@ -26,6 +28,19 @@ const (
 	ColorGreen    = "\033[32m"
 )
 
+// ColorSupported 判断控制台输出是否适合使用 ANSI 颜色：设置了非空的 NO_COLOR（https://no-color.org）或标准输出不是终端
+// （重定向到文件、CI、容器日志采集）时返回 false。
+func ColorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // Log Constants
 const (
 	// LogTypeMongo 为日志类型标识（供下游聚合检索）。
@@ -45,10 +60,13 @@ const (
 
 // OperationLogger 表示操作日志。
 type OperationLogger struct {
-	Database  string `json:"database"`
-	Statement string `json:"statement"`
-	Result    string `json:"result"`
-	Path      string `json:"path"`
+	Database   string `json:"database"`
+	Command    string `json:"command"`
+	Collection string `json:"collection"`
+	Statement  string `json:"statement"`
+	Result     string `json:"result"`
+	Path       string `json:"path"`
+	Reply      *Reply `json:"reply,omitempty"`
 
 	Duration uint64 `json:"duration"`
 
@@ -64,24 +82,129 @@ type OperationLogger struct {
 	UserId   string `json:"user_id"`
 	AppId    string `json:"app_id"`
 	TenantId string `json:"tenant_id"`
+
+	// Fields 为提取器返回的其他关联字段（如 session_id、region）。
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 // Conf 为 logger 的配置。
 type Conf struct {
 	// Console 控制是否输出到控制台。
 	Console bool
+	// Slog 非空时命令日志写入该 logger，替代控制台输出。
+	Slog *slog.Logger
 	// SlowThreshold 为慢查询阈值。
 	SlowThreshold time.Duration
+	// SampleRate 为成功且非慢查询命令的日志采样比例，取值 (0,1) 时生效，其余值全部记录。
+	SampleRate float64
 	// Colorful 控制是否启用彩色输出。
 	Colorful bool
 	// Database 为库名字段，用于检索与聚合。
 	Database string
+	// Extractors 为日志关联字段提取器，按顺序执行，后者覆盖前者；为空时使用 MetadataExtractor(MetadataKeys{})。
+	Extractors []Extractor
+	// Handlers 为额外接收结构化操作日志的 handler，按注册顺序调用。
+	Handlers []Handler
+}
+
+// Handler 接收结构化操作日志；返回的错误与 panic 被隔离并写入本地输出，不影响其他 handler 与 OTel 上报。
+type Handler func(ctx context.Context, log *OperationLogger) error
+
+// Command 为一次命令的结构化信息。
+type Command struct {
+	// Name 为命令名（find、insert、update 等）。
+	Name string
+	// Database 为命令执行的数据库，为空时使用 Conf.Database。
+	Database string
+	// Collection 为命令的目标集合，无集合的命令为空。
+	Collection string
+	// Statement 为命令文本。
+	Statement string
+	// Reply 为成功命令的回复摘要，失败或回复中没有可摘要的字段时为 nil。
+	Reply *Reply
+}
+
+// Reply 为命令回复摘要，只有命令回复中存在的字段才会设置。
+type Reply struct {
+	// Inserted 为 insert 写入的文档数。
+	Inserted *int64 `json:"inserted,omitempty"`
+	// Matched 为 update/findAndModify 匹配的文档数。
+	Matched *int64 `json:"matched,omitempty"`
+	// Modified 为 update 实际修改的文档数。
+	Modified *int64 `json:"modified,omitempty"`
+	// Upserted 为 update 插入的文档数。
+	Upserted *int64 `json:"upserted,omitempty"`
+	// Deleted 为 delete 删除的文档数。
+	Deleted *int64 `json:"deleted,omitempty"`
+	// BatchSize 为 find/aggregate/getMore 本批返回的文档数。
+	BatchSize *int64 `json:"batch_size,omitempty"`
+	// CursorId 为游标 ID，0 表示结果已全部返回。
+	CursorId *int64 `json:"cursor_id,omitempty"`
+}
+
+// Clone 返回摘要的深拷贝。
+func (r *Reply) Clone() *Reply {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	for _, p := range []**int64{&c.Inserted, &c.Matched, &c.Modified, &c.Upserted, &c.Deleted, &c.BatchSize, &c.CursorId} {
+		if *p != nil {
+			v := **p
+			*p = &v
+		}
+	}
+	return &c
+}
+
+// attrs 返回摘要中已设置字段的 slog 属性。
+func (r *Reply) attrs() []any {
+	var attrs []any
+	for _, f := range r.fields() {
+		attrs = append(attrs, slog.Int64(f.name, f.value))
+	}
+	return attrs
+}
+
+// String 返回 "matched=1 modified=1" 形式的摘要文本。
+func (r *Reply) String() string {
+	parts := make([]string, 0, 7)
+	for _, f := range r.fields() {
+		parts = append(parts, f.name+"="+strconv.FormatInt(f.value, 10))
+	}
+	return strings.Join(parts, " ")
+}
+
+type replyField struct {
+	name  string
+	value int64
+}
+
+func (r *Reply) fields() []replyField {
+	var fields []replyField
+	for _, f := range []struct {
+		name  string
+		value *int64
+	}{
+		{"inserted", r.Inserted},
+		{"matched", r.Matched},
+		{"modified", r.Modified},
+		{"upserted", r.Upserted},
+		{"deleted", r.Deleted},
+		{"batch_size", r.BatchSize},
+		{"cursor_id", r.CursorId},
+	} {
+		if f.value != nil {
+			fields = append(fields, replyField{f.name, *f.value})
+		}
+	}
+	return fields
 }
 
 // Interface 约束 logger 需要提供的能力。
 type Interface interface {
 	// Trace 记录一次命令的执行信息。
-	Trace(ctx context.Context, id int64, elapsed time.Duration, smt string, err string)
+	Trace(ctx context.Context, id int64, elapsed time.Duration, cmd Command, err string)
 }
 
 type logger struct {
@@ -113,15 +236,29 @@ func NewLogger(conf *Conf) Interface {
 		traceErrStr = colorPrefix + ColorRedBold + "%s\n" + ColorReset + "%s"
 	}
 
+	c := *conf
+	if len(c.Extractors) == 0 {
+		c.Extractors = []Extractor{MetadataExtractor(MetadataKeys{})}
+	}
+
 	return &logger{
-		Conf:         *conf,
+		Conf:         c,
 		traceStr:     traceStr,
 		traceWarnStr: traceWarnStr,
 		traceErrStr:  traceErrStr,
 	}
 }
 
-func (l *logger) Trace(ctx context.Context, id int64, elapsed time.Duration, smt string, err string) {
+func (l *logger) Trace(ctx context.Context, id int64, elapsed time.Duration, cmd Command, err string) {
+	// 错误与慢查询始终记录，仅对普通成功命令按比例采样。
+	if l.dropped(elapsed, err) {
+		return
+	}
+
+	if cmd.Database == "" {
+		cmd.Database = l.Database
+	}
+	smt := cmd.Statement
 
 	date := time.Now().Format(time.DateTime)
 	file := fileWithLineNum()
@@ -129,65 +266,113 @@ func (l *logger) Trace(ctx context.Context, id int64, elapsed time.Duration, smt
 
 	// 按错误/慢查询/普通信息分支记录日志。
 	// 注意：此处不再使用 LogLevel 进行过滤，而是根据执行结果自动标记级别（全链路收集）。
+	// 配置了 slog 时写入 slog，替代控制台输出。
+	out := Output{Console: l.Console, Slog: l.Slog}
+	attrs := []slog.Attr{
+		slog.String("database", cmd.Database),
+		slog.String("command", cmd.Name),
+		slog.String("collection", cmd.Collection),
+		slog.Int64("request_id", id),
+		slog.Float64("duration_ms", timer),
+		slog.String("path", file),
+		slog.String("statement", smt),
+	}
+	// 成功命令附带回复摘要（匹配/修改/删除数、批大小、游标 ID）。
+	if cmd.Reply != nil {
+		attrs = append(attrs, slog.Group("reply", cmd.Reply.attrs()...))
+		smt += "\n[Reply: " + cmd.Reply.String() + "]"
+	}
+
 	switch {
 	case len(err) > 0: // 错误分支：err 非空。
-		if l.Console {
-			fmt.Printf(l.traceErrStr+"\n", date, "error", l.Database, id, timer, file, err, smt)
+		if !out.emit(ctx, Error, "mongo command failed", append(attrs, slog.String("error", err))...) && l.Console {
+			fmt.Printf(l.traceErrStr+"\n", date, "error", cmd.Database, id, timer, file, err, smt)
 		}
-		l.handleLog(ctx, Error, file, smt, err, elapsed)
+		l.handleLog(ctx, Error, file, cmd, err, elapsed)
 
 	case elapsed > l.SlowThreshold && l.SlowThreshold != 0: // 慢查询分支：耗时超过阈值。
 		slowLog := fmt.Sprintf("SLOW SQL >= %v", l.SlowThreshold)
-		if l.Console {
-			fmt.Printf(l.traceWarnStr+"\n", date, "warn", l.Database, id, timer, file, slowLog, smt)
+		if !out.emit(ctx, Warn, "mongo slow command", append(attrs, slog.Duration("slow_threshold", l.SlowThreshold))...) && l.Console {
+			fmt.Printf(l.traceWarnStr+"\n", date, "warn", cmd.Database, id, timer, file, slowLog, smt)
 		}
-		l.handleLog(ctx, Warn, file, smt, slowLog, elapsed)
+		l.handleLog(ctx, Warn, file, cmd, slowLog, elapsed)
 
 	default: // 普通信息分支。
-		if l.Console {
-			fmt.Printf(l.traceStr+"\n", date, "info", l.Database, id, timer, file, smt)
+		if !out.emit(ctx, Info, "mongo command", attrs...) && l.Console {
+			fmt.Printf(l.traceStr+"\n", date, "info", cmd.Database, id, timer, file, smt)
 		}
-		l.handleLog(ctx, Info, file, smt, ResultSuccess, elapsed)
+		l.handleLog(ctx, Info, file, cmd, ResultSuccess, elapsed)
 	}
 }
 
-func (l *logger) handleLog(ctx context.Context, level LogLevel, path, smt, result string, elapsed time.Duration) {
+// dropped 判断普通成功命令是否被采样丢弃。
+func (l *logger) dropped(elapsed time.Duration, err string) bool {
+	if l.SampleRate <= 0 || l.SampleRate >= 1 || len(err) > 0 {
+		return false
+	}
+	if l.SlowThreshold != 0 && elapsed > l.SlowThreshold {
+		return false
+	}
+	return rand.Float64() >= l.SampleRate
+}
+
+func (l *logger) handleLog(ctx context.Context, level LogLevel, path string, cmd Command, result string, elapsed time.Duration) {
 	logData := &OperationLogger{
-		Database:  l.Database,                     // Database 为库名。
-		Statement: smt,                            // Statement 为命令文本。
-		Result:    result,                         // Result 为 success/slow/error 等结果标记。
-		Duration:  uint64(elapsed.Microseconds()), // Duration 为耗时（微秒），便于统计分析。
-		Level:     uint32(level),                  // Level 为日志级别枚举值。
-		Path:      path,                           // Path 为调用位置。
-		Type:      LogTypeMongo,                   // Type 为日志类型标记。
+		Database:   cmd.Database,                   // Database 为命令执行的库名。
+		Command:    cmd.Name,                       // Command 为命令名，便于按操作类型聚合。
+		Collection: cmd.Collection,                 // Collection 为目标集合，无集合的命令为空。
+		Statement:  cmd.Statement,                  // Statement 为命令文本。
+		Reply:      cmd.Reply,                      // Reply 为成功命令的回复摘要。
+		Result:     result,                         // Result 为 success/slow/error 等结果标记。
+		Duration:   uint64(elapsed.Microseconds()), // Duration 为耗时（微秒），便于统计分析。
+		Level:      uint32(level),                  // Level 为日志级别枚举值。
+		Path:       path,                           // Path 为调用位置。
+		Type:       LogTypeMongo,                   // Type 为日志类型标记。
+	}
+
+	// 由提取器补充关联字段：未配置时从 gRPC metadata 中读取 Firefly 头部（存在则写入结构化日志，作为兼容兜底）。
+	for _, extract := range l.Extractors {
+		logData.setFields(extract(ctx))
 	}
 
-	// 从 OTel span context 中提取链路字段（优先）
+	// 从 OTel span context 中提取链路字段（优先于提取器返回的 trace_id/parent_id）
 	spanCtx := trace.SpanFromContext(ctx).SpanContext()
 	if spanCtx.IsValid() {
 		logData.TraceId = spanCtx.TraceID().String()
 		logData.ParentId = spanCtx.SpanID().String()
 	}
 
-	// 从 gRPC metadata 中提取链路字段（存在则写入结构化日志，作为兼容兜底）
-	md, _ := metadata.FromIncomingContext(ctx)
-	if gd := md.Get(constant.UserId); len(gd) != 0 {
-		logData.UserId = gd[0]
-	}
-	if gd := md.Get(constant.AppId); len(gd) != 0 {
-		logData.AppId = gd[0]
-	}
-	if gd := md.Get(constant.InvokeServiceAppId); len(gd) != 0 {
-		logData.InvokeAppId = gd[0]
-	}
-	if gd := md.Get(constant.TargetServiceAppId); len(gd) != 0 {
-		logData.TargetAppId = gd[0]
-	}
-	if gd := md.Get(constant.TenantId); len(gd) != 0 {
-		logData.TenantId = gd[0]
+	l.emitOTelOperationLog(ctx, level, logData)
+	l.dispatch(ctx, logData)
+}
+
+// dispatch 依次调用 handler，每个 handler 收到独立的副本，单个 handler 的错误或 panic 不影响其余 handler。
+func (l *logger) dispatch(ctx context.Context, logData *OperationLogger) {
+	for i, handle := range l.Handlers {
+		if err := safeHandle(ctx, handle, logData.Clone()); err != nil {
+			out := Output{Console: l.Console, Slog: l.Slog}
+			if !out.emit(ctx, Warn, "mongo log handler failed", slog.Int("handler", i), slog.String("error", err.Error())) && l.Console {
+				fmt.Printf("[%s] [warn] [Database:%s] mongo log handler %d failed: %v\n", time.Now().Format(time.DateTime), logData.Database, i, err)
+			}
+		}
 	}
+}
 
-	l.emitOTelOperationLog(ctx, level, logData)
+// Clone 返回日志的深拷贝（包括 Fields 与 Reply），修改副本不影响其他 handler。
+func (l *OperationLogger) Clone() *OperationLogger {
+	c := *l
+	c.Reply = l.Reply.Clone()
+	c.Fields = maps.Clone(l.Fields)
+	return &c
+}
+
+func safeHandle(ctx context.Context, handle Handler, entry *OperationLogger) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handle(ctx, entry)
 }
 
 func (l *logger) emitOTelOperationLog(ctx context.Context, level LogLevel, logData *OperationLogger) {
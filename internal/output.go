@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Output 为日志的本地输出目标，OpenTelemetry Logs 上报不受影响。
+type Output struct {
+	// Console 为 true 时以文本格式输出到标准输出。
+	Console bool
+	// Slog 非空时写入该 logger，替代控制台输出。
+	Slog *slog.Logger
+}
+
+// emit 写入 slog，未配置 slog 时返回 false，由调用方决定是否输出到控制台。
+func (o Output) emit(ctx context.Context, level LogLevel, msg string, attrs ...slog.Attr) bool {
+	if o.Slog == nil {
+		return false
+	}
+	o.Slog.LogAttrs(ctx, convertSlogLevel(level), msg, attrs...)
+	return true
+}
+
+func convertSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case Error:
+		return slog.LevelError
+	case Warn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
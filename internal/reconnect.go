@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// ReconnectLogger 表示连接监护（健康检查与重建连接）的事件日志。
+type ReconnectLogger struct {
+	Database string `json:"database"`
+	Event    string `json:"event"`
+	Attempt  int    `json:"attempt"`
+	Error    string `json:"error"`
+}
+
+// EmitReconnectLog 上报一条连接监护日志：包含错误时为 WARN，否则为 INFO。
+func EmitReconnectLog(ctx context.Context, out Output, logData *ReconnectLogger) {
+	if logData == nil {
+		return
+	}
+
+	level := Info
+	if logData.Error != "" {
+		level = Warn
+	}
+
+	if !out.emit(ctx, level, "mongo supervise",
+		slog.String("database", logData.Database),
+		slog.String("event", logData.Event),
+		slog.Int("attempt", logData.Attempt),
+		slog.String("error", logData.Error),
+	) && out.Console {
+		fmt.Printf("[%s] [%s] [Database:%s] %s attempt=%d %s\n",
+			time.Now().Format(time.DateTime), strings.ToLower(convertOTelSeverityText(level)), logData.Database,
+			logData.Event, logData.Attempt, logData.Error)
+	}
+
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(convertOTelSeverity(level))
+	record.SetSeverityText(convertOTelSeverityText(level))
+
+	if b, err := json.Marshal(logData); err == nil {
+		record.SetBody(log.StringValue(string(b)))
+	} else {
+		record.SetBody(log.StringValue(logData.Event))
+	}
+
+	record.AddAttributes(
+		log.String("log_type", "reconnect"),
+		log.String("database", logData.Database),
+		log.String("event", logData.Event),
+		log.Int("attempt", logData.Attempt),
+		log.String("error", logData.Error),
+	)
+
+	global.Logger("go-mongo").Emit(ctx, record)
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotateTimeFormat 为滚动文件名中的时间格式，按字典序即为时间顺序。
+const rotateTimeFormat = "20060102T150405.000"
+
+// RotateFile 为按大小滚动的日志文件：写入前超过 MaxSize 时将当前文件重命名为 {name}-{time}{ext}，
+// 滚动后在后台压缩旧文件并删除超过 MaxAge 的文件。
+type RotateFile struct {
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	compress bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// millMu 保证同一时刻只有一个后台压缩与清理任务。
+	millMu sync.Mutex
+}
+
+// OpenRotateFile 以追加方式打开日志文件（目录不存在时创建）。maxSize<=0 时不滚动，maxAge<=0 时不删除旧文件。
+func OpenRotateFile(path string, maxSize int64, maxAge time.Duration, compress bool) (*RotateFile, error) {
+	f := &RotateFile{path: path, maxSize: maxSize, maxAge: maxAge, compress: compress}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotateFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write 实现 io.Writer，单次写入不会跨越两个文件。
+func (f *RotateFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前文件。
+func (f *RotateFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// rotate 将当前文件重命名为备份文件并重新打开原路径；重命名失败时继续写入原文件，不会使后续写入失效。
+func (f *RotateFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	backup := strings.TrimSuffix(f.path, ext) + "-" + time.Now().Format(rotateTimeFormat) + ext
+	renameErr := os.Rename(f.path, backup)
+	if err := f.open(); err != nil {
+		return errors.Join(renameErr, err)
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	go f.mill(backup)
+	return nil
+}
+
+// mill 压缩刚滚动出的文件并删除过期文件，失败时保留原文件。
+func (f *RotateFile) mill(backup string) {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	if f.compress {
+		if err := gzipFile(backup); err == nil {
+			_ = os.Remove(backup)
+		}
+	}
+
+	if f.maxAge <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-f.maxAge)
+	for _, entry := range entries {
+		// 只处理本文件滚动出的备份，目录中的其他日志（如 mongo-audit.log）不受影响。
+		stamp, ok := f.backupTime(entry.Name())
+		if ok && stamp.Before(cutoff) {
+			_ = os.Remove(filepath.Join(filepath.Dir(f.path), entry.Name()))
+		}
+	}
+}
+
+// backupTime 解析备份文件名 {name}-{time}{ext}[.gz] 中的滚动时间，不是本文件的备份时返回 false。
+func (f *RotateFile) backupTime(name string) (time.Time, bool) {
+	base := filepath.Base(f.path)
+	ext := filepath.Ext(base)
+	stamp, ok := strings.CutPrefix(name, strings.TrimSuffix(base, ext)+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp = strings.TrimSuffix(stamp, ".gz")
+	if stamp, ok = strings.CutSuffix(stamp, ext); !ok {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(rotateTimeFormat, stamp, time.Local)
+	return t, err == nil
+}
+
+func gzipFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(name + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// StartupLogger 表示连接建立后的启动摘要日志。
+type StartupLogger struct {
+	Database       string `json:"database"`
+	Address        string `json:"address"`
+	AppName        string `json:"app_name"`
+	ServerVersion  string `json:"server_version"`
+	Topology       string `json:"topology"`
+	ReplicaSet     string `json:"replica_set"`
+	Transactions   bool   `json:"transactions"`
+	ChangeStreams  bool   `json:"change_streams"`
+	TLS            bool   `json:"tls"`
+	MaxPoolSize    uint64 `json:"max_pool_size"`
+	MaxConnIdleSec int64  `json:"max_conn_idle_sec"`
+
+	Warnings []string `json:"warnings"`
+}
+
+// EmitStartupLog 上报启动摘要：存在兼容性告警时为 WARN，否则为 INFO。
+func EmitStartupLog(ctx context.Context, out Output, logData *StartupLogger) {
+	if logData == nil {
+		return
+	}
+
+	level := Info
+	if len(logData.Warnings) != 0 {
+		level = Warn
+	}
+
+	if !out.emit(ctx, level, "mongo connected",
+		slog.String("database", logData.Database),
+		slog.String("address", logData.Address),
+		slog.String("app_name", logData.AppName),
+		slog.String("server_version", logData.ServerVersion),
+		slog.String("topology", logData.Topology),
+		slog.String("replica_set", logData.ReplicaSet),
+		slog.Bool("transactions", logData.Transactions),
+		slog.Bool("change_streams", logData.ChangeStreams),
+		slog.Bool("tls", logData.TLS),
+		slog.Uint64("max_pool_size", logData.MaxPoolSize),
+		slog.Any("warnings", logData.Warnings),
+	) && out.Console {
+		fmt.Printf("[%s] [%s] [Database:%s] connected to %s (version=%s topology=%s transactions=%t change_streams=%t tls=%t max_pool_size=%d)\n",
+			time.Now().Format(time.DateTime), strings.ToLower(convertOTelSeverityText(level)), logData.Database, logData.Address,
+			logData.ServerVersion, logData.Topology, logData.Transactions, logData.ChangeStreams, logData.TLS, logData.MaxPoolSize)
+		for _, w := range logData.Warnings {
+			fmt.Printf("  warning: %s\n", w)
+		}
+	}
+
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(convertOTelSeverity(level))
+	record.SetSeverityText(convertOTelSeverityText(level))
+
+	if b, err := json.Marshal(logData); err == nil {
+		record.SetBody(log.StringValue(string(b)))
+	} else {
+		record.SetBody(log.StringValue(logData.ServerVersion))
+	}
+
+	record.AddAttributes(
+		log.String("log_type", "startup"),
+		log.String("database", logData.Database),
+		log.String("server_version", logData.ServerVersion),
+		log.String("topology", logData.Topology),
+		log.Bool("transactions", logData.Transactions),
+		log.Bool("change_streams", logData.ChangeStreams),
+		log.Int64("max_pool_size", int64(logData.MaxPoolSize)),
+		log.Int("warnings", len(logData.Warnings)),
+	)
+
+	global.Logger("go-mongo").Emit(ctx, record)
+}
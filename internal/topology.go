@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// TopologyLogger 表示节点状态或主节点变化的日志。
+type TopologyLogger struct {
+	Database string `json:"database"`
+	// Event 为 server_changed（节点类型变化）或 primary_changed（主节点切换）。
+	Event    string `json:"event"`
+	Address  string `json:"address"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+	Error    string `json:"error"`
+}
+
+// EmitTopologyLog 上报一条拓扑变化日志：节点不可用或失去主节点时为 WARN，否则为 INFO。
+func EmitTopologyLog(ctx context.Context, out Output, logData *TopologyLogger) {
+	if logData == nil {
+		return
+	}
+
+	level := Info
+	if logData.Current == "" || logData.Current == "Unknown" {
+		level = Warn
+	}
+
+	if !out.emit(ctx, level, "mongo topology changed",
+		slog.String("database", logData.Database),
+		slog.String("event", logData.Event),
+		slog.String("address", logData.Address),
+		slog.String("previous", logData.Previous),
+		slog.String("current", logData.Current),
+	) && out.Console {
+		fmt.Printf("[%s] [%s] [Database:%s] %s %s: %s -> %s\n",
+			time.Now().Format(time.DateTime), strings.ToLower(convertOTelSeverityText(level)), logData.Database,
+			logData.Event, logData.Address, logData.Previous, logData.Current)
+	}
+
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(convertOTelSeverity(level))
+	record.SetSeverityText(convertOTelSeverityText(level))
+
+	if b, err := json.Marshal(logData); err == nil {
+		record.SetBody(log.StringValue(string(b)))
+	} else {
+		record.SetBody(log.StringValue(logData.Event))
+	}
+
+	record.AddAttributes(
+		log.String("log_type", "topology"),
+		log.String("database", logData.Database),
+		log.String("event", logData.Event),
+		log.String("address", logData.Address),
+		log.String("previous", logData.Previous),
+		log.String("current", logData.Current),
+	)
+
+	global.Logger("go-mongo").Emit(ctx, record)
+}
@@ -0,0 +1,176 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrServerSideJS 表示过滤条件、管道或命令中包含服务端 JavaScript（$where、$function、$accumulator、mapReduce）。
+var ErrServerSideJS = errors.New("mongo: server-side javascript is not allowed")
+
+// jsAllowed 为 true 时关闭服务端 JavaScript 拦截，默认拦截。
+var jsAllowed atomic.Bool
+
+// jsOperators 为会在服务端执行 JavaScript 的操作符，出现在任意层级的键上都会被拒绝；
+// 普通字段名（如名为 mapReduce 的字段）不受影响。
+var jsOperators = map[string]bool{
+	"$where":       true,
+	"$function":    true,
+	"$accumulator": true,
+}
+
+// AllowServerSideJS 显式开启（true）或重新关闭（false）服务端 JavaScript，默认关闭。
+func AllowServerSideJS(allow bool) {
+	jsAllowed.Store(allow)
+}
+
+// CheckFilter 递归检查过滤条件、更新文档或聚合管道，包含服务端 JavaScript 时返回包装了 ErrServerSideJS 的错误。
+// bson.D/bson.M/bson.A/mongo.Pipeline/bson.Raw 等直接遍历，其他类型（如结构体）编码后检查。
+// 通过 AllowServerSideJS(true) 开启后不做检查。
+func CheckFilter(filter any) error {
+	if filter == nil || jsAllowed.Load() {
+		return nil
+	}
+	return checkJS(filter)
+}
+
+// CheckCommand 检查 RunCommand 的命令文档：命令名（首个键）为 mapReduce 时拒绝，其余部分按 CheckFilter 检查。
+func CheckCommand(cmd bson.D) error {
+	if len(cmd) == 0 || jsAllowed.Load() {
+		return nil
+	}
+	if strings.EqualFold(cmd[0].Key, "mapReduce") {
+		return fmt.Errorf("%w: %s", ErrServerSideJS, cmd[0].Key)
+	}
+	return checkJS(cmd)
+}
+
+// checkJS 遍历常见的文档与数组类型，遇到 JavaScript 操作符或 JavaScript 值时返回错误。
+func checkJS(value any) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bson.D:
+		for _, e := range v {
+			if err := checkJSKey(e.Key, e.Value); err != nil {
+				return err
+			}
+		}
+	case bson.E:
+		return checkJSKey(v.Key, v.Value)
+	case bson.M:
+		for k, elem := range v {
+			if err := checkJSKey(k, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		return checkJS(bson.M(v))
+	case bson.A:
+		return checkJSList(v)
+	case []any:
+		return checkJSList(v)
+	case []bson.D:
+		for _, doc := range v {
+			if err := checkJS(doc); err != nil {
+				return err
+			}
+		}
+	case mongo.Pipeline:
+		return checkJS([]bson.D(v))
+	case []bson.M:
+		for _, doc := range v {
+			if err := checkJS(doc); err != nil {
+				return err
+			}
+		}
+	case bson.Raw:
+		return checkJSValue(bson.RawValue{Type: bson.TypeEmbeddedDocument, Value: v})
+	case bson.RawValue:
+		return checkJSValue(v)
+	case bson.JavaScript, bson.CodeWithScope:
+		return fmt.Errorf("%w: javascript value", ErrServerSideJS)
+	default:
+		return checkJSOther(value)
+	}
+	return nil
+}
+
+func checkJSKey(key string, value any) error {
+	if jsOperators[key] {
+		return fmt.Errorf("%w: %s", ErrServerSideJS, key)
+	}
+	return checkJS(value)
+}
+
+func checkJSList(values []any) error {
+	for _, v := range values {
+		if err := checkJS(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkJSOther 处理上面未列出的类型：标量直接放行，结构体、map 与切片编码后检查。
+func checkJSOther(value any) error {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return nil
+	}
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil
+	}
+
+	// 包一层文档以便同时支持结构体、map 与数组类型。
+	raw, err := bson.Marshal(bson.D{{Key: "v", Value: value}})
+	if err != nil {
+		return err
+	}
+	return checkJSValue(bson.Raw(raw).Lookup("v"))
+}
+
+func checkJSValue(value bson.RawValue) error {
+	switch value.Type {
+	case bson.TypeEmbeddedDocument:
+		elems, err := value.Document().Elements()
+		if err != nil {
+			return err
+		}
+		for _, elem := range elems {
+			if jsOperators[elem.Key()] {
+				return fmt.Errorf("%w: %s", ErrServerSideJS, elem.Key())
+			}
+			if err := checkJSValue(elem.Value()); err != nil {
+				return err
+			}
+		}
+	case bson.TypeArray:
+		values, err := value.Array().Values()
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if err := checkJSValue(v); err != nil {
+				return err
+			}
+		}
+	case bson.TypeJavaScript, bson.TypeCodeWithScope:
+		return fmt.Errorf("%w: javascript value", ErrServerSideJS)
+	}
+	return nil
+}
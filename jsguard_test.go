@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestCheckFilter(t *testing.T) {
+	type filter struct {
+		Where string `bson:"$where"`
+	}
+	type plain struct {
+		Name string `bson:"name"`
+	}
+	raw, err := bson.Marshal(bson.D{{Key: "$where", Value: "this.a > 1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter any
+		err    error
+	}{
+		{name: "nil"},
+		{name: "plain", filter: bson.D{{Key: "status", Value: "active"}, {Key: "n", Value: bson.D{{Key: "$gt", Value: 1}}}}},
+		{name: "field named mapReduce", filter: bson.D{{Key: "mapReduce", Value: true}}},
+		{name: "operator name as a value", filter: bson.M{"note": "$where"}},
+		{name: "$where", filter: bson.D{{Key: "$where", Value: "this.a > 1"}}, err: ErrServerSideJS},
+		{name: "nested $where", filter: bson.M{"$and": bson.A{bson.M{"a": 1}, bson.M{"$where": "true"}}}, err: ErrServerSideJS},
+		{name: "map", filter: map[string]any{"$expr": bson.M{"$function": bson.M{}}}, err: ErrServerSideJS},
+		{name: "pipeline", filter: mongo.Pipeline{{{Key: "$group", Value: bson.D{{Key: "x", Value: bson.D{{Key: "$accumulator", Value: bson.D{}}}}}}}}, err: ErrServerSideJS},
+		{name: "pipeline without js", filter: mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "a", Value: 1}}}}}},
+		{name: "javascript value", filter: bson.D{{Key: "a", Value: bson.JavaScript("1")}}, err: ErrServerSideJS},
+		{name: "raw", filter: bson.Raw(raw), err: ErrServerSideJS},
+		{name: "struct", filter: filter{Where: "true"}, err: ErrServerSideJS},
+		{name: "struct pointer", filter: &plain{Name: "a"}},
+		{name: "scalar", filter: bson.D{{Key: "ids", Value: []int{1, 2}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckFilter(tt.filter); !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  bson.D
+		err  error
+	}{
+		{name: "empty"},
+		{name: "find", cmd: bson.D{{Key: "find", Value: "orders"}, {Key: "filter", Value: bson.D{{Key: "mapReduce", Value: 1}}}}},
+		{name: "mapReduce", cmd: bson.D{{Key: "mapReduce", Value: "orders"}}, err: ErrServerSideJS},
+		{name: "lowercase mapreduce", cmd: bson.D{{Key: "mapreduce", Value: "orders"}}, err: ErrServerSideJS},
+		{name: "js in filter", cmd: bson.D{{Key: "find", Value: "orders"}, {Key: "filter", Value: bson.D{{Key: "$where", Value: "true"}}}}, err: ErrServerSideJS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckCommand(tt.cmd); !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAllowServerSideJS(t *testing.T) {
+	AllowServerSideJS(true)
+	defer AllowServerSideJS(false)
+
+	if err := CheckFilter(bson.D{{Key: "$where", Value: "true"}}); err != nil {
+		t.Errorf("CheckFilter = %v, want nil when allowed", err)
+	}
+	if err := CheckCommand(bson.D{{Key: "mapReduce", Value: "orders"}}); err != nil {
+		t.Errorf("CheckCommand = %v, want nil when allowed", err)
+	}
+}
@@ -0,0 +1,100 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fireflycore/go-mongo/internal"
+)
+
+// DefaultLogFileMaxSize 为日志文件的默认滚动大小（MB）。
+const DefaultLogFileMaxSize = 100
+
+// LogFileConf 为命令日志的本地文件输出配置：每条命令以一行 JSON（与 OperationLog 相同）追加写入，
+// 超过 MaxSize 时滚动为 {name}-{time}{ext}。
+type LogFileConf struct {
+	// Path 为日志文件路径，目录不存在时自动创建。
+	Path string `json:"path"`
+	// MaxSize 为单个文件的最大大小（单位：MB），<=0 时使用 DefaultLogFileMaxSize。
+	MaxSize int `json:"max_size"`
+	// MaxAge 为滚动文件的保留天数，<=0 表示不删除。
+	MaxAge int `json:"max_age"`
+	// Compress 为 true 时使用 gzip 压缩滚动文件。
+	Compress bool `json:"compress"`
+}
+
+// logFile 为按路径共享的日志文件，同一路径的多个连接（包括 Reconnect 期间的新旧连接）写入同一文件。
+type logFile struct {
+	path string
+	file *internal.RotateFile
+	refs int
+}
+
+var (
+	logFilesMu sync.Mutex
+	// logFiles 为按绝对路径登记的已打开日志文件。
+	logFiles = make(map[string]*logFile)
+	// logFileByClient 按 client（见 clientKey）记录持有的日志文件，断开连接或 client 被回收时释放。
+	logFileByClient sync.Map
+)
+
+// acquireLogFile 打开或复用 conf.Path 对应的日志文件并增加引用。
+func acquireLogFile(conf *LogFileConf) (*logFile, error) {
+	path, err := filepath.Abs(conf.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+
+	if f, ok := logFiles[path]; ok {
+		f.refs++
+		return f, nil
+	}
+
+	maxSize := conf.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultLogFileMaxSize
+	}
+	file, err := internal.OpenRotateFile(path, int64(maxSize)<<20, time.Duration(conf.MaxAge)*24*time.Hour, conf.Compress)
+	if err != nil {
+		return nil, err
+	}
+	f := &logFile{path: path, file: file, refs: 1}
+	logFiles[path] = f
+	return f, nil
+}
+
+// release 减少引用，最后一个引用释放时关闭文件。
+func (f *logFile) release() {
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+
+	f.refs--
+	if f.refs > 0 {
+		return
+	}
+	delete(logFiles, f.path)
+	_ = f.file.Close()
+}
+
+// handle 实现 LogHandler，将日志以一行 JSON 写入文件。
+func (f *logFile) handle(_ context.Context, log *OperationLog) error {
+	b, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+	_, err = f.file.Write(append(b, '\n'))
+	return err
+}
+
+// releaseLogFile 释放 client 持有的日志文件。
+func releaseLogFile(key clientKey) {
+	if v, ok := logFileByClient.LoadAndDelete(key); ok {
+		v.(*logFile).release()
+	}
+}
@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/fireflycore/go-mongo/internal"
+)
+
+// OperationLog 为一条结构化命令日志，与 OTel Logs 上报的日志体一致。
+type OperationLog = internal.OperationLogger
+
+// LogReply 为成功命令的回复摘要。
+type LogReply = internal.Reply
+
+// LogHandler 接收结构化命令日志（如写入 Kafka、统计指标），每个 handler 收到独立的副本。
+// 返回的错误或 panic 只影响该 handler 本身，会以 WARN 写入本地输出（slog 或控制台）。
+type LogHandler func(ctx context.Context, log *OperationLog) error
+
+// WithLogHandler 追加接收结构化命令日志的 handler，可多次调用，按注册顺序执行；需同时开启 Logger。
+// OTel Logs 上报与本地输出不受影响。handler 在命令监控回调中同步执行，耗时操作应自行异步处理。
+func (c *Conf) WithLogHandler(handlers ...LogHandler) {
+	for _, h := range handlers {
+		if h != nil {
+			c.logHandlers = append(c.logHandlers, h)
+		}
+	}
+}
+
+// handlers 返回传给内部 logger 的 handler。
+func (c *Conf) handlers() []internal.Handler {
+	list := make([]internal.Handler, 0, len(c.logHandlers))
+	for _, h := range c.logHandlers {
+		list = append(list, internal.Handler(h))
+	}
+	return list
+}
@@ -0,0 +1,165 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrUnknownInstance 表示 Manager 中不存在该名称的实例。
+var ErrUnknownInstance = errors.New("mongo: unknown instance")
+
+// Manager 管理多个命名的连接实例：首次 Get 时才建立连接并缓存，CloseAll 统一关闭。
+// shared 配置项会作用于每个实例，用于共享日志、命令监控器等设置。
+type Manager struct {
+	shared []Option
+
+	mu        sync.Mutex
+	instances map[string]*managedInstance
+}
+
+type managedInstance struct {
+	mu     sync.Mutex
+	conf   *Conf
+	client *Client
+}
+
+// NewManager 创建连接管理器，confs 为实例名到配置的映射，shared 为各实例共享的配置项。
+func NewManager(confs map[string]*Conf, shared ...Option) *Manager {
+	m := &Manager{shared: shared, instances: make(map[string]*managedInstance, len(confs))}
+	for name, conf := range confs {
+		m.instances[name] = &managedInstance{conf: conf}
+	}
+	return m
+}
+
+// Register 添加或替换实例配置；已建立连接的实例不受影响，需先 Close 后才会按新配置连接。
+func (m *Manager) Register(name string, conf *Conf) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if inst, ok := m.instances[name]; ok {
+		inst.mu.Lock()
+		inst.conf = conf
+		inst.mu.Unlock()
+		return
+	}
+	m.instances[name] = &managedInstance{conf: conf}
+}
+
+// Names 返回已注册的实例名（按字典序）。
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.instances))
+	for name := range m.instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get 返回实例的 Client，首次调用时建立连接，等价于 GetContext(context.Background(), name)。
+func (m *Manager) Get(name string) (*Client, error) {
+	return m.GetContext(context.Background(), name)
+}
+
+// GetContext 返回实例的 Client，首次调用时按 ctx 建立连接；连接失败不会缓存，下次调用重试。
+// 不同实例的连接互不阻塞。
+func (m *Manager) GetContext(ctx context.Context, name string) (*Client, error) {
+	m.mu.Lock()
+	inst, ok := m.instances[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownInstance, name)
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.client != nil {
+		return inst.client, nil
+	}
+	if inst.conf == nil {
+		return nil, fmt.Errorf("mongo: instance %q: conf is nil", name)
+	}
+
+	client, err := NewClientWithContext(ctx, m.confFor(inst.conf))
+	if err != nil {
+		return nil, fmt.Errorf("mongo: instance %q: %w", name, err)
+	}
+	inst.client = client
+	return client, nil
+}
+
+// DB 返回实例的默认数据库句柄。
+func (m *Manager) DB(ctx context.Context, name string) (*mongo.Database, error) {
+	client, err := m.GetContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return client.DB(), nil
+}
+
+// Close 关闭单个实例的连接，之后再次 Get 会重新连接。
+func (m *Manager) Close(ctx context.Context, name string) error {
+	m.mu.Lock()
+	inst, ok := m.instances[name]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownInstance, name)
+	}
+	return inst.close(ctx)
+}
+
+// CloseAll 关闭全部已建立的连接，错误合并后返回。
+func (m *Manager) CloseAll(ctx context.Context) error {
+	m.mu.Lock()
+	list := make([]*managedInstance, 0, len(m.instances))
+	for _, inst := range m.instances {
+		list = append(list, inst)
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, inst := range list {
+		if err := inst.close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (inst *managedInstance) close(ctx context.Context) error {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.client == nil {
+		return nil
+	}
+	err := inst.client.Close(ctx)
+	inst.client = nil
+	return err
+}
+
+// confFor 复制实例配置并应用共享配置项，不修改调用方传入的 Conf。
+func (m *Manager) confFor(conf *Conf) *Conf {
+	c := *conf
+	// 截断容量，避免各实例追加监控器时共用底层数组。
+	c.monitors = slices.Clip(c.monitors)
+	c.poolMonitors = slices.Clip(c.poolMonitors)
+	c.serverMonitors = slices.Clip(c.serverMonitors)
+	c.codecs = slices.Clip(c.codecs)
+	for _, opt := range m.shared {
+		if opt != nil {
+			opt(&c)
+		}
+	}
+	return &c
+}
@@ -0,0 +1,208 @@
+package mongo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// DefaultLatencyBuckets 为默认的延迟直方图桶上界。
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Bucket 为直方图中的一个桶，Le 为上界（0 表示 +Inf），Count 为落入该桶的命令数（非累计）。
+type Bucket struct {
+	Le    time.Duration `json:"le"`
+	Count uint64        `json:"count"`
+}
+
+// OperationStats 为某个集合上某种命令的延迟统计。
+type OperationStats struct {
+	Database   string        `json:"database"`
+	Collection string        `json:"collection"`
+	Operation  string        `json:"operation"`
+	Count      uint64        `json:"count"`
+	Failures   uint64        `json:"failures"`
+	Sum        time.Duration `json:"sum"`
+	Max        time.Duration `json:"max"`
+	Buckets    []Bucket      `json:"buckets"`
+}
+
+// Mean 返回平均延迟。
+func (s *OperationStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile 按桶上界估算分位数（q 取 0~1），落入 +Inf 桶时返回 Max。
+func (s *OperationStats) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(s.Count))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for _, b := range s.Buckets {
+		seen += b.Count
+		if seen >= rank {
+			if b.Le == 0 {
+				return s.Max
+			}
+			return b.Le
+		}
+	}
+	return s.Max
+}
+
+type metricsKey struct {
+	database   string
+	collection string
+	operation  string
+}
+
+// Metrics 在内存中按集合与命令累计延迟直方图，无需外部指标系统即可通过 Stats 自报运行状况。
+type Metrics struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	stats   map[metricsKey]*OperationStats
+	pending sync.Map
+}
+
+// NewMetrics 创建 Metrics，buckets 为升序的桶上界，为空时使用 DefaultLatencyBuckets。
+func NewMetrics(buckets ...time.Duration) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	return &Metrics{buckets: buckets, stats: make(map[metricsKey]*OperationStats)}
+}
+
+// WithMetrics 将 Metrics 的命令监控器注册到 conf。
+func (c *Conf) WithMetrics(metrics *Metrics) {
+	c.WithCommandMonitor(metrics.Monitor())
+}
+
+// Monitor 返回采集延迟的命令监控器，不统计 hello/ping 等维护类命令。
+func (m *Metrics) Monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			if IsHousekeepingCommand(e.CommandName) {
+				return
+			}
+			m.pending.Store(e.RequestID, metricsKey{
+				database:   e.DatabaseName,
+				collection: CommandCollection(e.Command, e.CommandName),
+				operation:  e.CommandName,
+			})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.observe(e.RequestID, e.Duration, false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			m.observe(e.RequestID, e.Duration, true)
+		},
+	}
+}
+
+func (m *Metrics) observe(id int64, d time.Duration, failed bool) {
+	v, ok := m.pending.LoadAndDelete(id)
+	if !ok {
+		return
+	}
+	key := v.(metricsKey)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stats[key]
+	if !ok {
+		s = &OperationStats{
+			Database:   key.database,
+			Collection: key.collection,
+			Operation:  key.operation,
+			Buckets:    make([]Bucket, len(m.buckets)+1),
+		}
+		for i, le := range m.buckets {
+			s.Buckets[i].Le = le
+		}
+		m.stats[key] = s
+	}
+
+	s.Count++
+	if failed {
+		s.Failures++
+	}
+	s.Sum += d
+	s.Max = max(s.Max, d)
+
+	i := sort.Search(len(m.buckets), func(i int) bool { return d <= m.buckets[i] })
+	s.Buckets[i].Count++
+}
+
+// Stats 返回当前统计的快照，按数据库、集合、命令排序。
+func (m *Metrics) Stats() []OperationStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]OperationStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		cp := *s
+		cp.Buckets = append([]Bucket(nil), s.Buckets...)
+		list = append(list, cp)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		if a.Collection != b.Collection {
+			return a.Collection < b.Collection
+		}
+		return a.Operation < b.Operation
+	})
+	return list
+}
+
+// Reset 清空已累计的统计。
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	m.stats = make(map[metricsKey]*OperationStats)
+	m.mu.Unlock()
+}
+
+// CommandCollection 从命令文档中取出目标集合：大多数 CRUD 命令的第一个字段值即为集合名，
+// getMore 的命令名字段为游标 ID，集合名在 collection 字段中。
+func CommandCollection(cmd bson.Raw, name string) string {
+	key := name
+	if name == "getMore" {
+		key = "collection"
+	}
+	v, err := cmd.LookupErr(key)
+	if err != nil {
+		return ""
+	}
+	s, _ := v.StringValueOK()
+	return s
+}
@@ -0,0 +1,237 @@
+package mongobench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// 基准用例名称。
+const (
+	CaseRaw    = "raw"    // CaseRaw 原生 driver，无任何监控器，作为基线。
+	CaseOTel   = "otel"   // CaseOTel 通过 New 创建，仅启用 otelmongo。
+	CaseLogger = "logger" // CaseLogger 通过 New 创建，启用 otelmongo 与命令日志（不输出控制台）。
+	CaseDecode = "decode" // CaseDecode 在 CaseOTel 基础上解码为嵌入 Table 的结构体。
+)
+
+// Result 为单个用例的基准结果。
+type Result struct {
+	Name        string `json:"name"`
+	N           int    `json:"n"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+}
+
+// Report 为一次完整基准运行的结果。
+type Report struct {
+	Results map[string]Result `json:"results"`
+}
+
+// Overhead 返回用例相对 CaseRaw 的耗时开销比例（0.1 表示慢 10%）。
+func (r *Report) Overhead(name string) float64 {
+	base, ok := r.Results[CaseRaw]
+	if !ok || base.NsPerOp == 0 {
+		return 0
+	}
+	res, ok := r.Results[name]
+	if !ok {
+		return 0
+	}
+	return float64(res.NsPerOp)/float64(base.NsPerOp) - 1
+}
+
+// Budget 为各用例允许的最大开销比例。
+type Budget map[string]float64
+
+// Check 校验报告是否满足预算，超出预算的用例合并为一个错误返回。
+func (r *Report) Check(budget Budget) error {
+	names := make([]string, 0, len(budget))
+	for name := range budget {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, ok := r.Results[name]; !ok {
+			errs = append(errs, fmt.Errorf("mongobench: case %q has no result", name))
+			continue
+		}
+		if overhead := r.Overhead(name); overhead > budget[name] {
+			errs = append(errs, fmt.Errorf("mongobench: case %q overhead %.1f%% exceeds budget %.1f%%", name, overhead*100, budget[name]*100))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// String 输出便于 CI 日志阅读的结果表。
+func (r *Report) String() string {
+	names := make([]string, 0, len(r.Results))
+	for name := range r.Results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		res := r.Results[name]
+		fmt.Fprintf(&sb, "%-8s %10d ns/op %8d B/op %6d allocs/op %+7.1f%%\n", name, res.NsPerOp, res.BytesPerOp, res.AllocsPerOp, r.Overhead(name)*100)
+	}
+	return sb.String()
+}
+
+type benchDoc struct {
+	gomongo.Table `bson:",inline"`
+	Name          string `bson:"name"`
+	Count         int64  `bson:"count"`
+}
+
+// Run 对同一份文档分别以原生 driver 与本包封装执行 FindOne，测量各层开销。
+// 运行期间会在 conf.Database 中创建临时集合，结束后删除；conf 不会被修改。
+func Run(ctx context.Context, conf *gomongo.Conf) (*Report, error) {
+	if conf == nil {
+		return nil, errors.New("mongobench: conf is nil")
+	}
+
+	raw, err := rawDatabase(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	defer raw.Client().Disconnect(context.WithoutCancel(ctx))
+
+	plain := *conf
+	plain.Logger = false
+	otelDB, err := gomongo.NewWithContext(ctx, &plain)
+	if err != nil {
+		return nil, err
+	}
+	// Conf.Shared 时连接可能与调用方共享，通过 Close 释放引用而不是直接断开。
+	defer gomongo.Close(context.WithoutCancel(ctx), otelDB)
+
+	logged := *conf
+	logged.Logger = true
+	logged.WithLoggerConsole(false)
+	loggerDB, err := gomongo.NewWithContext(ctx, &logged)
+	if err != nil {
+		return nil, err
+	}
+	defer gomongo.Close(context.WithoutCancel(ctx), loggerDB)
+
+	name := "mongobench_" + gomongo.NewUUIDv7()
+	doc := &benchDoc{Name: "mongobench", Count: 1}
+	doc.BeforeInsert()
+	if _, err := raw.Collection(name).InsertOne(ctx, doc); err != nil {
+		return nil, err
+	}
+	defer raw.Collection(name).Drop(context.WithoutCancel(ctx))
+
+	filter := bson.D{{Key: "_id", Value: doc.Id}}
+	report := &Report{Results: make(map[string]Result)}
+
+	cases := []struct {
+		name string
+		db   *mongo.Database
+		out  func() any
+	}{
+		{CaseRaw, raw, func() any { return &bson.Raw{} }},
+		{CaseOTel, otelDB, func() any { return &bson.Raw{} }},
+		{CaseLogger, loggerDB, func() any { return &bson.Raw{} }},
+		{CaseDecode, otelDB, func() any { return &benchDoc{} }},
+	}
+
+	for _, c := range cases {
+		collection := c.db.Collection(name)
+		res, err := measure(c.name, func() error {
+			return collection.FindOne(ctx, filter).Decode(c.out())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mongobench: case %q: %w", c.name, err)
+		}
+		report.Results[c.name] = res
+	}
+
+	return report, nil
+}
+
+// benchTime 为单个用例的最短测量时间。
+const benchTime = time.Second
+
+// measure 以逐轮加倍的迭代次数执行 fn，直到一轮耗时达到 benchTime，返回该轮的平均耗时与内存分配；
+// 与 testing.Benchmark 的做法一致，但不在业务二进制中引入 testing 包。
+func measure(name string, fn func() error) (Result, error) {
+	// 预热：建立连接、填充缓存。
+	if err := fn(); err != nil {
+		return Result{}, err
+	}
+
+	for n := 1; ; n *= 2 {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if err := fn(); err != nil {
+				return Result{}, err
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if elapsed >= benchTime || n >= 1<<30 {
+			return Result{
+				Name:        name,
+				N:           n,
+				NsPerOp:     elapsed.Nanoseconds() / int64(n),
+				AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(n),
+				BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / int64(n),
+			}, nil
+		}
+	}
+}
+
+// rawDatabase 使用与 conf 相同的地址、认证与 TLS 创建不挂任何监控器的原生连接。
+func rawDatabase(ctx context.Context, conf *gomongo.Conf) (*mongo.Database, error) {
+	uri, err := conf.URI()
+	if err != nil {
+		return nil, err
+	}
+
+	clientOptions := options.Client().ApplyURI(uri)
+	if credential := conf.Credential(); credential != nil {
+		clientOptions.SetAuth(*credential)
+	}
+
+	tlsConfig, err := conf.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		// ServerName 留空，由 driver 按连接的节点主机名校验证书。
+		clientOptions.TLSConfig = tlsConfig
+	}
+
+	client, err := mongo.Connect(clientOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx, nil); err != nil {
+		_ = client.Disconnect(context.WithoutCancel(ctx))
+		return nil, err
+	}
+
+	return client.Database(conf.Database), nil
+}
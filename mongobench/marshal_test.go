@@ -0,0 +1,48 @@
+package mongobench
+
+import (
+	"bytes"
+	"testing"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// marshalFilter 为典型的静态作用域条件：未软删除且状态有效。
+func marshalFilter() any {
+	return bson.D{
+		{Key: "deleted_at", Value: nil},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"active", "pending"}}}},
+		{Key: "tenant_id", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+}
+
+// encode 与 driver 处理过滤条件的方式一致：无论是否为 bson.Raw，都经 Encoder 写入新的缓冲区。
+// bson.Raw 省去的是反射序列化，字节仍会复制一次。
+func encode(b *testing.B, filter any) {
+	buf := new(bytes.Buffer)
+	if err := bson.NewEncoder(bson.NewDocumentWriter(buf)).Encode(filter); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkMarshalFilter 为每次请求序列化过滤条件（driver 默认行为）。
+func BenchmarkMarshalFilter(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		encode(b, marshalFilter())
+	}
+}
+
+// BenchmarkMarshalCachedFilter 通过 FilterCache 复用序列化结果。
+func BenchmarkMarshalCachedFilter(b *testing.B) {
+	cache := gomongo.NewFilterCache()
+	b.ReportAllocs()
+	for b.Loop() {
+		filter, err := cache.Get("bench", marshalFilter)
+		if err != nil {
+			b.Fatal(err)
+		}
+		encode(b, filter)
+	}
+}
@@ -0,0 +1,155 @@
+package mongoprom
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// 命令结果标签值。
+const (
+	ResultSuccess = "success" // ResultSuccess 命令执行成功。
+	ResultError   = "error"   // ResultError 命令执行失败。
+)
+
+// DefaultSlowThreshold 为默认的慢查询阈值。
+const DefaultSlowThreshold = gomongo.DefaultSlowThreshold
+
+// Conf 为 Collector 的配置，零值可用。
+type Conf struct {
+	// Namespace 为指标名前缀：为空时指标名为 mongo_*，否则为 {Namespace}_mongo_*。
+	Namespace string
+	// SlowThreshold 为慢查询阈值，与 mongo.Conf.SlowThreshold 一致：为 0 时使用 DefaultSlowThreshold，为负数时不统计慢查询。
+	SlowThreshold time.Duration
+	// Buckets 为耗时直方图的桶上界（秒），为空时使用 prometheus.DefBuckets。
+	Buckets []float64
+	// ConstLabels 为附加到所有指标的固定标签（如实例名）。
+	ConstLabels prometheus.Labels
+	// IncludeHousekeeping 为 true 时同时统计 hello/isMaster/ping/endSessions 等维护类命令。
+	IncludeHousekeeping bool
+}
+
+// Collector 由命令监控器驱动的 Prometheus 指标：
+// mongo_command_duration_seconds（database/collection/command）、
+// mongo_commands_total（database/collection/command/result）、
+// mongo_slow_queries_total（database/collection/command）。
+type Collector struct {
+	slowThreshold time.Duration
+	housekeeping  bool
+
+	duration *prometheus.HistogramVec
+	commands *prometheus.CounterVec
+	slow     *prometheus.CounterVec
+
+	// pending 保存命令开始时的标签，成功/失败事件不包含集合名。
+	pending sync.Map
+}
+
+// commandKey 唯一标识一条执行中的命令。
+type commandKey struct {
+	connectionId string
+	requestId    int64
+}
+
+// commandLabels 为命令开始时解析出的标签值。
+type commandLabels struct {
+	database   string
+	collection string
+	command    string
+}
+
+// NewCollector 创建 Collector，需由调用方注册到自己的 prometheus.Registerer，
+// 并通过 Attach 或 Monitor 接入连接配置。
+func NewCollector(conf Conf) *Collector {
+	if conf.SlowThreshold == 0 {
+		conf.SlowThreshold = DefaultSlowThreshold
+	}
+	if len(conf.Buckets) == 0 {
+		conf.Buckets = prometheus.DefBuckets
+	}
+	if conf.Namespace == "" {
+		conf.Namespace = "mongo"
+	} else {
+		conf.Namespace += "_mongo"
+	}
+
+	return &Collector{
+		slowThreshold: conf.SlowThreshold,
+		housekeeping:  conf.IncludeHousekeeping,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   conf.Namespace,
+			Name:        "command_duration_seconds",
+			Help:        "Duration of MongoDB commands in seconds.",
+			Buckets:     conf.Buckets,
+			ConstLabels: conf.ConstLabels,
+		}, []string{"database", "collection", "command"}),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   conf.Namespace,
+			Name:        "commands_total",
+			Help:        "Number of MongoDB commands by result.",
+			ConstLabels: conf.ConstLabels,
+		}, []string{"database", "collection", "command", "result"}),
+		slow: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   conf.Namespace,
+			Name:        "slow_queries_total",
+			Help:        "Number of MongoDB commands slower than the slow query threshold.",
+			ConstLabels: conf.ConstLabels,
+		}, []string{"database", "collection", "command"}),
+	}
+}
+
+// Describe 实现 prometheus.Collector。
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.commands.Describe(ch)
+	c.slow.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector。
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.duration.Collect(ch)
+	c.commands.Collect(ch)
+	c.slow.Collect(ch)
+}
+
+// Attach 将 Collector 的命令监控器注册到 conf，与 otelmongo 及命令日志串联执行。
+func (c *Collector) Attach(conf *gomongo.Conf) {
+	conf.WithCommandMonitor(c.Monitor())
+}
+
+// Monitor 返回驱动指标的命令监控器，可与其他监控器一起通过 conf.WithCommandMonitor 注册。
+func (c *Collector) Monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			if !c.housekeeping && gomongo.IsHousekeepingCommand(e.CommandName) {
+				return
+			}
+			labels := commandLabels{database: e.DatabaseName, command: e.CommandName, collection: gomongo.CommandCollection(e.Command, e.CommandName)}
+			c.pending.Store(commandKey{e.ConnectionID, e.RequestID}, labels)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			c.observe(&e.CommandFinishedEvent, ResultSuccess)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			c.observe(&e.CommandFinishedEvent, ResultError)
+		},
+	}
+}
+
+func (c *Collector) observe(e *event.CommandFinishedEvent, result string) {
+	v, ok := c.pending.LoadAndDelete(commandKey{e.ConnectionID, e.RequestID})
+	if !ok {
+		return
+	}
+	l := v.(commandLabels)
+
+	c.duration.WithLabelValues(l.database, l.collection, l.command).Observe(e.Duration.Seconds())
+	c.commands.WithLabelValues(l.database, l.collection, l.command, result).Inc()
+	if c.slowThreshold > 0 && e.Duration >= c.slowThreshold {
+		c.slow.WithLabelValues(l.database, l.collection, l.command).Inc()
+	}
+}
@@ -0,0 +1,285 @@
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Overrides 为字段覆盖表，键为 Go 字段名或 bson 字段名。
+// 值为固定值，或 func(i int) any 形式的生成函数（i 为文档序号）。
+type Overrides map[string]any
+
+type beforeInserter interface {
+	BeforeInsert()
+}
+
+var (
+	tTime = reflect.TypeOf(time.Time{})
+
+	firstNames = []string{"Alice", "Bob", "Carol", "David", "Eve", "Frank", "Grace", "Heidi", "Ivan", "Judy"}
+	lastNames  = []string{"Smith", "Chen", "Wang", "Garcia", "Miller", "Li", "Zhang", "Brown", "Lopez", "Wilson"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet"}
+	cities     = []string{"Shanghai", "Beijing", "Shenzhen", "Hangzhou", "Chengdu", "Singapore", "Tokyo", "Berlin"}
+	domains    = []string{"example.com", "example.org", "example.net"}
+)
+
+// Generate 根据结构体字段类型与 `fake` tag 生成 n 个文档，并对实现了 BeforeInsert 的文档调用该方法。
+//
+// 支持的 tag：name、first_name、last_name、email、phone、uuid、word、sentence、city、url、
+// int:min,max、float:min,max、enum:a|b|c、skip。未设置 tag 时按字段名与类型推断。
+func Generate[T any](n int, overrides Overrides) []T {
+	list := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		var doc T
+		v := reflect.ValueOf(&doc).Elem()
+		if v.Kind() == reflect.Ptr {
+			v.Set(reflect.New(v.Type().Elem()))
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			fillStruct(v, i)
+		}
+
+		if bi, ok := any(&doc).(beforeInserter); ok {
+			bi.BeforeInsert()
+		} else if bi, ok := any(doc).(beforeInserter); ok {
+			bi.BeforeInsert()
+		}
+
+		if v.Kind() == reflect.Struct {
+			applyOverrides(v, overrides, i)
+		}
+
+		list = append(list, doc)
+	}
+	return list
+}
+
+// Seed 生成 n 个文档并通过 InsertMany 批量写入集合，返回生成的文档。
+func Seed[T any](ctx context.Context, collection *mongo.Collection, n int, overrides Overrides) ([]T, error) {
+	list := Generate[T](n, overrides)
+	if len(list) == 0 {
+		return list, nil
+	}
+
+	docs := make([]any, 0, len(list))
+	for i := range list {
+		docs = append(docs, list[i])
+	}
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func fillStruct(v reflect.Value, i int) {
+	t := v.Type()
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(j)
+
+		hint := sf.Tag.Get("fake")
+		if hint == "skip" {
+			continue
+		}
+		if sf.Anonymous && fv.Kind() == reflect.Struct {
+			fillStruct(fv, i)
+			continue
+		}
+		fillValue(fv, hint, strings.ToLower(sf.Name), i)
+	}
+}
+
+func fillValue(v reflect.Value, hint, name string, i int) {
+	if hint == "" {
+		hint = inferHint(v.Type(), name)
+	}
+	kind, args, _ := strings.Cut(hint, ":")
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fakeString(kind, args, i))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lo, hi := intRange(args, 0, 1000)
+		v.SetInt(lo + rand.Int64N(hi-lo+1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lo, hi := intRange(args, 0, 1000)
+		if lo < 0 {
+			lo = 0
+		}
+		v.SetUint(uint64(lo + rand.Int64N(hi-lo+1)))
+	case reflect.Float32, reflect.Float64:
+		lo, hi := floatRange(args, 0, 1000)
+		v.SetFloat(lo + rand.Float64()*(hi-lo))
+	case reflect.Bool:
+		v.SetBool(rand.IntN(2) == 1)
+	case reflect.Struct:
+		if v.Type() == tTime {
+			v.Set(reflect.ValueOf(time.Now().UTC().Add(-time.Duration(rand.Int64N(int64(30 * 24 * time.Hour))))))
+			return
+		}
+		fillStruct(v, i)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		size := 1 + rand.IntN(3)
+		s := reflect.MakeSlice(v.Type(), size, size)
+		for k := 0; k < size; k++ {
+			s.Index(k).SetString(fakeString(kind, args, i))
+		}
+		v.Set(s)
+	}
+	// 指针字段（如 DeletedAt）保持 nil，表示未设置。
+}
+
+// inferHint 在未设置 tag 时根据字段名推断生成策略。
+func inferHint(t reflect.Type, name string) string {
+	if t.Kind() != reflect.String && !(t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String) {
+		return ""
+	}
+	switch {
+	case strings.Contains(name, "email"):
+		return "email"
+	case strings.Contains(name, "phone") || strings.Contains(name, "mobile"):
+		return "phone"
+	case strings.HasSuffix(name, "id"):
+		return "uuid"
+	case strings.Contains(name, "first"):
+		return "first_name"
+	case strings.Contains(name, "last"):
+		return "last_name"
+	case strings.Contains(name, "name"):
+		return "name"
+	case strings.Contains(name, "city"):
+		return "city"
+	case strings.Contains(name, "url") || strings.Contains(name, "link"):
+		return "url"
+	case strings.Contains(name, "desc") || strings.Contains(name, "content") || strings.Contains(name, "remark"):
+		return "sentence"
+	default:
+		return "word"
+	}
+}
+
+func fakeString(kind, args string, i int) string {
+	switch kind {
+	case "name":
+		return pick(firstNames) + " " + pick(lastNames)
+	case "first_name":
+		return pick(firstNames)
+	case "last_name":
+		return pick(lastNames)
+	case "email":
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(pick(firstNames)), strings.ToLower(pick(lastNames)), i, pick(domains))
+	case "phone":
+		return fmt.Sprintf("1%02d%08d", 30+rand.IntN(60), rand.IntN(100000000))
+	case "uuid":
+		return uuid.NewString()
+	case "sentence":
+		n := 4 + rand.IntN(6)
+		parts := make([]string, n)
+		for k := range parts {
+			parts[k] = pick(words)
+		}
+		s := strings.Join(parts, " ")
+		return strings.ToUpper(s[:1]) + s[1:] + "."
+	case "city":
+		return pick(cities)
+	case "url":
+		return fmt.Sprintf("https://%s/%s/%d", pick(domains), pick(words), i)
+	case "enum":
+		return pick(strings.Split(args, "|"))
+	default:
+		return fmt.Sprintf("%s-%d", pick(words), i)
+	}
+}
+
+func applyOverrides(v reflect.Value, overrides Overrides, i int) {
+	for key, value := range overrides {
+		fv, ok := fieldByName(v, key)
+		if !ok || !fv.CanSet() {
+			continue
+		}
+		if fn, ok := value.(func(int) any); ok {
+			value = fn(i)
+		}
+		if value == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+		rv := reflect.ValueOf(value)
+		if rv.Type().AssignableTo(fv.Type()) {
+			fv.Set(rv)
+		} else if rv.Type().ConvertibleTo(fv.Type()) {
+			fv.Set(rv.Convert(fv.Type()))
+		}
+	}
+}
+
+// fieldByName 按 Go 字段名或 bson tag 名查找字段（包含嵌入结构体）。
+func fieldByName(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get("bson"), ",")
+		if sf.Name == key || tag == key {
+			return v.Field(j), true
+		}
+		if sf.Anonymous && v.Field(j).Kind() == reflect.Struct {
+			if fv, ok := fieldByName(v.Field(j), key); ok {
+				return fv, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func pick(list []string) string {
+	return list[rand.IntN(len(list))]
+}
+
+func intRange(args string, lo, hi int64) (int64, int64) {
+	if a, b, ok := strings.Cut(args, ","); ok {
+		if v, err := strconv.ParseInt(strings.TrimSpace(a), 10, 64); err == nil {
+			lo = v
+		}
+		if v, err := strconv.ParseInt(strings.TrimSpace(b), 10, 64); err == nil {
+			hi = v
+		}
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+func floatRange(args string, lo, hi float64) (float64, float64) {
+	if a, b, ok := strings.Cut(args, ","); ok {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(a), 64); err == nil {
+			lo = v
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(b), 64); err == nil {
+			hi = v
+		}
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
@@ -0,0 +1,92 @@
+package mongotest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UpdateGoldenEnv 为非空时 AssertFilter 会用当前结果覆盖 golden 文件。
+const UpdateGoldenEnv = "MONGOTEST_UPDATE_GOLDEN"
+
+// AssertFilter 将 got 规范化为 Canonical Extended JSON 后与 golden 文件按键顺序逐行比较。
+// golden 文件同样会被解析并重新格式化，因此只要求语义与键顺序一致，不要求缩进一致。
+func AssertFilter(t testing.TB, got bson.D, goldenFile string) {
+	t.Helper()
+
+	actual, err := canonicalJSON(got)
+	if err != nil {
+		t.Fatalf("mongotest: marshal filter: %v", err)
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatalf("mongotest: create golden dir: %v", err)
+		}
+		if err := os.WriteFile(goldenFile, []byte(actual+"\n"), 0o644); err != nil {
+			t.Fatalf("mongotest: write golden file: %v", err)
+		}
+		return
+	}
+
+	raw, err := os.ReadFile(goldenFile)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("mongotest: golden file %s not found, run with %s=1 to create it", goldenFile, UpdateGoldenEnv)
+	}
+	if err != nil {
+		t.Fatalf("mongotest: read golden file: %v", err)
+	}
+
+	var want bson.D
+	if err := bson.UnmarshalExtJSON(raw, true, &want); err != nil {
+		t.Fatalf("mongotest: parse golden file %s: %v", goldenFile, err)
+	}
+	expected, err := canonicalJSON(want)
+	if err != nil {
+		t.Fatalf("mongotest: marshal golden file: %v", err)
+	}
+
+	if actual != expected {
+		t.Errorf("mongotest: filter does not match %s\n%s", goldenFile, lineDiff(expected, actual))
+	}
+}
+
+func canonicalJSON(doc bson.D) (string, error) {
+	if doc == nil {
+		doc = bson.D{}
+	}
+	b, err := bson.MarshalExtJSONIndent(doc, true, false, "", "  ")
+	return string(b), err
+}
+
+// lineDiff 输出简单的逐行差异：- 为 golden，+ 为实际结果。
+func lineDiff(want, got string) string {
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+
+	var sb strings.Builder
+	for i := 0; i < max(len(wl), len(gl)); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w == g {
+			sb.WriteString("  " + w + "\n")
+			continue
+		}
+		if i < len(wl) {
+			sb.WriteString("- " + w + "\n")
+		}
+		if i < len(gl) {
+			sb.WriteString("+ " + g + "\n")
+		}
+	}
+	return sb.String()
+}
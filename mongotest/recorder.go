@@ -0,0 +1,210 @@
+package mongotest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// Command 为记录下来的一条命令。
+type Command struct {
+	RequestID  int64
+	Name       string
+	Database   string
+	Collection string
+	Command    bson.Raw
+	Duration   time.Duration
+	// Finished 表示命令已结束（成功或失败）。
+	Finished bool
+	// Failure 为失败原因，成功时为空。
+	Failure string
+}
+
+// Recorder 通过命令监控器记录客户端发出的所有命令，用于断言代码实际执行的查询。
+type Recorder struct {
+	mu       sync.Mutex
+	commands []Command
+	index    map[int64]int
+}
+
+// Record 创建 Recorder 并注册到 conf，之后由 conf 创建的连接发出的命令都会被记录。
+func Record(conf *gomongo.Conf) *Recorder {
+	r := &Recorder{index: make(map[int64]int)}
+	conf.WithCommandMonitor(r.Monitor())
+	return r
+}
+
+// Monitor 返回 Recorder 使用的命令监控器，可用于直接挂载到 driver options。
+func (r *Recorder) Monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			cmd := Command{
+				RequestID: e.RequestID,
+				Name:      e.CommandName,
+				Database:  e.DatabaseName,
+				// driver 可能复用底层缓冲区，这里拷贝一份。
+				Command: bson.Raw(slices.Clone(e.Command)),
+			}
+			cmd.Collection = collectionOf(cmd.Command, cmd.Name)
+
+			r.mu.Lock()
+			r.index[e.RequestID] = len(r.commands)
+			r.commands = append(r.commands, cmd)
+			r.mu.Unlock()
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			r.finish(e.RequestID, e.Duration, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			var failure string
+			if e.Failure != nil {
+				failure = e.Failure.Error()
+			}
+			r.finish(e.RequestID, e.Duration, failure)
+		},
+	}
+}
+
+func (r *Recorder) finish(id int64, d time.Duration, failure string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index[id]
+	if !ok {
+		return
+	}
+	delete(r.index, id)
+	r.commands[i].Duration = d
+	r.commands[i].Finished = true
+	r.commands[i].Failure = failure
+}
+
+// Commands 返回目前记录的命令快照。
+func (r *Recorder) Commands() Commands {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.commands)
+}
+
+// Reset 清空已记录的命令。
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = nil
+	r.index = make(map[int64]int)
+}
+
+// Commands 为命令列表，匹配方法均返回过滤后的新列表，可链式调用。
+type Commands []Command
+
+// HasCommand 过滤出指定命令名（find/insert/update/delete/aggregate...）的命令。
+func (c Commands) HasCommand(name string) Commands {
+	return c.Filter(func(cmd Command) bool { return cmd.Name == name })
+}
+
+// OnCollection 过滤出作用于指定集合的命令。
+func (c Commands) OnCollection(name string) Commands {
+	return c.Filter(func(cmd Command) bool { return cmd.Collection == name })
+}
+
+// OnDatabase 过滤出作用于指定库的命令。
+func (c Commands) OnDatabase(name string) Commands {
+	return c.Filter(func(cmd Command) bool { return cmd.Database == name })
+}
+
+// WithField 过滤出命令文档中任意层级包含点路径（如 "$set.updated_at"）的命令。
+func (c Commands) WithField(path string) Commands {
+	parts := strings.Split(path, ".")
+	return c.Filter(func(cmd Command) bool { return hasPath(cmd.Command, parts) })
+}
+
+// Failed 过滤出执行失败的命令。
+func (c Commands) Failed() Commands {
+	return c.Filter(func(cmd Command) bool { return cmd.Failure != "" })
+}
+
+// Filter 按自定义条件过滤命令。
+func (c Commands) Filter(fn func(Command) bool) Commands {
+	var list Commands
+	for _, cmd := range c {
+		if fn(cmd) {
+			list = append(list, cmd)
+		}
+	}
+	return list
+}
+
+// Exists 判断列表是否非空。
+func (c Commands) Exists() bool {
+	return len(c) != 0
+}
+
+// Names 返回列表中的命令名，便于断言失败时输出。
+func (c Commands) Names() []string {
+	names := make([]string, 0, len(c))
+	for _, cmd := range c {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// collectionOf 从命令文档中取出目标集合：通常为第一个元素的值，getMore 为 collection 字段。
+func collectionOf(cmd bson.Raw, name string) string {
+	if name == "getMore" {
+		if v, ok := cmd.Lookup("collection").StringValueOK(); ok {
+			return v
+		}
+		return ""
+	}
+
+	elems, err := cmd.Elements()
+	if err != nil || len(elems) == 0 {
+		return ""
+	}
+	if v, ok := elems[0].Value().StringValueOK(); ok {
+		return v
+	}
+	return ""
+}
+
+// hasPath 在文档任意层级（包括数组元素）查找点路径。
+func hasPath(doc bson.Raw, parts []string) bool {
+	elems, err := doc.Elements()
+	if err != nil {
+		return false
+	}
+
+	for _, elem := range elems {
+		value := elem.Value()
+		if elem.Key() == parts[0] {
+			if len(parts) == 1 {
+				return true
+			}
+			if sub, ok := subDocument(value); ok && hasPath(sub, parts[1:]) {
+				return true
+			}
+		}
+		if sub, ok := subDocument(value); ok && hasPath(sub, parts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func subDocument(value bson.RawValue) (bson.Raw, bool) {
+	switch value.Type {
+	case bson.TypeEmbeddedDocument:
+		return value.Document(), true
+	case bson.TypeArray:
+		return bson.Raw(value.Array()), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,224 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// housekeepingCommands 为服务端维护类命令（小写）：握手、探活与会话清理。
+var housekeepingCommands = map[string]bool{
+	"hello":       true,
+	"ismaster":    true,
+	"ping":        true,
+	"endsessions": true,
+}
+
+// IsHousekeepingCommand 判断命令是否为 hello/isMaster/ping/endSessions 等服务端维护类命令（不区分大小写），
+// 这类命令默认不计入命令日志与指标。
+func IsHousekeepingCommand(name string) bool {
+	return housekeepingCommands[strings.ToLower(name)]
+}
+
+// newLoggerMonitor 构造把命令执行信息写入 internal logger 的监控器，命令文本经 redactor 脱敏（nil 时不脱敏），
+// 并按 maxStatement 截断（<=0 时不截断）；logged 返回 false 的命令不记录。
+func newLoggerMonitor(logger internal.Interface, redactor *redactor, maxStatement int, logged func(name string) bool) *event.CommandMonitor {
+	// cmds 用于缓存 RequestID 对应的命令信息，供结束事件读取。
+	var cmds sync.Map
+
+	return &event.CommandMonitor{
+		// Started 在命令开始时触发。
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if !logged(e.CommandName) {
+				return
+			}
+			cmds.Store(e.RequestID, internal.Command{
+				Name:       e.CommandName,
+				Database:   e.DatabaseName,
+				Collection: CommandCollection(e.Command, e.CommandName),
+				Statement:  truncateStatement(redactor.statement(e.Command), maxStatement),
+			})
+		},
+		// Succeeded 在命令成功时触发。
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			if !logged(e.CommandName) {
+				return
+			}
+			// 通过 RequestID 找到对应的命令信息，取出后删除，避免 map 增长。
+			cmd := loadCommand(&cmds, &e.CommandFinishedEvent)
+			cmd.Reply = replySummary(e.CommandName, e.Reply)
+			// 记录成功 Trace，err 字符串为空。
+			logger.Trace(ctx, e.RequestID, e.Duration, cmd, "")
+		},
+		// Failed 在命令失败时触发。
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			if !logged(e.CommandName) {
+				return
+			}
+			cmd := loadCommand(&cmds, &e.CommandFinishedEvent)
+			// 记录失败 Trace，err 为 driver 提供的失败信息。
+			if e.Failure != nil {
+				logger.Trace(ctx, e.RequestID, e.Duration, cmd, e.Failure.Error())
+			} else {
+				logger.Trace(ctx, e.RequestID, e.Duration, cmd, "")
+			}
+		},
+	}
+}
+
+// loadCommand 取出命令开始时缓存的信息；找不到时（如监控器安装前已发出的命令）只填充结束事件中的命令名与库名。
+func loadCommand(cmds *sync.Map, e *event.CommandFinishedEvent) internal.Command {
+	if v, ok := cmds.LoadAndDelete(e.RequestID); ok {
+		return v.(internal.Command)
+	}
+	return internal.Command{Name: e.CommandName, Database: e.DatabaseName}
+}
+
+// replySummary 从成功命令的回复中提取写入计数、批大小与游标 ID，没有可摘要的字段时返回 nil。
+func replySummary(name string, reply bson.Raw) *internal.Reply {
+	r := &internal.Reply{}
+	found := false
+	int64At := func(doc bson.Raw, key string) *int64 {
+		if v, err := doc.LookupErr(key); err == nil {
+			if n, ok := v.AsInt64OK(); ok {
+				found = true
+				return &n
+			}
+		}
+		return nil
+	}
+
+	switch strings.ToLower(name) {
+	case "insert":
+		r.Inserted = int64At(reply, "n")
+	case "update":
+		// n 包含 upsert 插入的文档，匹配数需扣除。
+		r.Matched = int64At(reply, "n")
+		r.Modified = int64At(reply, "nModified")
+		if v, err := reply.LookupErr("upserted"); err == nil {
+			if values, err := v.Array().Values(); err == nil {
+				upserted := int64(len(values))
+				r.Upserted = &upserted
+				if r.Matched != nil {
+					*r.Matched -= upserted
+				}
+			}
+		}
+	case "delete":
+		r.Deleted = int64At(reply, "n")
+	case "findandmodify":
+		if v, err := reply.LookupErr("lastErrorObject"); err == nil {
+			if doc, ok := v.DocumentOK(); ok {
+				r.Matched = int64At(doc, "n")
+				if _, err := doc.LookupErr("upserted"); err == nil && r.Matched != nil {
+					upserted := *r.Matched
+					r.Upserted, r.Matched = &upserted, new(int64)
+				}
+			}
+		}
+	}
+
+	if v, err := reply.LookupErr("cursor"); err == nil {
+		if cursor, ok := v.DocumentOK(); ok {
+			r.CursorId = int64At(cursor, "id")
+			for _, key := range []string{"firstBatch", "nextBatch"} {
+				if batch, err := cursor.LookupErr(key); err == nil {
+					if values, err := batch.Array().Values(); err == nil {
+						size := int64(len(values))
+						r.BatchSize, found = &size, true
+					}
+				}
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return r
+}
+
+// truncateStatement 将超过 max 字节的命令文本截断到不破坏 UTF-8 字符的位置，并追加原始大小标记。
+func truncateStatement(smt string, max int) string {
+	if max <= 0 || len(smt) <= max {
+		return smt
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(smt[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[truncated, %d bytes total]", smt[:cut], len(smt))
+}
+
+// commandFilter 返回命令日志的命令名过滤器：LogCommands 非空时只记录其中的命令（可包含维护类命令），
+// 否则跳过 LogSkipCommands 中的命令，以及未设置 IncludeHousekeeping 时的维护类命令。命令名不区分大小写。
+func (c *Conf) commandFilter() func(name string) bool {
+	allow := commandSet(c.LogCommands)
+	skip := commandSet(c.LogSkipCommands)
+	housekeeping := c.IncludeHousekeeping
+
+	return func(name string) bool {
+		name = strings.ToLower(name)
+		if len(allow) != 0 {
+			return allow[name]
+		}
+		if skip[name] {
+			return false
+		}
+		return housekeeping || !housekeepingCommands[name]
+	}
+}
+
+func commandSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return set
+}
+
+// chainCommandMonitors 将多个命令监控器按顺序串联为一个（nil 监控器与 nil 回调会被跳过）。
+func chainCommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	var list []*event.CommandMonitor
+	for _, m := range monitors {
+		if m != nil {
+			list = append(list, m)
+		}
+	}
+	if len(list) == 1 {
+		return list[0]
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range list {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range list {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range list {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
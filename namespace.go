@@ -0,0 +1,183 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrNamespaceDrift 表示模型声明的集合与数据库实际集合不一致。
+var ErrNamespaceDrift = errors.New("mongo: namespace drift detected")
+
+// CollectionType 为 listCollections 返回的集合类型。
+type CollectionType string
+
+const (
+	CollectionTypeCollection CollectionType = "collection" // CollectionTypeCollection 普通集合。
+	CollectionTypeView       CollectionType = "view"       // CollectionTypeView 视图。
+	CollectionTypeTimeseries CollectionType = "timeseries" // CollectionTypeTimeseries 时序集合。
+)
+
+// NameFilter 定义 ListCollections 的过滤条件，零值表示不过滤。
+type NameFilter struct {
+	// Names 为精确匹配的集合名列表。
+	Names []string
+	// Prefix 为集合名前缀。
+	Prefix string
+	// Type 为集合类型。
+	Type CollectionType
+	// IncludeSystem 控制是否包含 system.* 集合。
+	IncludeSystem bool
+}
+
+// CollectionInfo 为 listCollections 结果的类型化表示。
+type CollectionInfo struct {
+	Name     string         `json:"name"`
+	Type     CollectionType `json:"type"`
+	ReadOnly bool           `json:"read_only"`
+	// UUID 为集合 UUID 字符串，视图没有 UUID。
+	UUID string `json:"uuid"`
+	// ViewOn 为视图的源集合，仅 Type 为 view 时有值。
+	ViewOn string `json:"view_on"`
+	// Capped 表示是否为固定集合。
+	Capped bool `json:"capped"`
+	// Options 为创建集合时使用的原始选项。
+	Options bson.M `json:"options"`
+}
+
+// IsView 判断是否为视图。
+func (c *CollectionInfo) IsView() bool {
+	return c.Type == CollectionTypeView
+}
+
+// ListCollections 列出数据库中的集合并转换为 CollectionInfo。
+func ListCollections(ctx context.Context, db *mongo.Database, filter *NameFilter) ([]CollectionInfo, error) {
+	query := bson.D{}
+	if filter != nil {
+		if len(filter.Names) != 0 {
+			query = append(query, bson.E{Key: "name", Value: bson.D{{Key: "$in", Value: filter.Names}}})
+		}
+		if filter.Type != "" {
+			query = append(query, bson.E{Key: "type", Value: string(filter.Type)})
+		}
+	}
+
+	specs, err := db.ListCollectionSpecifications(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]CollectionInfo, 0, len(specs))
+	for _, spec := range specs {
+		if filter == nil || !filter.IncludeSystem {
+			if strings.HasPrefix(spec.Name, "system.") {
+				continue
+			}
+		}
+		if filter != nil && filter.Prefix != "" && !strings.HasPrefix(spec.Name, filter.Prefix) {
+			continue
+		}
+
+		info := CollectionInfo{
+			Name:     spec.Name,
+			Type:     CollectionType(spec.Type),
+			ReadOnly: spec.ReadOnly,
+			Options:  bson.M{},
+		}
+		if spec.UUID != nil {
+			if u, err := uuid.FromBytes(spec.UUID.Data); err == nil {
+				info.UUID = u.String()
+			}
+		}
+		if len(spec.Options) != 0 {
+			if err := bson.Unmarshal(spec.Options, &info.Options); err != nil {
+				return nil, err
+			}
+		}
+		if v, ok := info.Options["viewOn"].(string); ok {
+			info.ViewOn = v
+		}
+		if v, ok := info.Options["capped"].(bool); ok {
+			info.Capped = v
+		}
+
+		list = append(list, info)
+	}
+
+	return list, nil
+}
+
+// NamespaceExists 判断集合（或视图）是否存在。
+func NamespaceExists(ctx context.Context, db *mongo.Database, name string) (bool, error) {
+	names, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: name}})
+	if err != nil {
+		return false, err
+	}
+	return len(names) != 0, nil
+}
+
+// Namespacer 由模型实现，用于声明其所在集合。
+type Namespacer interface {
+	CollectionName() string
+}
+
+// NamespaceDrift 为 EnsureNamespacesMatch 的检查结果。
+type NamespaceDrift struct {
+	// Missing 为模型已声明但数据库中不存在的集合。
+	Missing []string `json:"missing"`
+	// Unmanaged 为数据库中存在但没有模型声明的集合。
+	Unmanaged []string `json:"unmanaged"`
+}
+
+// Error 实现 error 接口。
+func (d *NamespaceDrift) Error() string {
+	return fmt.Sprintf("%s: missing=%v unmanaged=%v", ErrNamespaceDrift, d.Missing, d.Unmanaged)
+}
+
+// Unwrap 使 errors.Is(err, ErrNamespaceDrift) 成立。
+func (d *NamespaceDrift) Unwrap() error {
+	return ErrNamespaceDrift
+}
+
+// EnsureNamespacesMatch 对比模型声明与数据库实际集合，存在缺失集合时返回 *NamespaceDrift。
+// Unmanaged 仅作为信息返回，不单独触发错误。
+func EnsureNamespacesMatch(ctx context.Context, db *mongo.Database, models ...Namespacer) (*NamespaceDrift, error) {
+	list, err := ListCollections(ctx, db, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]string, 0, len(list))
+	for _, info := range list {
+		existing = append(existing, info.Name)
+	}
+
+	declared := make([]string, 0, len(models))
+	for _, model := range models {
+		declared = append(declared, model.CollectionName())
+	}
+
+	drift := &NamespaceDrift{}
+	for _, name := range declared {
+		if !slices.Contains(existing, name) && !slices.Contains(drift.Missing, name) {
+			drift.Missing = append(drift.Missing, name)
+		}
+	}
+	for _, name := range existing {
+		if !slices.Contains(declared, name) {
+			drift.Unmanaged = append(drift.Unmanaged, name)
+		}
+	}
+
+	if len(drift.Missing) != 0 {
+		return drift, drift
+	}
+
+	return drift, nil
+}
@@ -0,0 +1,73 @@
+package oplog
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Checkpoint 负责持久化已处理到的 oplog 位点，用于断点续读。
+type Checkpoint interface {
+	// Load 返回上次保存的位点，未保存过时返回零值。
+	Load(ctx context.Context) (bson.Timestamp, error)
+	// Save 保存最新处理完成的位点。
+	Save(ctx context.Context, ts bson.Timestamp) error
+}
+
+// MemoryCheckpoint 为进程内位点，适用于测试或无需跨进程续读的场景。
+type MemoryCheckpoint struct {
+	mu sync.Mutex
+	ts bson.Timestamp
+}
+
+// Load 实现 Checkpoint。
+func (m *MemoryCheckpoint) Load(_ context.Context) (bson.Timestamp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ts, nil
+}
+
+// Save 实现 Checkpoint。
+func (m *MemoryCheckpoint) Save(_ context.Context, ts bson.Timestamp) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ts = ts
+	return nil
+}
+
+// CollectionCheckpoint 将位点保存在指定集合中，以 Id 区分不同的消费者。
+type CollectionCheckpoint struct {
+	Collection *mongo.Collection
+	Id         string
+}
+
+// NewCollectionCheckpoint 创建基于集合的位点存储。
+func NewCollectionCheckpoint(collection *mongo.Collection, id string) *CollectionCheckpoint {
+	return &CollectionCheckpoint{Collection: collection, Id: id}
+}
+
+// Load 实现 Checkpoint。
+func (c *CollectionCheckpoint) Load(ctx context.Context) (bson.Timestamp, error) {
+	var doc struct {
+		Ts bson.Timestamp `bson:"ts"`
+	}
+	err := c.Collection.FindOne(ctx, bson.D{{Key: "_id", Value: c.Id}}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return bson.Timestamp{}, nil
+	}
+	return doc.Ts, err
+}
+
+// Save 实现 Checkpoint。
+func (c *CollectionCheckpoint) Save(ctx context.Context, ts bson.Timestamp) error {
+	_, err := c.Collection.UpdateOne(ctx, bson.D{
+		{Key: "_id", Value: c.Id},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "ts", Value: ts}}},
+	}, options.UpdateOne().SetUpsert(true))
+	return err
+}
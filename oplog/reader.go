@@ -0,0 +1,391 @@
+package oplog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrSharded 表示连接的是 mongos（分片集群或负载均衡模式），mongos 上没有 local.oplog.rs，需直连各分片副本集读取。
+var ErrSharded = errors.New("oplog: sharded topology has no oplog, connect to a shard replica set directly")
+
+// ErrOplogRollover 表示位点对应的条目已被 oplog 覆盖，位点之后的部分条目已丢失，需要全量重建后从新位点开始。
+var ErrOplogRollover = errors.New("oplog: checkpoint has rolled off the oplog")
+
+// ErrPreparedTransaction 表示遇到了两阶段提交（prepare）的事务条目，只出现在分片集群的跨分片事务中，暂不支持展开。
+var ErrPreparedTransaction = errors.New("oplog: prepared transactions are not supported")
+
+// OpType 为 oplog 条目的操作类型。
+type OpType string
+
+const (
+	OpInsert  OpType = "i" // OpInsert 插入。
+	OpUpdate  OpType = "u" // OpUpdate 更新。
+	OpDelete  OpType = "d" // OpDelete 删除。
+	OpCommand OpType = "c" // OpCommand 命令（建删集合、事务 applyOps 等）。
+	OpNoop    OpType = "n" // OpNoop 空操作。
+)
+
+// Entry 为 local.oplog.rs 中单条记录的类型化表示。
+type Entry struct {
+	Timestamp bson.Timestamp `bson:"ts"`
+	Term      int64          `bson:"t"`
+	Op        OpType         `bson:"op"`
+	Namespace string         `bson:"ns"`
+	Wall      time.Time      `bson:"wall"`
+	// Object 为操作内容：插入的文档、更新描述或删除条件。
+	Object bson.Raw `bson:"o"`
+	// Object2 为更新操作的定位条件（通常包含 _id）。
+	Object2 bson.Raw `bson:"o2,omitempty"`
+}
+
+// Database 返回条目所属的库名。
+func (e *Entry) Database() string {
+	db, _, _ := strings.Cut(e.Namespace, ".")
+	return db
+}
+
+// Collection 返回条目所属的集合名。
+func (e *Entry) Collection() string {
+	_, coll, _ := strings.Cut(e.Namespace, ".")
+	return coll
+}
+
+// DocumentId 返回条目关联文档的 _id（插入/删除取 o._id，更新取 o2._id）。
+func (e *Entry) DocumentId() bson.RawValue {
+	if e.Op == OpUpdate && e.Object2 != nil {
+		return e.Object2.Lookup("_id")
+	}
+	if e.Object != nil {
+		return e.Object.Lookup("_id")
+	}
+	return bson.RawValue{}
+}
+
+// Conf 为 Reader 的配置。
+type Conf struct {
+	// Namespaces 为需要订阅的命名空间，支持 "db.coll" 精确匹配与 "db.*" 整库匹配，为空表示全部。
+	Namespaces []string
+	// Ops 为需要订阅的操作类型，为空时默认 insert/update/delete。
+	// 多文档事务的写入记录在 admin.$cmd 的 applyOps 命令中，Reader 会将其展开为内部的各条操作后再按 Ops 与 Namespaces 过滤，
+	// 展开的条目沿用事务条目的 ts/t/wall；applyOps 本身不会交给 handler。
+	Ops []OpType
+	// Checkpoint 为位点存储，为空时使用 MemoryCheckpoint。
+	Checkpoint Checkpoint
+	// BatchSize 为游标批大小（<=0 时使用 driver 默认值）。
+	BatchSize int32
+	// MaxAwaitTime 为 tailable 游标等待新数据的最长时间（<=0 时默认 1s）。
+	MaxAwaitTime time.Duration
+	// RetryInterval 为游标失效后重新建立的间隔（<=0 时默认 1s）。
+	RetryInterval time.Duration
+}
+
+// Handler 处理单条 oplog 记录，返回错误时 Run 停止且不保存该条位点。
+type Handler func(ctx context.Context, entry *Entry) error
+
+// Reader 以 tailable 游标持续读取 local.oplog.rs。
+type Reader struct {
+	collection *mongo.Collection
+	conf       Conf
+}
+
+// NewReader 创建 oplog 读取器。
+func NewReader(client *mongo.Client, conf *Conf) *Reader {
+	c := Conf{}
+	if conf != nil {
+		c = *conf
+	}
+	if len(c.Ops) == 0 {
+		c.Ops = []OpType{OpInsert, OpUpdate, OpDelete}
+	}
+	if c.Checkpoint == nil {
+		c.Checkpoint = &MemoryCheckpoint{}
+	}
+	if c.MaxAwaitTime <= 0 {
+		c.MaxAwaitTime = time.Second
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = time.Second
+	}
+
+	return &Reader{
+		collection: client.Database("local").Collection("oplog.rs"),
+		conf:       c,
+	}
+}
+
+// Run 从位点开始读取 oplog 并调用 handler，直到 ctx 取消或 handler 返回错误。
+// 位点为空时从当前最新的 oplog 开始，不回放历史记录。启动与每次重建游标时校验位点条目仍在 oplog 中，
+// 已被覆盖时返回 ErrOplogRollover，而不是静默跳过丢失的条目。
+// 单节点部署没有 oplog，返回 gomongo.ErrRequiresReplicaSet；连接 mongos 时返回 ErrSharded；
+// 遇到跨分片事务的 prepare 条目时返回 ErrPreparedTransaction。
+func (r *Reader) Run(ctx context.Context, handler Handler) error {
+	info, err := gomongo.Capabilities(ctx, r.collection.Database().Client())
+	if err != nil {
+		return err
+	}
+	switch info.Topology {
+	case gomongo.TopologyStandalone:
+		return gomongo.ErrRequiresReplicaSet
+	case gomongo.TopologySharded, gomongo.TopologyLoadBalanced:
+		return ErrSharded
+	}
+
+	ts, err := r.conf.Checkpoint.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if ts.IsZero() {
+		if ts, err = r.latest(ctx); err != nil {
+			return err
+		}
+	}
+
+	for {
+		ts, err = r.tail(ctx, ts, handler)
+		if err != nil {
+			return err
+		}
+
+		// 游标失效（例如主从切换或空集合），等待后从最新位点重新建立。
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.conf.RetryInterval):
+		}
+	}
+}
+
+func (r *Reader) tail(ctx context.Context, ts bson.Timestamp, handler Handler) (bson.Timestamp, error) {
+	opts := options.Find().
+		SetCursorType(options.TailableAwait).
+		SetMaxAwaitTime(r.conf.MaxAwaitTime).
+		SetNoCursorTimeout(true)
+	if r.conf.BatchSize > 0 {
+		opts.SetBatchSize(r.conf.BatchSize)
+	}
+
+	cursor, err := r.collection.Find(ctx, r.filter(ts), opts)
+	if err != nil {
+		return ts, err
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	// 位点非空时查询包含位点条目本身，第一条必须是它，否则位点已被覆盖。
+	verify := !ts.IsZero()
+	// txn 为尚未结束的大事务（partialTxn）已展开的条目，游标重建时丢弃并从事务之前的位点重新读取。
+	var txn []Entry
+	for {
+		if cursor.TryNext(ctx) {
+			var entry Entry
+			if err := cursor.Decode(&entry); err != nil {
+				return ts, err
+			}
+			if verify {
+				verify = false
+				if entry.Timestamp != ts {
+					return ts, fmt.Errorf("%w: checkpoint %d.%d, oldest available %d.%d", ErrOplogRollover, ts.T, ts.I, entry.Timestamp.T, entry.Timestamp.I)
+				}
+				continue
+			}
+
+			entries, done, err := r.expand(&entry, txn)
+			if err != nil {
+				return ts, err
+			}
+			if !done {
+				txn = entries
+				continue
+			}
+			txn = nil
+			for i := range entries {
+				if err := handler(ctx, &entries[i]); err != nil {
+					return ts, err
+				}
+			}
+			if err := r.conf.Checkpoint.Save(ctx, entry.Timestamp); err != nil {
+				return ts, err
+			}
+			ts = entry.Timestamp
+			continue
+		}
+
+		if err := cursor.Err(); err != nil {
+			if ctx.Err() != nil {
+				return ts, ctx.Err()
+			}
+			// 可恢复的游标错误交由 Run 重建，其余错误直接返回。
+			if resumable(err) {
+				return ts, nil
+			}
+			return ts, err
+		}
+		if cursor.ID() == 0 {
+			return ts, nil
+		}
+		if ctx.Err() != nil {
+			return ts, ctx.Err()
+		}
+	}
+}
+
+// resumableCodes 为游标失效后可重新建立的服务端错误码。CappedPositionLost（136）表示游标位置已被覆盖，
+// 不可恢复，直接返回给调用方。
+var resumableCodes = []int{
+	43,    // CursorNotFound
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	10107, // NotWritablePrimary
+	11601, // Interrupted
+	13435, // NotPrimaryNoSecondaryOk
+}
+
+func resumable(err error) bool {
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+	for _, code := range resumableCodes {
+		if se.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// latest 返回当前最新的 oplog 时间戳。
+func (r *Reader) latest(ctx context.Context) (bson.Timestamp, error) {
+	var entry Entry
+	err := r.collection.FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "$natural", Value: -1}})).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return bson.Timestamp{}, nil
+	}
+	return entry.Timestamp, err
+}
+
+// applyOps 为事务条目（admin.$cmd 上的 applyOps 命令）的内容。
+type applyOps struct {
+	Ops        []Entry `bson:"applyOps"`
+	PartialTxn bool    `bson:"partialTxn"`
+	Prepare    bool    `bson:"prepare"`
+}
+
+// expand 返回 entry 对应的待处理条目：普通条目原样返回（不匹配时为空），事务条目展开为匹配的内部操作并追加到 txn 之后。
+// done 为 false 表示大事务尚未结束（partialTxn），调用方需保留返回的条目等待后续分段。
+func (r *Reader) expand(entry *Entry, txn []Entry) ([]Entry, bool, error) {
+	if entry.Op != OpCommand || entry.Namespace != "admin.$cmd" {
+		if !r.matches(entry) {
+			return nil, true, nil
+		}
+		return []Entry{*entry}, true, nil
+	}
+	if _, err := entry.Object.LookupErr("applyOps"); err != nil {
+		if !r.matches(entry) {
+			return nil, true, nil
+		}
+		return []Entry{*entry}, true, nil
+	}
+
+	var ops applyOps
+	if err := bson.Unmarshal(entry.Object, &ops); err != nil {
+		return nil, false, err
+	}
+	if ops.Prepare {
+		return nil, false, ErrPreparedTransaction
+	}
+	for _, op := range ops.Ops {
+		op.Timestamp, op.Term, op.Wall = entry.Timestamp, entry.Term, entry.Wall
+		if r.matches(&op) {
+			txn = append(txn, op)
+		}
+	}
+	return txn, !ops.PartialTxn, nil
+}
+
+// matches 判断条目是否符合 Ops 与 Namespaces，与 filter 的服务端条件一致。
+func (r *Reader) matches(entry *Entry) bool {
+	if !slices.Contains(r.conf.Ops, entry.Op) {
+		return false
+	}
+	if len(r.conf.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range r.conf.Namespaces {
+		if db, ok := strings.CutSuffix(ns, ".*"); ok {
+			if entry.Database() == db {
+				return true
+			}
+			continue
+		}
+		if entry.Namespace == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// filter 返回 ts 之后的查询条件：符合 Ops 与 Namespaces 的条目，以及订阅了增删改时的事务条目。
+// ts 非空时同时匹配位点条目本身，用于校验位点是否已被覆盖。
+func (r *Reader) filter(ts bson.Timestamp) bson.D {
+	match := bson.A{r.opsFilter()}
+	if slices.ContainsFunc(r.conf.Ops, func(op OpType) bool { return op == OpInsert || op == OpUpdate || op == OpDelete }) {
+		match = append(match, bson.D{
+			{Key: "op", Value: OpCommand},
+			{Key: "ns", Value: "admin.$cmd"},
+			{Key: "o.applyOps", Value: bson.D{{Key: "$exists", Value: true}}},
+		})
+	}
+	if ts.IsZero() {
+		return bson.D{
+			{Key: "ts", Value: bson.D{{Key: "$gt", Value: ts}}},
+			{Key: "$or", Value: match},
+		}
+	}
+	return bson.D{
+		{Key: "ts", Value: bson.D{{Key: "$gte", Value: ts}}},
+		{Key: "$or", Value: append(bson.A{bson.D{{Key: "ts", Value: ts}}}, match...)},
+	}
+}
+
+// opsFilter 返回 Ops 与 Namespaces 对应的条件。
+func (r *Reader) opsFilter() bson.D {
+	filter := bson.D{
+		{Key: "op", Value: bson.D{{Key: "$in", Value: r.conf.Ops}}},
+	}
+
+	if len(r.conf.Namespaces) != 0 {
+		var exact []string
+		var dbs []string
+		for _, ns := range r.conf.Namespaces {
+			if db, ok := strings.CutSuffix(ns, ".*"); ok {
+				dbs = append(dbs, regexp.QuoteMeta(db))
+				continue
+			}
+			exact = append(exact, ns)
+		}
+
+		var or bson.A
+		if len(exact) != 0 {
+			or = append(or, bson.D{{Key: "ns", Value: bson.D{{Key: "$in", Value: exact}}}})
+		}
+		if len(dbs) != 0 {
+			slices.Sort(dbs)
+			or = append(or, bson.D{{Key: "ns", Value: bson.Regex{Pattern: "^(" + strings.Join(dbs, "|") + `)\.`}}})
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: or})
+	}
+
+	return filter
+}
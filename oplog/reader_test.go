@@ -0,0 +1,115 @@
+package oplog
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func rawDoc(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func txnEntry(t *testing.T, extra bson.D, ops ...bson.D) *Entry {
+	list := bson.A{}
+	for _, op := range ops {
+		list = append(list, op)
+	}
+	o := append(bson.D{{Key: "applyOps", Value: list}}, extra...)
+	return &Entry{
+		Timestamp: bson.Timestamp{T: 100, I: 1},
+		Op:        OpCommand,
+		Namespace: "admin.$cmd",
+		Object:    rawDoc(t, o),
+	}
+}
+
+func TestExpand(t *testing.T) {
+	r := &Reader{conf: Conf{Namespaces: []string{"app.users", "audit.*"}, Ops: []OpType{OpInsert, OpUpdate, OpDelete}}}
+
+	insert := bson.D{{Key: "op", Value: "i"}, {Key: "ns", Value: "app.users"}, {Key: "o", Value: bson.D{{Key: "_id", Value: 1}}}}
+	other := bson.D{{Key: "op", Value: "i"}, {Key: "ns", Value: "app.orders"}, {Key: "o", Value: bson.D{{Key: "_id", Value: 2}}}}
+	audit := bson.D{{Key: "op", Value: "d"}, {Key: "ns", Value: "audit.logs"}, {Key: "o", Value: bson.D{{Key: "_id", Value: 3}}}}
+
+	tests := []struct {
+		name  string
+		entry *Entry
+		txn   []Entry
+		want  []string
+		done  bool
+		err   error
+	}{
+		{
+			name:  "plain entry",
+			entry: &Entry{Op: OpInsert, Namespace: "app.users"},
+			want:  []string{"app.users"},
+			done:  true,
+		},
+		{
+			name:  "plain entry outside namespaces",
+			entry: &Entry{Op: OpInsert, Namespace: "app.orders"},
+			done:  true,
+		},
+		{
+			name:  "command outside ops",
+			entry: &Entry{Op: OpCommand, Namespace: "admin.$cmd", Object: rawDoc(t, bson.D{{Key: "create", Value: "x"}})},
+			done:  true,
+		},
+		{
+			name:  "transaction",
+			entry: txnEntry(t, nil, insert, other, audit),
+			want:  []string{"app.users", "audit.logs"},
+			done:  true,
+		},
+		{
+			name:  "partial transaction",
+			entry: txnEntry(t, bson.D{{Key: "partialTxn", Value: true}}, insert),
+			want:  []string{"app.users"},
+			done:  false,
+		},
+		{
+			name:  "last segment of a partial transaction",
+			entry: txnEntry(t, nil, audit),
+			txn:   []Entry{{Op: OpInsert, Namespace: "app.users"}},
+			want:  []string{"app.users", "audit.logs"},
+			done:  true,
+		},
+		{
+			name:  "prepared transaction",
+			entry: txnEntry(t, bson.D{{Key: "prepare", Value: true}}, insert),
+			err:   ErrPreparedTransaction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, done, err := r.expand(tt.entry, tt.txn)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if done != tt.done {
+				t.Fatalf("done = %v, want %v", done, tt.done)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, e := range got {
+				if e.Namespace != tt.want[i] {
+					t.Errorf("entry %d ns = %q, want %q", i, e.Namespace, tt.want[i])
+				}
+				if tt.txn == nil && tt.entry.Namespace == "admin.$cmd" && e.Timestamp != tt.entry.Timestamp {
+					t.Errorf("entry %d ts = %v, want the transaction ts", i, e.Timestamp)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package mongo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/fireflycore/go-utils/tlsx"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Option 为 NewWithOptions 的配置项，修改的是内部构造的 Conf。
+// 新能力以 Option 的形式提供，不影响已有调用方；也可以自行实现 func(*Conf) 直接修改 Conf。
+type Option func(c *Conf)
+
+// NewWithOptions 以地址、数据库名与配置项创建连接并返回数据库句柄，等价于构造 Conf 后调用 New。
+func NewWithOptions(address, database string, opts ...Option) (*mongo.Database, error) {
+	return New(NewConf(address, database, opts...))
+}
+
+// NewClientWithOptions 与 NewWithOptions 相同，返回 Client；建立连接受 ctx 控制。
+func NewClientWithOptions(ctx context.Context, address, database string, opts ...Option) (*Client, error) {
+	return NewClientWithContext(ctx, NewConf(address, database, opts...))
+}
+
+// NewConf 根据地址、数据库名与配置项构造 Conf，可在传给 New 前继续修改。
+func NewConf(address, database string, opts ...Option) *Conf {
+	c := &Conf{Address: address, Database: database}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+// WithAuth 设置用户名与密码，authSource 为空时使用 driver 默认值。
+func WithAuth(username, password, authSource string) Option {
+	return func(c *Conf) {
+		c.Username = username
+		c.Password = password
+		c.AuthSource = authSource
+	}
+}
+
+// WithAuthMechanism 设置认证机制与附加属性。
+func WithAuthMechanism(mechanism string, properties map[string]string) Option {
+	return func(c *Conf) {
+		c.AuthMechanism = mechanism
+		c.AuthMechanismProperties = properties
+	}
+}
+
+// WithTLS 设置 TLS 配置。
+func WithTLS(tls *tlsx.TLS) Option {
+	return func(c *Conf) {
+		c.Tls = tls
+	}
+}
+
+// WithLogger 启用命令监控日志，console 控制是否同时输出到控制台。
+func WithLogger(console bool) Option {
+	return func(c *Conf) {
+		c.Logger = true
+		c.WithLoggerConsole(console)
+	}
+}
+
+// WithColorful 强制开启或关闭控制台命令日志的 ANSI 颜色，未设置时按终端与 NO_COLOR 自动检测。
+func WithColorful(state bool) Option {
+	return func(c *Conf) {
+		c.Colorful = &state
+	}
+}
+
+// WithSlowThreshold 设置慢查询阈值，命令耗时超过该值时按 warn 输出；为 0 时使用 DefaultSlowThreshold，为负数时不检测。
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(c *Conf) {
+		c.SlowThreshold = threshold
+	}
+}
+
+// WithLogSampleRate 设置成功且非慢查询命令的日志采样比例，错误与慢查询始终记录。
+func WithLogSampleRate(rate float64) Option {
+	return func(c *Conf) {
+		c.LogSampleRate = rate
+	}
+}
+
+// WithLogCommands 设置命令日志只记录的命令名（如 "find"、"aggregate"、"update"）。
+func WithLogCommands(names ...string) Option {
+	return func(c *Conf) {
+		c.LogCommands = names
+	}
+}
+
+// WithLogSkipCommands 设置命令日志跳过的命令名（如 "getMore"）。
+func WithLogSkipCommands(names ...string) Option {
+	return func(c *Conf) {
+		c.LogSkipCommands = names
+	}
+}
+
+// WithLogHandlers 启用命令日志并追加接收结构化日志的 handler。
+func WithLogHandlers(handlers ...LogHandler) Option {
+	return func(c *Conf) {
+		c.Logger = true
+		c.WithLogHandler(handlers...)
+	}
+}
+
+// WithSlog 启用命令监控日志并写入 slog logger，替代控制台输出。
+func WithSlog(logger *slog.Logger) Option {
+	return func(c *Conf) {
+		c.Logger = true
+		c.WithSlog(logger)
+	}
+}
+
+// WithPool 设置连接池最大连接数与连接最大空闲时间，<=0 的参数不生效。
+func WithPool(maxOpen int, maxIdle time.Duration) Option {
+	return func(c *Conf) {
+		c.MaxOpenConnects = maxOpen
+		c.ConnMaxLifeTime = int(maxIdle / time.Second)
+	}
+}
+
+// WithReplicaSet 设置多节点地址与副本集名称，addresses 非空时优先于 address。
+func WithReplicaSet(name string, addresses ...string) Option {
+	return func(c *Conf) {
+		c.ReplicaSet = name
+		c.Addresses = addresses
+	}
+}
+
+// WithReadPreference 设置读偏好模式与节点标签集合。
+func WithReadPreference(mode string, tags ...map[string]string) Option {
+	return func(c *Conf) {
+		c.ReadPreference = mode
+		c.ReadPreferenceTags = tags
+	}
+}
+
+// WithTimeout 设置客户端级操作超时。
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Conf) {
+		c.Timeout = int(timeout / time.Millisecond)
+	}
+}
+
+// WithLazyConnect 跳过启动 Ping。
+func WithLazyConnect() Option {
+	return func(c *Conf) {
+		c.LazyConnect = true
+	}
+}
+
+// WithAppName 设置上报给服务端的客户端名称。
+func WithAppName(name string) Option {
+	return func(c *Conf) {
+		c.AppName = name
+	}
+}
+
+// WithMonitors 追加命令监控器。
+func WithMonitors(monitors ...*event.CommandMonitor) Option {
+	return func(c *Conf) {
+		for _, m := range monitors {
+			c.WithCommandMonitor(m)
+		}
+	}
+}
+
+// WithCodecs 追加自定义类型的编解码注册回调。
+func WithCodecs(register ...func(reg *bson.Registry)) Option {
+	return func(c *Conf) {
+		c.WithCodecs(register...)
+	}
+}
+
+// WithSecrets 设置敏感信息提供者。
+func WithSecrets(provider SecretsProvider) Option {
+	return func(c *Conf) {
+		c.WithSecretsProvider(provider)
+	}
+}
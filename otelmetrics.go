@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// 命令指标名称。
+const (
+	MetricCommandDuration = "mongo.client.command.duration" // MetricCommandDuration 命令耗时直方图（秒）。
+	MetricCommandErrors   = "mongo.client.command.errors"   // MetricCommandErrors 失败命令计数。
+	MetricCommandInflight = "mongo.client.command.inflight" // MetricCommandInflight 执行中的命令数。
+)
+
+// meterName 为本包注册指标使用的 Meter 名称。
+const meterName = "github.com/fireflycore/go-mongo"
+
+// commandDurationBuckets 为命令耗时直方图的桶边界（秒）。
+var commandDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// CommandMetricsConf 为命令级 OpenTelemetry 指标配置，零值为启用。
+type CommandMetricsConf struct {
+	// Disabled 为 true 时不记录指标。
+	Disabled bool `json:"disabled"`
+}
+
+// WithMeterProvider 设置记录命令指标使用的 MeterProvider，未设置时使用全局 provider。
+func (c *Conf) WithMeterProvider(provider metric.MeterProvider) {
+	c.meterProvider = provider
+}
+
+// commandKey 唯一标识一条执行中的命令。
+type commandKey struct {
+	connectionId string
+	requestId    int64
+}
+
+// commandMetrics 在命令监控回调中记录耗时、错误与执行中命令数，
+// 标签为 db.namespace、db.collection.name 与 db.operation.name。
+type commandMetrics struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	inflight metric.Int64UpDownCounter
+
+	// housekeeping 为 false 时不记录维护类命令。
+	housekeeping bool
+
+	// started 保存命令开始时的标签，结束时复用（成功/失败事件不包含集合名）。
+	started sync.Map
+}
+
+// metricsMonitor 构造记录命令指标的监控器，CommandMetrics.Disabled 时返回 nil。
+func (c *Conf) metricsMonitor() (*event.CommandMonitor, error) {
+	if c.CommandMetrics != nil && c.CommandMetrics.Disabled {
+		return nil, nil
+	}
+
+	provider := c.meterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(meterName)
+
+	m := &commandMetrics{housekeeping: c.IncludeHousekeeping}
+	var err error
+	if m.duration, err = meter.Float64Histogram(MetricCommandDuration,
+		metric.WithDescription("Duration of MongoDB commands."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(commandDurationBuckets...),
+	); err != nil {
+		return nil, err
+	}
+	if m.errors, err = meter.Int64Counter(MetricCommandErrors,
+		metric.WithDescription("Number of failed MongoDB commands."),
+		metric.WithUnit("{command}"),
+	); err != nil {
+		return nil, err
+	}
+	if m.inflight, err = meter.Int64UpDownCounter(MetricCommandInflight,
+		metric.WithDescription("Number of MongoDB commands in flight."),
+		metric.WithUnit("{command}"),
+	); err != nil {
+		return nil, err
+	}
+
+	return &event.CommandMonitor{
+		Started:   m.onStarted,
+		Succeeded: m.onSucceeded,
+		Failed:    m.onFailed,
+	}, nil
+}
+
+func (m *commandMetrics) onStarted(ctx context.Context, e *event.CommandStartedEvent) {
+	// 未登记开始事件的命令在 finish 中同样被跳过。
+	if !m.housekeeping && IsHousekeepingCommand(e.CommandName) {
+		return
+	}
+	attrs := metric.WithAttributeSet(attribute.NewSet(
+		attribute.String("db.system.name", "mongodb"),
+		attribute.String("db.namespace", e.DatabaseName),
+		attribute.String("db.collection.name", CommandCollection(e.Command, e.CommandName)),
+		attribute.String("db.operation.name", e.CommandName),
+	))
+	m.started.Store(commandKey{e.ConnectionID, e.RequestID}, attrs)
+	m.inflight.Add(ctx, 1, attrs)
+}
+
+func (m *commandMetrics) onSucceeded(ctx context.Context, e *event.CommandSucceededEvent) {
+	if attrs, ok := m.finish(ctx, &e.CommandFinishedEvent); ok {
+		m.duration.Record(ctx, e.Duration.Seconds(), attrs)
+	}
+}
+
+func (m *commandMetrics) onFailed(ctx context.Context, e *event.CommandFailedEvent) {
+	if attrs, ok := m.finish(ctx, &e.CommandFinishedEvent); ok {
+		m.duration.Record(ctx, e.Duration.Seconds(), attrs)
+		m.errors.Add(ctx, 1, attrs, metric.WithAttributes(attribute.String("error.type", errorType(e.Failure))))
+	}
+}
+
+// finish 取出命令开始时的标签并减少执行中命令数；找不到开始事件时（如监控器安装前已发出的命令）返回 false。
+func (m *commandMetrics) finish(ctx context.Context, e *event.CommandFinishedEvent) (metric.MeasurementOption, bool) {
+	v, ok := m.started.LoadAndDelete(commandKey{e.ConnectionID, e.RequestID})
+	if !ok {
+		return nil, false
+	}
+	attrs := v.(metric.MeasurementOption)
+	m.inflight.Add(ctx, -1, attrs)
+	return attrs, true
+}
+
+// errorType 返回错误分类：服务端错误使用错误码名称（如 NotWritablePrimary），其次为 timeout、network，其余为 other。
+func errorType(err error) string {
+	var driverErr driver.Error
+	switch {
+	case errors.As(err, &driverErr) && driverErr.Name != "":
+		return driverErr.Name
+	case mongo.IsTimeout(err):
+		return "timeout"
+	case mongo.IsNetworkError(err):
+		return "network"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,493 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gomongo "github.com/fireflycore/go-mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrMissingKey 表示写入的文档缺少分区字段。
+var ErrMissingKey = errors.New("partition: document is missing the partition key")
+
+// Mode 为分区方式。
+type Mode uint8
+
+const (
+	// ModeTime 按时间字段分区，例如 events_2024_05。
+	ModeTime Mode = iota
+	// ModeHash 按字段哈希分区，例如 events_h03。
+	ModeHash
+)
+
+// Period 为时间分区的粒度。
+type Period uint8
+
+const (
+	// PeriodMonth 按月分区（base_2006_01）。
+	PeriodMonth Period = iota
+	// PeriodDay 按天分区（base_2006_01_02）。
+	PeriodDay
+)
+
+// Conf 为分区配置。
+type Conf struct {
+	// Base 为分区集合名前缀，例如 events。
+	Base string
+	// Mode 为分区方式。
+	Mode Mode
+
+	// TimeField 为时间分区字段（为空时默认 created_at）。
+	TimeField string
+	// Period 为时间分区粒度。
+	Period Period
+	// Retention 为保留的时间分区数量（含当前分区），<=0 表示不自动淘汰。
+	Retention int
+
+	// HashField 为哈希分区字段（为空时默认 _id）。
+	HashField string
+	// Buckets 为哈希分区数量（<=0 时默认 16）。
+	Buckets int
+
+	// Indexes 为新建分区时创建的索引。
+	Indexes []mongo.IndexModel
+}
+
+// Partitioner 将读写路由到按时间或哈希划分的分区集合，并负责分区的创建与淘汰。
+type Partitioner struct {
+	db      *mongo.Database
+	conf    Conf
+	created sync.Map
+}
+
+// New 创建 Partitioner。
+func New(db *mongo.Database, conf *Conf) (*Partitioner, error) {
+	if conf == nil || conf.Base == "" {
+		return nil, errors.New("partition: base collection name is required")
+	}
+
+	c := *conf
+	if c.TimeField == "" {
+		c.TimeField = "created_at"
+	}
+	if c.HashField == "" {
+		c.HashField = "_id"
+	}
+	if c.Buckets <= 0 {
+		c.Buckets = 16
+	}
+
+	return &Partitioner{db: db, conf: c}, nil
+}
+
+// layout 返回时间分区名中的日期格式。
+func (p *Partitioner) layout() string {
+	if p.conf.Period == PeriodDay {
+		return "2006_01_02"
+	}
+	return "2006_01"
+}
+
+// TimeName 返回时间 t 所在分区的集合名。
+func (p *Partitioner) TimeName(t time.Time) string {
+	return p.conf.Base + "_" + t.UTC().Format(p.layout())
+}
+
+// HashName 返回哈希值 key 所在分区的集合名。key 按 BSON 编码后哈希，与 Route 从文档中读取的字段值一致
+// （如 bson.ObjectID 与文档中的 _id 落入同一分区）；整型统一按 int64 哈希，int 与 int32/int64 字段互相匹配。
+func (p *Partitioner) HashName(key any) string {
+	value, ok := key.(bson.RawValue)
+	if !ok {
+		t, data, err := bson.MarshalValue(key)
+		if err != nil {
+			// 无法编码的值不会出现在文档中，按文本哈希即可。
+			t, data, _ = bson.MarshalValue(fmt.Sprint(key))
+		}
+		value = bson.RawValue{Type: t, Value: data}
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(hashBytes(value))
+	return fmt.Sprintf("%s_h%02d", p.conf.Base, int(h.Sum32()%uint32(p.conf.Buckets)))
+}
+
+// Route 返回文档应写入的分区集合名。
+func (p *Partitioner) Route(doc any) (string, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	if p.conf.Mode == ModeHash {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(p.conf.HashField, ".")...)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrMissingKey, p.conf.HashField)
+		}
+		return p.HashName(value), nil
+	}
+
+	value, err := bson.Raw(raw).LookupErr(strings.Split(p.conf.TimeField, ".")...)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrMissingKey, p.conf.TimeField)
+	}
+	dt, ok := value.DateTimeOK()
+	if !ok {
+		return "", fmt.Errorf("%w: %s is not a datetime", ErrMissingKey, p.conf.TimeField)
+	}
+	return p.TimeName(time.UnixMilli(dt)), nil
+}
+
+// Collection 返回分区集合，首次访问时创建集合与索引。
+func (p *Partitioner) Collection(ctx context.Context, name string) (*mongo.Collection, error) {
+	collection := p.db.Collection(name)
+	if _, ok := p.created.Load(name); ok {
+		return collection, nil
+	}
+
+	if len(p.conf.Indexes) != 0 {
+		if _, err := collection.Indexes().CreateMany(ctx, p.conf.Indexes); err != nil {
+			return nil, err
+		}
+	} else if err := p.db.CreateCollection(ctx, name); err != nil && !isNamespaceExists(err) {
+		return nil, err
+	}
+
+	p.created.Store(name, struct{}{})
+	return collection, nil
+}
+
+// InsertOne 将文档写入其所在分区，文档实现 BeforeInsert 时会先调用该方法；写入以 insert 操作经过拦截器，并按分区集合的访问策略校验（见 mongo.CheckInsert）。
+func (p *Partitioner) InsertOne(ctx context.Context, doc any) (_ *mongo.InsertOneResult, err error) {
+	if bi, ok := doc.(interface{ BeforeInsert() }); ok {
+		bi.BeforeInsert()
+	}
+
+	name, err := p.Route(doc)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := p.Collection(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &gomongo.Operation{Name: "insert", Documents: []any{doc}}
+	ctx, finish, err := gomongo.Intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+	if len(op.Documents) != 1 {
+		return nil, errors.New("partition: interceptor changed the number of documents")
+	}
+	if err := gomongo.CheckInsert(ctx, gomongo.PolicyNamespace(collection), op.Documents[0]); err != nil {
+		return nil, err
+	}
+
+	return collection.InsertOne(ctx, op.Documents[0])
+}
+
+// Partitions 返回数据库中已存在的分区集合名（按名称升序，时间分区即按时间升序）。
+func (p *Partitioner) Partitions(ctx context.Context) ([]string, error) {
+	infos, err := gomongo.ListCollections(ctx, p.db, &gomongo.NameFilter{Prefix: p.conf.Base + "_"})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, info := range infos {
+		if p.owns(info.Name) {
+			names = append(names, info.Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// owns 判断集合名是否为本分区方案生成的分区。
+func (p *Partitioner) owns(name string) bool {
+	suffix, ok := strings.CutPrefix(name, p.conf.Base+"_")
+	if !ok {
+		return false
+	}
+	if p.conf.Mode == ModeHash {
+		n, ok := strings.CutPrefix(suffix, "h")
+		if !ok {
+			return false
+		}
+		_, err := strconv.Atoi(n)
+		return err == nil
+	}
+	_, err := time.Parse(p.layout(), suffix)
+	return err == nil
+}
+
+// Targets 根据 filter 裁剪分区：时间分区按 TimeField 上的 $eq/$gt/$gte/$lt/$lte 条件，
+// 哈希分区按 HashField 上的等值或 $in 条件；无法裁剪时返回全部已存在分区。
+func (p *Partitioner) Targets(ctx context.Context, filter bson.D) ([]string, error) {
+	names, err := p.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.prune(names, filter), nil
+}
+
+// prune 从已存在的分区 names 中剔除 filter 不可能命中的分区。
+func (p *Partitioner) prune(names []string, filter bson.D) []string {
+	if p.conf.Mode == ModeHash {
+		keys, ok := hashKeys(lookup(filter, p.conf.HashField))
+		if !ok {
+			return names
+		}
+		wanted := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			wanted[p.HashName(k)] = true
+		}
+		return slices.DeleteFunc(names, func(name string) bool { return !wanted[name] })
+	}
+
+	from, to := timeRange(lookup(filter, p.conf.TimeField))
+	return slices.DeleteFunc(names, func(name string) bool {
+		start, _ := time.Parse(p.layout(), strings.TrimPrefix(name, p.conf.Base+"_"))
+		end := start.AddDate(0, 1, 0)
+		if p.conf.Period == PeriodDay {
+			end = start.AddDate(0, 0, 1)
+		}
+		return (!from.IsZero() && !end.After(from)) || (!to.IsZero() && start.After(to))
+	})
+}
+
+// Find 在裁剪后的分区中依次查询（时间分区按时间升序），limit>0 时达到条数即停止；结果按各分区集合的访问策略剔除无权读取的字段。
+func Find[T any](ctx context.Context, p *Partitioner, filter bson.D, limit int64) ([]T, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if err := gomongo.CheckFilter(filter); err != nil {
+		return nil, err
+	}
+
+	names, err := p.Targets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []T
+	for _, name := range names {
+		var err error
+		if list, err = findIn(ctx, p.db.Collection(name), filter, limit, list); err != nil {
+			return nil, err
+		}
+		if limit > 0 && int64(len(list)) >= limit {
+			break
+		}
+	}
+	return list, nil
+}
+
+// findIn 以 find 操作经过拦截器后查询单个分区，将结果追加到 list，达到 limit 时停止。
+func findIn[T any](ctx context.Context, collection *mongo.Collection, filter bson.D, limit int64, list []T) (_ []T, err error) {
+	op := &gomongo.Operation{Name: "find", Filter: filter}
+	ctx, finish, err := gomongo.Intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	cursor, err := collection.Find(ctx, op.Filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	for cursor.Next(ctx) {
+		var doc T
+		if err := gomongo.DecodeRestricted(ctx, gomongo.PolicyNamespace(collection), cursor.Current, &doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc)
+		if limit > 0 && int64(len(list)) >= limit {
+			return list, nil
+		}
+	}
+	return list, cursor.Err()
+}
+
+// Count 统计裁剪后各分区中满足 filter 的文档总数。
+func (p *Partitioner) Count(ctx context.Context, filter bson.D) (int64, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if err := gomongo.CheckFilter(filter); err != nil {
+		return 0, err
+	}
+
+	names, err := p.Targets(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, name := range names {
+		n, err := countIn(ctx, p.db.Collection(name), filter)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// countIn 以 count 操作经过拦截器后统计单个分区。
+func countIn(ctx context.Context, collection *mongo.Collection, filter bson.D) (_ int64, err error) {
+	op := &gomongo.Operation{Name: "count", Filter: filter}
+	ctx, finish, err := gomongo.Intercept(ctx, collection, op)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { finish(err) }()
+
+	return collection.CountDocuments(ctx, op.Filter)
+}
+
+// Retire 删除超出 Retention 的时间分区，返回被删除的集合名；删除通过 DropCollection 执行并记录审计日志。
+func (p *Partitioner) Retire(ctx context.Context) ([]string, error) {
+	if p.conf.Mode != ModeTime || p.conf.Retention <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	oldest := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(p.conf.Retention - 1), 0)
+	if p.conf.Period == PeriodDay {
+		oldest = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(p.conf.Retention - 1))
+	}
+
+	names, err := p.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var retired []string
+	for _, name := range names {
+		start, err := time.Parse(p.layout(), strings.TrimPrefix(name, p.conf.Base+"_"))
+		if err != nil || !start.Before(oldest) {
+			continue
+		}
+		if err := gomongo.DropCollection(gomongo.WithDestructiveConfirm(ctx), p.db.Collection(name)); err != nil {
+			return retired, err
+		}
+		p.created.Delete(name)
+		retired = append(retired, name)
+	}
+	return retired, nil
+}
+
+func lookup(filter bson.D, key string) any {
+	for _, e := range filter {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// timeRange 从字段条件中解析时间范围，零值表示该侧无限制。
+func timeRange(cond any) (from, to time.Time) {
+	if t, ok := asTime(cond); ok {
+		return t, t
+	}
+
+	var ops bson.D
+	switch v := cond.(type) {
+	case bson.D:
+		ops = v
+	case bson.M:
+		for k, val := range v {
+			ops = append(ops, bson.E{Key: k, Value: val})
+		}
+	default:
+		return
+	}
+
+	for _, op := range ops {
+		t, ok := asTime(op.Value)
+		if !ok {
+			continue
+		}
+		switch op.Key {
+		case "$eq":
+			from, to = t, t
+		case "$gt", "$gte":
+			from = t
+		case "$lt", "$lte":
+			to = t
+		}
+	}
+	return
+}
+
+func asTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC(), true
+	case bson.DateTime:
+		return t.Time().UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// hashKeys 从字段条件中解析等值或 $in 的取值。
+func hashKeys(cond any) ([]any, bool) {
+	switch v := cond.(type) {
+	case nil:
+		return nil, false
+	case bson.D:
+		for _, op := range v {
+			if op.Key == "$eq" {
+				return []any{op.Value}, true
+			}
+			if op.Key == "$in" {
+				if list, ok := op.Value.(bson.A); ok {
+					return list, true
+				}
+				if list, ok := op.Value.([]any); ok {
+					return list, true
+				}
+				if list, ok := op.Value.([]string); ok {
+					keys := make([]any, len(list))
+					for i, s := range list {
+						keys[i] = s
+					}
+					return keys, true
+				}
+			}
+		}
+		return nil, false
+	case bson.M:
+		return nil, false
+	default:
+		return []any{v}, true
+	}
+}
+
+// hashBytes 返回 BSON 值的规范化哈希输入：类型字节加值的编码，int32 转为 int64。
+func hashBytes(value bson.RawValue) []byte {
+	if n, ok := value.Int32OK(); ok {
+		_, data, _ := bson.MarshalValue(int64(n))
+		return append([]byte{byte(bson.TypeInt64)}, data...)
+	}
+	return append([]byte{byte(value.Type)}, value.Value...)
+}
+
+func isNamespaceExists(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorCode(48)
+}
@@ -0,0 +1,141 @@
+package partition
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func newPartitioner(t *testing.T, conf Conf) *Partitioner {
+	t.Helper()
+	conf.Base = "events"
+	p, err := New(nil, &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRoute(t *testing.T) {
+	id := bson.NewObjectID()
+	at := time.Date(2026, 5, 31, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		conf Conf
+		doc  any
+		want string
+		err  error
+	}{
+		{name: "month", doc: bson.D{{Key: "created_at", Value: at}}, want: "events_2026_05"},
+		{name: "day", conf: Conf{Period: PeriodDay}, doc: bson.D{{Key: "created_at", Value: at}}, want: "events_2026_05_31"},
+		{name: "nested time field", conf: Conf{TimeField: "meta.at"}, doc: bson.M{"meta": bson.M{"at": at}}, want: "events_2026_05"},
+		{name: "missing time", doc: bson.D{{Key: "name", Value: "x"}}, err: ErrMissingKey},
+		{name: "time is not a datetime", doc: bson.D{{Key: "created_at", Value: "2026-05-31"}}, err: ErrMissingKey},
+		{name: "object id", conf: Conf{Mode: ModeHash}, doc: bson.D{{Key: "_id", Value: id}}, want: newPartitioner(t, Conf{Mode: ModeHash}).HashName(id)},
+		{name: "missing hash key", conf: Conf{Mode: ModeHash}, doc: bson.D{{Key: "name", Value: "x"}}, err: ErrMissingKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newPartitioner(t, tt.conf).Route(tt.doc)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Route = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHashName 确认 HashName 与 Route 从文档读取的字段值落入同一分区。
+func TestHashName(t *testing.T) {
+	p := newPartitioner(t, Conf{Mode: ModeHash, HashField: "user", Buckets: 8})
+	id := bson.NewObjectID()
+
+	tests := []struct {
+		name string
+		key  any
+		doc  bson.D
+	}{
+		{name: "string", key: "u1", doc: bson.D{{Key: "user", Value: "u1"}}},
+		{name: "object id", key: id, doc: bson.D{{Key: "user", Value: id}}},
+		{name: "int and int32", key: 7, doc: bson.D{{Key: "user", Value: int32(7)}}},
+		{name: "int and int64", key: 7, doc: bson.D{{Key: "user", Value: int64(7)}}},
+		{name: "int64 and int32", key: int64(1 << 20), doc: bson.D{{Key: "user", Value: int32(1 << 20)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := p.HashName(tt.key)
+			if !p.owns(name) || !strings.HasPrefix(name, "events_h") {
+				t.Fatalf("HashName = %q", name)
+			}
+			routed, err := p.Route(tt.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if routed != name {
+				t.Errorf("Route = %q, HashName = %q", routed, name)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	months := []string{"events_2026_01", "events_2026_02", "events_2026_03", "events_2026_04"}
+	days := []string{"events_2026_02_27", "events_2026_02_28", "events_2026_03_01"}
+	feb15 := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	mar1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	hash := newPartitioner(t, Conf{Mode: ModeHash, Buckets: 4})
+	var buckets []string
+	for i := range 4 {
+		buckets = append(buckets, fmt.Sprintf("events_h%02d", i))
+	}
+
+	tests := []struct {
+		name   string
+		conf   Conf
+		names  []string
+		filter bson.D
+		want   []string
+	}{
+		{name: "no condition", names: months, want: months},
+		{name: "equal time", names: months, filter: bson.D{{Key: "created_at", Value: feb15}}, want: months[1:2]},
+		{
+			name:   "range",
+			names:  months,
+			filter: bson.D{{Key: "created_at", Value: bson.D{{Key: "$gte", Value: feb15}, {Key: "$lt", Value: mar1}}}},
+			// $lt 与 $lte 同样处理，边界所在分区保留。
+			want: months[1:3],
+		},
+		{name: "lower bound only", names: months, filter: bson.D{{Key: "created_at", Value: bson.M{"$gt": mar1}}}, want: months[2:]},
+		{name: "bson datetime", names: months, filter: bson.D{{Key: "created_at", Value: bson.D{{Key: "$lte", Value: bson.NewDateTimeFromTime(feb15)}}}}, want: months[:2]},
+		{name: "days", conf: Conf{Period: PeriodDay}, names: days, filter: bson.D{{Key: "created_at", Value: bson.D{{Key: "$gte", Value: mar1}}}}, want: days[2:]},
+		{name: "hash equal", conf: Conf{Mode: ModeHash, Buckets: 4}, names: buckets, filter: bson.D{{Key: "_id", Value: "a"}}, want: []string{hash.HashName("a")}},
+		{
+			name:   "hash $in",
+			conf:   Conf{Mode: ModeHash, Buckets: 4},
+			names:  buckets,
+			filter: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{"a", "b"}}}}},
+			want:   slices.DeleteFunc(slices.Clone(buckets), func(n string) bool { return n != hash.HashName("a") && n != hash.HashName("b") }),
+		},
+		{name: "hash range is not pruned", conf: Conf{Mode: ModeHash, Buckets: 4}, names: buckets, filter: bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: "a"}}}}, want: buckets},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newPartitioner(t, tt.conf).prune(slices.Clone(tt.names), tt.filter)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("prune = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	// ErrInvalidPatch 表示补丁格式非法或包含无法转换为 Mongo 更新的路径。
+	ErrInvalidPatch = errors.New("mongo: invalid patch")
+	// ErrPatchConflict 表示补丁的 test 断言失败、目标路径不存在或补丁内部路径互相冲突。
+	ErrPatchConflict = errors.New("mongo: patch conflict")
+)
+
+// jsonPatchOp 为 RFC 6902 的单个操作。
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// patchPlan 为补丁翻译后的更新计划。
+type patchPlan struct {
+	filter bson.D
+	set    bson.D
+	unset  bson.D
+	push   bson.D
+	paths  []string
+}
+
+// ApplyJSONPatch 将 RFC 6902（JSON 数组）或 RFC 7386（JSON 对象）补丁转换为更新操作符并应用到指定文档。
+// RFC 6902 的 test/replace/remove 会转为过滤条件，不满足时返回 ErrPatchConflict；move/copy 暂不支持。
+// 由于整个补丁以一次原子更新执行，test 只能断言补丁修改前的值：test 位于修改同一（或重叠）路径的操作之后时返回 ErrInvalidPatch。
+// 数组下标上的 add 按覆盖处理，追加元素请使用 "-"；按下标 remove 数组元素（需要移动后续元素）不支持，返回 ErrInvalidPatch。
+// RFC 7386 合并补丁中的对象值只与已有的对象字段合并，目标字段为非对象值时返回 ErrPatchConflict（请先以 null 删除或整体替换）；
+// 空对象不修改目标字段（目标缺失时也不会创建 {}）。
+// 只有顶层 _id 不可修改，嵌套文档中的 _id 字段可以正常修改。
+// 集合注册了访问策略时，修改只读、服务端管理或无权限字段返回 ErrPolicyViolation。
+func ApplyJSONPatch(ctx context.Context, collection *mongo.Collection, id string, patch []byte) (result *mongo.UpdateResult, err error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	plan, err := parsePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckWrite(ctx, PolicyNamespace(collection), plan.paths); err != nil {
+		return nil, err
+	}
+
+	update := bson.D{}
+	if !plan.touches("updated_at") {
+		plan.set = append(plan.set, bson.E{Key: "updated_at", Value: time.Now().UTC()})
+	}
+	if len(plan.set) != 0 {
+		update = append(update, bson.E{Key: "$set", Value: plan.set})
+	}
+	if len(plan.unset) != 0 {
+		update = append(update, bson.E{Key: "$unset", Value: plan.unset})
+	}
+	if len(plan.push) != 0 {
+		update = append(update, bson.E{Key: "$push", Value: plan.push})
+	}
+
+	op := &Operation{Name: "patch", Ids: []string{id}, Filter: append(bson.D{{Key: "_id", Value: id}}, plan.filter...), Update: update}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	result, err = collection.UpdateOne(ctx, op.Filter, op.Update)
+	if err != nil {
+		// PathNotViable：点路径穿过了非对象值（合并补丁的目标字段不是对象）。
+		var se mongo.ServerError
+		if errors.As(err, &se) && se.HasErrorCode(28) {
+			return nil, fmt.Errorf("%w: %v", ErrPatchConflict, err)
+		}
+		return nil, err
+	}
+
+	// 未匹配时区分文档不存在与断言失败。
+	if result.MatchedCount == 0 {
+		count, err := collection.CountDocuments(ctx, bson.D{{Key: "_id", Value: id}})
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+		return nil, ErrPatchConflict
+	}
+
+	return result, nil
+}
+
+// parsePatch 按补丁格式（JSON 数组为 RFC 6902，JSON 对象为 RFC 7386）翻译为更新计划。
+func parsePatch(patch []byte) (*patchPlan, error) {
+	plan := &patchPlan{}
+
+	var err error
+	switch trimmed := bytes.TrimSpace(patch); {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("%w: empty patch", ErrInvalidPatch)
+	case trimmed[0] == '[':
+		err = plan.jsonPatch(trimmed)
+	case trimmed[0] == '{':
+		err = plan.mergePatch(trimmed)
+	default:
+		return nil, fmt.Errorf("%w: patch must be a JSON array or object", ErrInvalidPatch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (p *patchPlan) jsonPatch(patch []byte) error {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	for i, op := range ops {
+		path, err := pointerToPath(op.Path)
+		if err != nil {
+			return fmt.Errorf("%w: op %d: %v", ErrInvalidPatch, i, err)
+		}
+
+		switch op.Op {
+		case "add":
+			value, err := extJSONValue(op.Value)
+			if err != nil {
+				return fmt.Errorf("%w: op %d: %v", ErrInvalidPatch, i, err)
+			}
+			if parent, ok := strings.CutSuffix(path, ".-"); ok {
+				if err := p.claim(parent); err != nil {
+					return err
+				}
+				p.push = append(p.push, bson.E{Key: parent, Value: value})
+				continue
+			}
+			if err := p.claim(path); err != nil {
+				return err
+			}
+			p.set = append(p.set, bson.E{Key: path, Value: value})
+		case "replace":
+			value, err := extJSONValue(op.Value)
+			if err != nil {
+				return fmt.Errorf("%w: op %d: %v", ErrInvalidPatch, i, err)
+			}
+			if err := p.claim(path); err != nil {
+				return err
+			}
+			p.filter = append(p.filter, bson.E{Key: path, Value: bson.D{{Key: "$exists", Value: true}}})
+			p.set = append(p.set, bson.E{Key: path, Value: value})
+		case "remove":
+			if isArrayIndex(path) {
+				return fmt.Errorf("%w: op %d: removing array element %q by index is not supported", ErrInvalidPatch, i, op.Path)
+			}
+			if err := p.claim(path); err != nil {
+				return err
+			}
+			p.filter = append(p.filter, bson.E{Key: path, Value: bson.D{{Key: "$exists", Value: true}}})
+			p.unset = append(p.unset, bson.E{Key: path, Value: ""})
+		case "test":
+			value, err := extJSONValue(op.Value)
+			if err != nil {
+				return fmt.Errorf("%w: op %d: %v", ErrInvalidPatch, i, err)
+			}
+			// test 转为过滤条件，只能看到修改前的文档；断言前面操作写入的值需要逐步执行，不支持。
+			if existing, ok := p.overlaps(path); ok {
+				return fmt.Errorf("%w: op %d: test of %q after modifying %q is not supported", ErrInvalidPatch, i, op.Path, existing)
+			}
+			p.filter = append(p.filter, bson.E{Key: path, Value: bson.D{{Key: "$eq", Value: value}}})
+		case "move", "copy":
+			return fmt.Errorf("%w: op %d: %q is not supported", ErrInvalidPatch, i, op.Op)
+		default:
+			return fmt.Errorf("%w: op %d: unknown op %q", ErrInvalidPatch, i, op.Op)
+		}
+	}
+
+	return nil
+}
+
+func (p *patchPlan) mergePatch(patch []byte) error {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(patch, false, &doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return p.merge("", doc)
+}
+
+// merge 递归展开合并补丁：null 删除字段，对象逐层合并（空对象不修改），其余值直接覆盖。
+func (p *patchPlan) merge(prefix string, doc bson.D) error {
+	for _, e := range doc {
+		if err := validatePatchKey(e.Key, prefix == ""); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+
+		path := e.Key
+		if prefix != "" {
+			path = prefix + "." + e.Key
+		}
+
+		switch v := e.Value.(type) {
+		case nil:
+			if err := p.claim(path); err != nil {
+				return err
+			}
+			p.unset = append(p.unset, bson.E{Key: path, Value: ""})
+		case bson.D:
+			// 空对象与已有对象合并为空操作；目标缺失或为非对象时 RFC 7386 会替换为 {}，单次更新无法按目标类型区分，同样跳过。
+			if len(v) == 0 {
+				continue
+			}
+			if err := p.merge(path, v); err != nil {
+				return err
+			}
+		default:
+			if err := p.claim(path); err != nil {
+				return err
+			}
+			p.set = append(p.set, bson.E{Key: path, Value: v})
+		}
+	}
+
+	return nil
+}
+
+// claim 登记本次补丁修改的路径，路径互为前缀时 Mongo 会拒绝更新，提前返回 ErrPatchConflict。
+func (p *patchPlan) claim(path string) error {
+	if existing, ok := p.overlaps(path); ok {
+		return fmt.Errorf("%w: path %q overlaps %q", ErrPatchConflict, path, existing)
+	}
+	p.paths = append(p.paths, path)
+	return nil
+}
+
+// overlaps 返回与 path 相同或互为前缀的已修改路径。
+func (p *patchPlan) overlaps(path string) (string, bool) {
+	for _, existing := range p.paths {
+		if existing == path || strings.HasPrefix(existing, path+".") || strings.HasPrefix(path, existing+".") {
+			return existing, true
+		}
+	}
+	return "", false
+}
+
+func (p *patchPlan) touches(path string) bool {
+	for _, existing := range p.paths {
+		if existing == path || strings.HasPrefix(existing, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// pointerToPath 将 JSON Pointer（/a/b/0）转换为点路径（a.b.0）。
+func pointerToPath(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("path %q must start with /", pointer)
+	}
+
+	segments := strings.Split(pointer[1:], "/")
+	for i, seg := range segments {
+		seg = strings.ReplaceAll(seg, "~1", "/")
+		seg = strings.ReplaceAll(seg, "~0", "~")
+		if err := validatePatchKey(seg, i == 0); err != nil {
+			return "", err
+		}
+		segments[i] = seg
+	}
+
+	return strings.Join(segments, "."), nil
+}
+
+// validatePatchKey 校验路径中的单个字段名，top 表示顶层字段（只有顶层 _id 不可修改）。
+func validatePatchKey(key string, top bool) error {
+	switch {
+	case key == "":
+		return errors.New("empty path segment")
+	case top && key == "_id":
+		return errors.New("_id is immutable")
+	case strings.HasPrefix(key, "$"):
+		return fmt.Errorf("segment %q must not start with $", key)
+	case strings.Contains(key, "."):
+		return fmt.Errorf("segment %q must not contain '.'", key)
+	}
+	return nil
+}
+
+// isArrayIndex 判断点路径的最后一段是否为数组下标。
+func isArrayIndex(path string) bool {
+	seg := path[strings.LastIndexByte(path, '.')+1:]
+	if seg == "" {
+		return false
+	}
+	for _, c := range seg {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// extJSONValue 以 Extended JSON（relaxed）解析补丁中的值，保留整数类型与嵌套文档顺序。
+func extJSONValue(raw json.RawMessage) (any, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("missing value")
+	}
+
+	var holder struct {
+		V any `bson:"v"`
+	}
+	doc := make([]byte, 0, len(raw)+6)
+	doc = append(doc, `{"v":`...)
+	doc = append(doc, raw...)
+	doc = append(doc, '}')
+	if err := bson.UnmarshalExtJSON(doc, false, &holder); err != nil {
+		return nil, err
+	}
+
+	return holder.V, nil
+}
@@ -0,0 +1,200 @@
+package mongo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func exists(path string) bson.E {
+	return bson.E{Key: path, Value: bson.D{{Key: "$exists", Value: true}}}
+}
+
+func TestParsePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		patch  string
+		filter bson.D
+		set    bson.D
+		unset  bson.D
+		push   bson.D
+		err    error
+	}{
+		{
+			name:  "add",
+			patch: `[{"op":"add","path":"/name","value":"ann"}]`,
+			set:   bson.D{{Key: "name", Value: "ann"}},
+		},
+		{
+			name:  "add appends with -",
+			patch: `[{"op":"add","path":"/tags/-","value":"go"}]`,
+			push:  bson.D{{Key: "tags", Value: "go"}},
+		},
+		{
+			name:   "replace requires the path",
+			patch:  `[{"op":"replace","path":"/age","value":3}]`,
+			filter: bson.D{exists("age")},
+			set:    bson.D{{Key: "age", Value: int32(3)}},
+		},
+		{
+			name:   "remove",
+			patch:  `[{"op":"remove","path":"/age"}]`,
+			filter: bson.D{exists("age")},
+			unset:  bson.D{{Key: "age", Value: ""}},
+		},
+		{
+			name:  "test before replace",
+			patch: `[{"op":"test","path":"/v","value":1},{"op":"replace","path":"/v","value":2}]`,
+			filter: bson.D{
+				{Key: "v", Value: bson.D{{Key: "$eq", Value: int32(1)}}},
+				exists("v"),
+			},
+			set: bson.D{{Key: "v", Value: int32(2)}},
+		},
+		{
+			name:  "test after replace of the same path",
+			patch: `[{"op":"replace","path":"/v","value":2},{"op":"test","path":"/v","value":2}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "test after modifying a parent",
+			patch: `[{"op":"add","path":"/a","value":{}},{"op":"test","path":"/a/b","value":1}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "test of an unrelated path after a modification",
+			patch: `[{"op":"add","path":"/a","value":1},{"op":"test","path":"/b","value":1}]`,
+			filter: bson.D{
+				{Key: "b", Value: bson.D{{Key: "$eq", Value: int32(1)}}},
+			},
+			set: bson.D{{Key: "a", Value: int32(1)}},
+		},
+		{
+			name:  "overlapping paths",
+			patch: `[{"op":"add","path":"/a","value":{}},{"op":"add","path":"/a/b","value":1}]`,
+			err:   ErrPatchConflict,
+		},
+		{
+			name:  "same path twice",
+			patch: `[{"op":"add","path":"/a","value":1},{"op":"remove","path":"/a"}]`,
+			err:   ErrPatchConflict,
+		},
+		{
+			name:  "escaped segments",
+			patch: `[{"op":"add","path":"/a~1b/c~0d","value":1}]`,
+			set:   bson.D{{Key: "a/b.c~d", Value: int32(1)}},
+		},
+		{
+			name:  "escaped tilde",
+			patch: `[{"op":"add","path":"/m/c~0d~01","value":1}]`,
+			set:   bson.D{{Key: "m.c~d~1", Value: int32(1)}},
+		},
+		{
+			name:  "top-level _id",
+			patch: `[{"op":"replace","path":"/_id","value":"x"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:   "nested _id",
+			patch:  `[{"op":"replace","path":"/items/0/_id","value":"x"}]`,
+			filter: bson.D{exists("items.0._id")},
+			set:    bson.D{{Key: "items.0._id", Value: "x"}},
+		},
+		{
+			name:  "remove array element by index",
+			patch: `[{"op":"remove","path":"/tags/0"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "operator segment",
+			patch: `[{"op":"add","path":"/$where","value":1}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "move is not supported",
+			patch: `[{"op":"move","from":"/a","path":"/b"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "missing value",
+			patch: `[{"op":"add","path":"/a"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "merge",
+			patch: `{"name":"ann","gone":null,"profile":{"city":"x","zip":null}}`,
+			set: bson.D{
+				{Key: "name", Value: "ann"},
+				{Key: "profile.city", Value: "x"},
+			},
+			unset: bson.D{
+				{Key: "gone", Value: ""},
+				{Key: "profile.zip", Value: ""},
+			},
+		},
+		{
+			name:  "merge empty object is a no-op",
+			patch: `{"profile":{},"name":"ann"}`,
+			set:   bson.D{{Key: "name", Value: "ann"}},
+		},
+		{
+			name:  "merge nested empty object",
+			patch: `{"profile":{"address":{}}}`,
+		},
+		{
+			name:  "merge top-level _id",
+			patch: `{"_id":"x"}`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "merge nested _id",
+			patch: `{"owner":{"_id":"u1"}}`,
+			set:   bson.D{{Key: "owner._id", Value: "u1"}},
+		},
+		{
+			name:  "merge duplicate keys",
+			patch: `{"a":1,"a":2}`,
+			err:   ErrPatchConflict,
+		},
+		{
+			name:  "empty patch",
+			patch: `  `,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "scalar patch",
+			patch: `1`,
+			err:   ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := parsePatch([]byte(tt.patch))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			for _, c := range []struct {
+				name      string
+				got, want bson.D
+			}{
+				{"filter", plan.filter, tt.filter},
+				{"set", plan.set, tt.set},
+				{"unset", plan.unset, tt.unset},
+				{"push", plan.push, tt.push},
+			} {
+				if len(c.got) == 0 && len(c.want) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(c.got, c.want) {
+					t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Patch 为 PatchMany 中的单个更新：按 Id 定位文档，Update 为更新文档（$set 等操作符）。
+type Patch struct {
+	Id     string
+	Update any
+}
+
+// PatchResult 为单个 Patch 的执行结果，与输入顺序一一对应。
+type PatchResult struct {
+	Id       string `json:"id"`
+	Matched  bool   `json:"matched"`
+	Modified bool   `json:"modified"`
+	Err      error  `json:"-"`
+}
+
+// PatchMany 批量更新多个文档并返回逐条结果，单条失败不影响其他条目。
+// 服务端 8.0+ 以无序 bulkWrite 一次提交；更低版本无法从 bulk 结果中区分逐条的匹配情况，退化为逐条 UpdateOne。
+// Update 为 bson.D 且未修改 updated_at 时会自动写入当前时间；集合注册了访问策略时，修改受限字段的条目返回 ErrPolicyViolation。
+// 返回的 error 仅表示整体失败（如网络错误）；写关注失败时写入可能已生效，各条目的 Err 为对应的写关注错误。
+func PatchMany(ctx context.Context, collection *mongo.Collection, patches []Patch) (_ []PatchResult, err error) {
+	results := make([]PatchResult, len(patches))
+	if len(patches) == 0 {
+		return results, nil
+	}
+
+	// 预先校验，非法条目直接记录错误，不参与提交。
+	var index []int
+	var ids []string
+	var filters []bson.D
+	var updates []any
+	for i, p := range patches {
+		results[i].Id = p.Id
+		if err := ValidateID(p.Id); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if p.Update == nil {
+			results[i].Err = errors.New("mongo: patch update is nil")
+			continue
+		}
+		if err := CheckFilter(p.Update); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := checkUpdate(ctx, collection, p.Update); err != nil {
+			results[i].Err = err
+			continue
+		}
+		index = append(index, i)
+		ids = append(ids, p.Id)
+		filters = append(filters, bson.D{{Key: "_id", Value: p.Id}})
+		updates = append(updates, touchUpdatedAt(p.Update))
+	}
+	if len(index) == 0 {
+		return results, nil
+	}
+
+	// 逐条过滤条件以 []bson.D 交给拦截器（如 TenantInterceptor 追加租户条件），提交时使用改写后的条件。
+	op := &Operation{Name: "patch_many", Ids: ids, Filter: filters}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+	if filters, _ = op.Filter.([]bson.D); len(filters) != len(index) {
+		return nil, errors.New("mongo: interceptor changed the patch_many filters")
+	}
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	info, err := Capabilities(ctx, collection.Database().Client())
+	if err != nil {
+		return nil, err
+	}
+	if info.VersionAtLeast(8, 0) {
+		return results, patchBulk(ctx, collection, index, filters, updates, results)
+	}
+
+	for k, i := range index {
+		res, err := collection.UpdateOne(ctx, filters[k], updates[k])
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			results[i].Err = err
+			continue
+		}
+		results[i].Matched = res.MatchedCount > 0
+		results[i].Modified = res.ModifiedCount > 0
+	}
+	return results, nil
+}
+
+// patchBulk 使用 client 级 bulkWrite（verbose 结果）提交所有更新。
+func patchBulk(ctx context.Context, collection *mongo.Collection, index []int, filters []bson.D, updates []any, results []PatchResult) error {
+	writes := make([]mongo.ClientBulkWrite, 0, len(index))
+	for k := range index {
+		writes = append(writes, mongo.ClientBulkWrite{
+			Database:   collection.Database().Name(),
+			Collection: collection.Name(),
+			Model: &mongo.ClientUpdateOneModel{
+				Filter: filters[k],
+				Update: updates[k],
+			},
+		})
+	}
+
+	res, err := collection.Database().Client().BulkWrite(ctx, writes,
+		options.ClientBulkWrite().SetOrdered(false).SetVerboseResults(true))
+
+	var bwe mongo.ClientBulkWriteException
+	if err != nil {
+		if !errors.As(err, &bwe) || bwe.WriteError != nil {
+			return err
+		}
+		res = bwe.PartialResult
+	}
+
+	// 写关注错误针对整批写入，未单独失败的条目均无法确认已满足写关注。
+	var wcErr error
+	if len(bwe.WriteConcernErrors) != 0 {
+		errs := make([]error, len(bwe.WriteConcernErrors))
+		for j, wce := range bwe.WriteConcernErrors {
+			errs[j] = wce
+		}
+		wcErr = errors.Join(errs...)
+	}
+
+	for k, i := range index {
+		if we, ok := bwe.WriteErrors[k]; ok {
+			results[i].Err = we
+			continue
+		}
+		results[i].Err = wcErr
+		if res == nil {
+			continue
+		}
+		if ur, ok := res.UpdateResults[k]; ok {
+			results[i].Matched = ur.MatchedCount > 0
+			results[i].Modified = ur.ModifiedCount > 0
+		}
+	}
+	return nil
+}
+
+// touchUpdatedAt 在 bson.D 更新文档未涉及 updated_at 时向 $set 追加当前时间（$set 不是 bson.D 时保持原样）。
+func touchUpdatedAt(update any) any {
+	doc, ok := update.(bson.D)
+	if !ok {
+		return update
+	}
+
+	setIndex := -1
+	for i, op := range doc {
+		fields, ok := op.Value.(bson.D)
+		if op.Key == "$set" {
+			if !ok {
+				return update
+			}
+			setIndex = i
+		}
+		for _, f := range fields {
+			if f.Key == "updated_at" {
+				return update
+			}
+		}
+	}
+
+	timer := bson.E{Key: "updated_at", Value: time.Now().UTC()}
+	out := append(bson.D{}, doc...)
+	if setIndex < 0 {
+		return append(out, bson.E{Key: "$set", Value: bson.D{timer}})
+	}
+	out[setIndex].Value = append(append(bson.D{}, out[setIndex].Value.(bson.D)...), timer)
+	return out
+}
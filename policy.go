@@ -0,0 +1,389 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrPolicyViolation 表示写入触及了访问策略禁止修改的字段。
+var ErrPolicyViolation = errors.New("mongo: access policy violation")
+
+// Policy 为集合级字段访问策略，字段均为点路径，策略作用于该路径及其子字段；数组元素中的字段使用不含下标的路径（如 items.price）。
+type Policy struct {
+	// ReadOnly 为创建后不可修改的字段。
+	ReadOnly []string
+	// ServerManaged 为只能由服务端写入的字段（如 created_at、tenant_id），客户端补丁不可修改。
+	ServerManaged []string
+	// Roles 为字段到角色列表的映射：ctx 中不含其中任一角色时，读取会剔除该字段，写入会被拒绝。
+	Roles map[string][]string
+}
+
+var policies sync.Map
+
+// RegisterPolicy 为集合注册访问策略，namespace 为 "库名.集合名"（见 PolicyNamespace），不同库的同名集合互不影响；重复注册时覆盖。
+func RegisterPolicy(namespace string, policy Policy) {
+	policies.Store(namespace, policy)
+}
+
+// PolicyNamespace 返回集合在策略注册表中的键 "库名.集合名"。
+func PolicyNamespace(collection *mongo.Collection) string {
+	return collection.Database().Name() + "." + collection.Name()
+}
+
+// LookupPolicy 返回集合（"库名.集合名"）注册的访问策略。
+func LookupPolicy(namespace string) (Policy, bool) {
+	v, ok := policies.Load(namespace)
+	if !ok {
+		return Policy{}, false
+	}
+	return v.(Policy), true
+}
+
+type rolesKey struct{}
+
+// WithRoles 在 ctx 中记录当前调用方的角色，供访问策略判断。
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// RolesFromContext 返回 ctx 中记录的角色。
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return roles
+}
+
+// CheckWrite 校验即将修改的字段路径是否符合集合（"库名.集合名"）的访问策略，违反时返回包装了 ErrPolicyViolation 的错误。
+// 路径中的数组下标与位置操作符（items.0.price、items.$[].price）按所在数组字段处理。
+// ApplyJSONPatch 与 PatchMany 已内置该校验，直接使用 driver 写入时需自行调用。
+func CheckWrite(ctx context.Context, namespace string, paths []string) error {
+	policy, ok := LookupPolicy(namespace)
+	if !ok {
+		return nil
+	}
+
+	roles := RolesFromContext(ctx)
+	for _, path := range paths {
+		path = policyPath(path)
+		for _, field := range policy.ReadOnly {
+			if pathsOverlap(path, field) {
+				return fmt.Errorf("%w: %q is read-only", ErrPolicyViolation, path)
+			}
+		}
+		for _, field := range policy.ServerManaged {
+			if pathsOverlap(path, field) {
+				return fmt.Errorf("%w: %q is server-managed", ErrPolicyViolation, path)
+			}
+		}
+		for field, allowed := range policy.Roles {
+			if pathsOverlap(path, field) && !hasAnyRole(roles, allowed) {
+				return fmt.Errorf("%w: %q requires one of roles %v", ErrPolicyViolation, path, allowed)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckInsert 校验新文档是否写入了 ctx 角色无权写入的字段；ReadOnly 与 ServerManaged 字段在创建时由服务端写入，不做限制。
+// BufferedInserter、EnsureDocuments 与 partition.Partitioner.InsertOne 已内置该校验。
+func CheckInsert(ctx context.Context, namespace string, docs ...any) error {
+	policy, ok := LookupPolicy(namespace)
+	if !ok || len(policy.Roles) == 0 {
+		return nil
+	}
+
+	roles := RolesFromContext(ctx)
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		for _, path := range documentPaths(raw, "") {
+			for field, allowed := range policy.Roles {
+				if pathsOverlap(path, field) && !hasAnyRole(roles, allowed) {
+					return fmt.Errorf("%w: %q requires one of roles %v", ErrPolicyViolation, path, allowed)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// documentPaths 返回文档中全部叶子字段的路径，数组元素中的字段不含下标。
+func documentPaths(doc bson.Raw, prefix string) []string {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, elem := range elems {
+		path := elem.Key()
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		paths = append(paths, valuePaths(elem.Value(), path)...)
+	}
+	return paths
+}
+
+func valuePaths(value bson.RawValue, path string) []string {
+	switch value.Type {
+	case bson.TypeEmbeddedDocument:
+		if paths := documentPaths(value.Document(), path); len(paths) != 0 {
+			return paths
+		}
+	case bson.TypeArray:
+		values, _ := value.Array().Values()
+		var paths []string
+		for _, item := range values {
+			paths = append(paths, valuePaths(item, path)...)
+		}
+		if len(paths) != 0 {
+			return paths
+		}
+	}
+	return []string{path}
+}
+
+// checkUpdate 按集合的访问策略校验更新文档（操作符文档、替换文档或聚合管道）修改的字段。
+func checkUpdate(ctx context.Context, collection *mongo.Collection, update any) error {
+	namespace := PolicyNamespace(collection)
+	if _, ok := LookupPolicy(namespace); !ok {
+		return nil
+	}
+	paths, err := updatePaths(update)
+	if err != nil {
+		return err
+	}
+	return CheckWrite(ctx, namespace, paths)
+}
+
+// updatePaths 返回更新修改的字段路径：操作符文档取各操作符下的字段（$rename 同时取目标字段），替换文档取顶层字段，
+// 聚合管道取 $set/$addFields/$unset 阶段的字段；无法确定修改字段的管道阶段（如 $replaceWith）返回 ErrPolicyViolation。
+func updatePaths(update any) ([]string, error) {
+	raw, err := bson.Marshal(bson.D{{Key: "v", Value: update}})
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	value := bson.Raw(raw).Lookup("v")
+	switch value.Type {
+	case bson.TypeEmbeddedDocument:
+		elems, err := value.Document().Elements()
+		if err != nil {
+			return nil, err
+		}
+		for _, elem := range elems {
+			op := elem.Key()
+			if !strings.HasPrefix(op, "$") {
+				paths = append(paths, op)
+				continue
+			}
+			fields, ok := elem.Value().DocumentOK()
+			if !ok {
+				continue
+			}
+			list, err := fields.Elements()
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range list {
+				paths = append(paths, f.Key())
+				if target, ok := f.Value().StringValueOK(); ok && op == "$rename" {
+					paths = append(paths, target)
+				}
+			}
+		}
+	case bson.TypeArray:
+		stages, err := value.Array().Values()
+		if err != nil {
+			return nil, err
+		}
+		for _, stage := range stages {
+			doc, ok := stage.DocumentOK()
+			if !ok {
+				continue
+			}
+			elems, err := doc.Elements()
+			if err != nil {
+				return nil, err
+			}
+			for _, elem := range elems {
+				switch elem.Key() {
+				case "$set", "$addFields":
+					fields, _ := elem.Value().DocumentOK()
+					list, _ := fields.Elements()
+					for _, f := range list {
+						paths = append(paths, f.Key())
+					}
+				case "$unset":
+					if field, ok := elem.Value().StringValueOK(); ok {
+						paths = append(paths, field)
+					}
+					if arr, ok := elem.Value().ArrayOK(); ok {
+						list, _ := arr.Values()
+						for _, f := range list {
+							if field, ok := f.StringValueOK(); ok {
+								paths = append(paths, field)
+							}
+						}
+					}
+				default:
+					return nil, fmt.Errorf("%w: cannot determine fields written by pipeline stage %s", ErrPolicyViolation, elem.Key())
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+// PolicyProjection 返回剔除 ctx 角色无权读取字段的排除投影，无需剔除时返回 nil。
+func PolicyProjection(ctx context.Context, namespace string) bson.D {
+	fields := restrictedFields(ctx, namespace)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	projection := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		projection = append(projection, bson.E{Key: field, Value: 0})
+	}
+	return projection
+}
+
+// StripRestricted 剔除文档中 ctx 角色无权读取的字段（包括数组元素中的字段），返回新文档。
+func StripRestricted(ctx context.Context, namespace string, doc bson.D) bson.D {
+	fields := restrictedFields(ctx, namespace)
+	if len(fields) == 0 {
+		return doc
+	}
+
+	out := slices.Clone(doc)
+	for _, field := range fields {
+		out = stripPath(out, strings.Split(field, "."))
+	}
+	return out
+}
+
+// DecodeRestricted 剔除 raw 中 ctx 角色无权读取的字段后解码到 out；集合未注册策略或无需剔除时直接解码。
+// Find、FindById、History、AtVersion、EnsureDocuments、Cascade.VerifyIntegrity 与 partition.Find 已内置该处理。
+func DecodeRestricted(ctx context.Context, namespace string, raw bson.Raw, out any) error {
+	if len(restrictedFields(ctx, namespace)) == 0 {
+		return bson.Unmarshal(raw, out)
+	}
+
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	stripped, err := bson.Marshal(StripRestricted(ctx, namespace, doc))
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(stripped, out)
+}
+
+// decodeAll 读取游标中的全部文档，按 DecodeRestricted 剔除无权字段。
+func decodeAll[T any](ctx context.Context, namespace string, cursor *mongo.Cursor) ([]T, error) {
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	list := make([]T, 0)
+	for cursor.Next(ctx) {
+		var doc T
+		if err := DecodeRestricted(ctx, namespace, cursor.Current, &doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc)
+	}
+	return list, cursor.Err()
+}
+
+// readable 判断 ctx 角色能否读取集合中的 path 字段。
+func readable(ctx context.Context, namespace, path string) bool {
+	for _, field := range restrictedFields(ctx, namespace) {
+		if pathsOverlap(policyPath(path), field) {
+			return false
+		}
+	}
+	return true
+}
+
+func restrictedFields(ctx context.Context, namespace string) []string {
+	policy, ok := LookupPolicy(namespace)
+	if !ok || len(policy.Roles) == 0 {
+		return nil
+	}
+
+	roles := RolesFromContext(ctx)
+	var fields []string
+	for field, allowed := range policy.Roles {
+		if !hasAnyRole(roles, allowed) {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+func stripPath(doc bson.D, parts []string) bson.D {
+	for i, e := range doc {
+		if e.Key != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			return slices.Delete(doc, i, i+1)
+		}
+		doc[i].Value = stripValue(e.Value, parts[1:])
+		return doc
+	}
+	return doc
+}
+
+// stripValue 在子文档或数组的每个文档元素中剔除剩余路径。
+func stripValue(value any, parts []string) any {
+	switch v := value.(type) {
+	case bson.D:
+		return stripPath(slices.Clone(v), parts)
+	case bson.A:
+		out := make(bson.A, len(v))
+		for i, item := range v {
+			out[i] = stripValue(item, parts)
+		}
+		return out
+	}
+	return value
+}
+
+// policyPath 去掉路径中的数组下标与位置操作符（$、$[]、$[id]），与策略中的字段路径比较。
+func policyPath(path string) string {
+	parts := strings.Split(path, ".")
+	out := parts[:0]
+	for _, part := range parts {
+		if strings.HasPrefix(part, "$") || isArrayIndex(part) {
+			continue
+		}
+		out = append(out, part)
+	}
+	return strings.Join(out, ".")
+}
+
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+func hasAnyRole(roles, allowed []string) bool {
+	for _, r := range roles {
+		if slices.Contains(allowed, r) {
+			return true
+		}
+	}
+	return false
+}
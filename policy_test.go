@@ -0,0 +1,225 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCheckWrite(t *testing.T) {
+	const ns = "policy_test.check_write"
+	RegisterPolicy(ns, Policy{
+		ReadOnly:      []string{"username"},
+		ServerManaged: []string{"created_at"},
+		Roles:         map[string][]string{"salary": {"hr"}, "items.price": {"sales"}},
+	})
+
+	tests := []struct {
+		name  string
+		roles []string
+		paths []string
+		err   error
+	}{
+		{name: "unrestricted", paths: []string{"nickname", "profile.city"}},
+		{name: "read-only", paths: []string{"username"}, err: ErrPolicyViolation},
+		{name: "child of read-only", paths: []string{"username.first"}, err: ErrPolicyViolation},
+		{name: "parent of server-managed", paths: []string{"created_at"}, err: ErrPolicyViolation},
+		{name: "prefix is not a parent", paths: []string{"salary_band"}},
+		{name: "role missing", paths: []string{"salary"}, err: ErrPolicyViolation},
+		{name: "role present", roles: []string{"hr"}, paths: []string{"salary"}},
+		{name: "array index", paths: []string{"items.0.price"}, err: ErrPolicyViolation},
+		{name: "all positional", paths: []string{"items.$[].price"}, err: ErrPolicyViolation},
+		{name: "filtered positional", roles: []string{"sales"}, paths: []string{"items.$[i].price"}},
+		{name: "replacing the array", paths: []string{"items"}, err: ErrPolicyViolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithRoles(context.Background(), tt.roles...)
+			if err := CheckWrite(ctx, ns, tt.paths); !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckInsert(t *testing.T) {
+	const ns = "policy_test.check_insert"
+	RegisterPolicy(ns, Policy{
+		ReadOnly: []string{"username"},
+		Roles:    map[string][]string{"items.price": {"sales"}},
+	})
+
+	tests := []struct {
+		name  string
+		roles []string
+		doc   any
+		err   error
+	}{
+		{name: "read-only is allowed on insert", doc: bson.D{{Key: "username", Value: "ann"}}},
+		{name: "restricted field in array", doc: bson.D{{Key: "items", Value: bson.A{bson.D{{Key: "price", Value: 1}}}}}, err: ErrPolicyViolation},
+		{name: "role present", roles: []string{"sales"}, doc: bson.D{{Key: "items", Value: bson.A{bson.D{{Key: "price", Value: 1}}}}}},
+		{name: "other array fields", doc: bson.D{{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "a"}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithRoles(context.Background(), tt.roles...)
+			if err := CheckInsert(ctx, ns, tt.doc); !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUpdatePaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		update any
+		want   []string
+		err    error
+	}{
+		{
+			name:   "operators",
+			update: bson.D{{Key: "$set", Value: bson.D{{Key: "a", Value: 1}, {Key: "b.c", Value: 2}}}, {Key: "$unset", Value: bson.D{{Key: "d", Value: ""}}}},
+			want:   []string{"a", "b.c", "d"},
+		},
+		{
+			name:   "rename includes the target",
+			update: bson.D{{Key: "$rename", Value: bson.D{{Key: "a", Value: "b"}}}},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "replacement",
+			update: bson.D{{Key: "name", Value: "ann"}, {Key: "age", Value: 3}},
+			want:   []string{"name", "age"},
+		},
+		{
+			name: "pipeline",
+			update: bson.A{
+				bson.D{{Key: "$set", Value: bson.D{{Key: "a", Value: 1}}}},
+				bson.D{{Key: "$unset", Value: bson.A{"b", "c"}}},
+				bson.D{{Key: "$unset", Value: "d"}},
+			},
+			want: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:   "opaque pipeline stage",
+			update: bson.A{bson.D{{Key: "$replaceWith", Value: "$other"}}},
+			err:    ErrPolicyViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := updatePaths(tt.update)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && !slices.Equal(got, tt.want) {
+				t.Errorf("paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripRestricted(t *testing.T) {
+	const ns = "policy_test.strip"
+	RegisterPolicy(ns, Policy{
+		Roles: map[string][]string{"salary": {"hr"}, "items.price": {"sales"}, "profile.phone": {"hr"}},
+	})
+
+	doc := bson.D{
+		{Key: "name", Value: "ann"},
+		{Key: "salary", Value: 10},
+		{Key: "profile", Value: bson.D{{Key: "city", Value: "x"}, {Key: "phone", Value: "1"}}},
+		{Key: "items", Value: bson.A{
+			bson.D{{Key: "sku", Value: "a"}, {Key: "price", Value: 1}},
+			"scalar",
+		}},
+	}
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  bson.D
+	}{
+		{
+			name: "no roles",
+			want: bson.D{
+				{Key: "name", Value: "ann"},
+				{Key: "profile", Value: bson.D{{Key: "city", Value: "x"}}},
+				{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "a"}}, "scalar"}},
+			},
+		},
+		{
+			name:  "hr",
+			roles: []string{"hr"},
+			want: bson.D{
+				{Key: "name", Value: "ann"},
+				{Key: "salary", Value: 10},
+				{Key: "profile", Value: bson.D{{Key: "city", Value: "x"}, {Key: "phone", Value: "1"}}},
+				{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "a"}}, "scalar"}},
+			},
+		},
+		{
+			name:  "all roles",
+			roles: []string{"hr", "sales"},
+			want:  doc,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithRoles(context.Background(), tt.roles...)
+			if got := StripRestricted(ctx, ns, doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("doc = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if len(doc) != 4 || len(doc[2].Value.(bson.D)) != 2 {
+		t.Errorf("input document was modified: %v", doc)
+	}
+}
+
+func TestDecodeRestricted(t *testing.T) {
+	const ns = "policy_test.decode"
+	RegisterPolicy(ns, Policy{Roles: map[string][]string{"salary": {"hr"}}})
+
+	type user struct {
+		Name   string `bson:"name"`
+		Salary int    `bson:"salary"`
+	}
+	raw, err := bson.Marshal(user{Name: "ann", Salary: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		roles     []string
+		want      user
+	}{
+		{name: "restricted", namespace: ns, want: user{Name: "ann"}},
+		{name: "allowed", namespace: ns, roles: []string{"hr"}, want: user{Name: "ann", Salary: 10}},
+		{name: "no policy", namespace: "policy_test.none", want: user{Name: "ann", Salary: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got user
+			if err := DecodeRestricted(WithRoles(context.Background(), tt.roles...), tt.namespace, raw, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("decoded = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// PoolHooks 为连接池事件回调，未设置的回调会被跳过。
+type PoolHooks struct {
+	// OnCheckOutFailed 在获取连接失败时触发，Reason 为 timeout 时通常表示连接池已耗尽。
+	OnCheckOutFailed func(e *event.PoolEvent)
+	// OnPoolCleared 在节点出错导致连接池被清空时触发。
+	OnPoolCleared func(e *event.PoolEvent)
+	// OnConnectionClosed 在连接关闭时触发，Reason 说明关闭原因（idle、stale、error 等）。
+	OnConnectionClosed func(e *event.PoolEvent)
+	// OnEvent 接收全部连接池事件。
+	OnEvent func(e *event.PoolEvent)
+}
+
+// monitor 将回调转换为 driver 的连接池监控器。
+func (h PoolHooks) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCheckOutFailed:
+				if h.OnCheckOutFailed != nil {
+					h.OnCheckOutFailed(e)
+				}
+			case event.ConnectionPoolCleared:
+				if h.OnPoolCleared != nil {
+					h.OnPoolCleared(e)
+				}
+			case event.ConnectionClosed:
+				if h.OnConnectionClosed != nil {
+					h.OnConnectionClosed(e)
+				}
+			}
+			if h.OnEvent != nil {
+				h.OnEvent(e)
+			}
+		},
+	}
+}
+
+// chainPoolMonitors 将多个连接池监控器按顺序串联为一个（nil 监控器与 nil 回调会被跳过）。
+func chainPoolMonitors(monitors ...*event.PoolMonitor) *event.PoolMonitor {
+	var list []*event.PoolMonitor
+	for _, m := range monitors {
+		if m != nil && m.Event != nil {
+			list = append(list, m)
+		}
+	}
+	switch len(list) {
+	case 0:
+		return nil
+	case 1:
+		return list[0]
+	}
+
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			for _, m := range list {
+				m.Event(e)
+			}
+		},
+	}
+}
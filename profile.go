@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// Profile 为集合级默认操作参数，零值字段表示沿用 client/database 的配置。
+type Profile struct {
+	// ReadPreference 为读偏好。
+	ReadPreference *readpref.ReadPref
+	// WriteConcern 为写关注。
+	WriteConcern *writeconcern.WriteConcern
+	// MaxTime 为单次操作的最长执行时间，通过 ctx 超时生效（调用方 ctx 更短时以调用方为准）。
+	MaxTime time.Duration
+	// BatchSize 为游标批大小。
+	BatchSize int32
+}
+
+var profiles sync.Map
+
+// RegisterProfile 为集合注册默认参数，namespace 为 "库名.集合名"（见 PolicyNamespace），不同库的同名集合互不影响；
+// 重复注册时覆盖，helper 操作该集合时会自动应用。
+func RegisterProfile(namespace string, profile Profile) {
+	profiles.Store(namespace, profile)
+}
+
+// UnregisterProfile 移除集合（"库名.集合名"）的默认参数。
+func UnregisterProfile(namespace string) {
+	profiles.Delete(namespace)
+}
+
+// LookupProfile 返回集合（"库名.集合名"）注册的默认参数。
+func LookupProfile(namespace string) (Profile, bool) {
+	v, ok := profiles.Load(namespace)
+	if !ok {
+		return Profile{}, false
+	}
+	return v.(Profile), true
+}
+
+// Collection 返回应用了集合 Profile 读偏好与写关注的集合句柄，未注册时与 db.Collection 相同。
+func Collection(db *mongo.Database, name string) *mongo.Collection {
+	return profiledCollection(db.Collection(name))
+}
+
+func profiledCollection(collection *mongo.Collection) *mongo.Collection {
+	profile, ok := LookupProfile(PolicyNamespace(collection))
+	if !ok || (profile.ReadPreference == nil && profile.WriteConcern == nil) {
+		return collection
+	}
+
+	opts := options.Collection()
+	if profile.ReadPreference != nil {
+		opts.SetReadPreference(profile.ReadPreference)
+	}
+	if profile.WriteConcern != nil {
+		opts.SetWriteConcern(profile.WriteConcern)
+	}
+	return collection.Clone(opts)
+}
+
+// withProfile 供 helper 在操作前调用：返回应用了 Profile 的集合、带 MaxTime 的 ctx 及其 cancel。
+func withProfile(ctx context.Context, collection *mongo.Collection) (context.Context, *mongo.Collection, context.CancelFunc) {
+	profile, _ := LookupProfile(PolicyNamespace(collection))
+	collection = profiledCollection(collection)
+
+	if profile.MaxTime <= 0 {
+		return ctx, collection, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, profile.MaxTime)
+	return ctx, collection, cancel
+}
+
+// profileFind 返回带有集合 Profile 批大小的查询选项。
+func profileFind(collection *mongo.Collection) *options.FindOptionsBuilder {
+	opts := options.Find()
+	if profile, ok := LookupProfile(PolicyNamespace(collection)); ok && profile.BatchSize > 0 {
+		opts.SetBatchSize(profile.BatchSize)
+	}
+	return opts
+}
@@ -0,0 +1,555 @@
+package protobson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UnknownFieldsKey 为保存未知字段原始字节的文档键，保证旧版本服务读写新版本消息时不丢字段。
+const UnknownFieldsKey = "_unknown"
+
+// ErrUint64Overflow 表示 uint64 值超出 int64 范围：BSON 没有无符号整型，按 int64 保存会变为负数，破坏查询与排序。
+var ErrUint64Overflow = errors.New("protobson: uint64 value exceeds the int64 range")
+
+// timestampName 为 google.protobuf.Timestamp 的全名，字段为 seconds（编号 1）与 nanos（编号 2）。
+const timestampName protoreflect.FullName = "google.protobuf.Timestamp"
+
+// wrapperNames 为 wrapperspb 包装类型的全名，包装类型只有一个字段 value（编号 1）。
+var wrapperNames = map[protoreflect.FullName]bool{
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt64Value": true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.BytesValue":  true,
+}
+
+var (
+	tMessage      = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	tTimestamp    = reflect.TypeOf((*timestamppb.Timestamp)(nil))
+	tBsonRawValue = reflect.TypeOf(bson.RawValue{})
+)
+
+// NewRegistry 返回注册了 protobuf 编解码器的默认 Registry。
+func NewRegistry() *bson.Registry {
+	reg := bson.NewRegistry()
+	Register(reg)
+	return reg
+}
+
+// Register 向已有 Registry 注册 protobuf 消息、Timestamp 与 wrapperspb 编解码器。
+// Timestamp 以 BSON DateTime 保存，精度为毫秒，亚毫秒部分不会往返保留；wrapperspb 无论作为顶层值、
+// Go 结构体字段还是嵌套在消息中都保存为对应的标量。uint64 超出 int64 范围时返回 ErrUint64Overflow。
+func Register(reg *bson.Registry) {
+	c := &codec{}
+	reg.RegisterTypeEncoder(tTimestamp, bson.ValueEncoderFunc(c.encodeTimestamp))
+	reg.RegisterTypeDecoder(tTimestamp, bson.ValueDecoderFunc(c.decodeTimestamp))
+	reg.RegisterInterfaceEncoder(tMessage, c)
+	reg.RegisterInterfaceDecoder(tMessage, c)
+}
+
+// codec 基于 protoreflect 在 proto 消息与 BSON 文档之间转换。
+type codec struct {
+	names sync.Map // names 缓存消息类型对应的字段名映射：namesKey -> map[protoreflect.FieldNumber]string
+}
+
+// namesKey 为字段名缓存的键；dynamicpb 等实现的不同消息共享同一 Go 类型，需要同时按描述符区分。
+type namesKey struct {
+	t reflect.Type
+	d protoreflect.MessageDescriptor
+}
+
+// encodeTimestamp 将 Timestamp 保存为 BSON DateTime，与 time.Time 字段一致、可直接用于范围查询与 TTL 索引；
+// DateTime 精度为毫秒，亚毫秒部分（Nanos % 1e6）写入时丢弃。
+func (c *codec) encodeTimestamp(ec bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	if val.IsNil() {
+		return vw.WriteNull()
+	}
+	ts := val.Interface().(*timestamppb.Timestamp)
+	return vw.WriteDateTime(ts.AsTime().UnixMilli())
+}
+
+func (c *codec) decodeTimestamp(dc bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	switch vr.Type() {
+	case bson.TypeNull:
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	case bson.TypeDateTime:
+		ms, err := vr.ReadDateTime()
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(timestamppb.New(time.UnixMilli(ms))))
+		return nil
+	default:
+		return fmt.Errorf("protobson: cannot decode %v into Timestamp", vr.Type())
+	}
+}
+
+// EncodeValue 实现 bson.ValueEncoder。
+func (c *codec) EncodeValue(ec bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		return vw.WriteNull()
+	}
+	msg, ok := asMessage(val)
+	if !ok {
+		return errors.New("protobson: value is not a proto.Message")
+	}
+
+	// 与嵌套在消息中时一致：wrapperspb 保存为标量，其余消息保存为子文档。
+	value, err := c.toMessageValue(msg.ProtoReflect())
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(value)
+	enc, err := ec.LookupEncoder(rv.Type())
+	if err != nil {
+		return err
+	}
+	return enc.EncodeValue(ec, vw, rv)
+}
+
+// DecodeValue 实现 bson.ValueDecoder。
+func (c *codec) DecodeValue(dc bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	if vr.Type() == bson.TypeNull {
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	}
+
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		val.Set(reflect.New(val.Type().Elem()))
+	}
+	msg, ok := asMessage(val)
+	if !ok {
+		return errors.New("protobson: value is not a proto.Message")
+	}
+
+	dec, err := dc.LookupDecoder(tBsonRawValue)
+	if err != nil {
+		return err
+	}
+	raw := reflect.New(tBsonRawValue).Elem()
+	if err := dec.DecodeValue(dc, vr, raw); err != nil {
+		return err
+	}
+	rv := raw.Interface().(bson.RawValue)
+	if rv.Type == 0 {
+		// 顶层文档的 ValueReader 没有类型字节。
+		rv.Type = bson.TypeEmbeddedDocument
+	}
+
+	m := msg.ProtoReflect()
+	proto.Reset(msg)
+	ok, err = c.fromMessageValue(m, rv)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &DecodeError{Field: m.Descriptor().FullName(), Kind: protoreflect.MessageKind, Type: rv.Type}
+	}
+	return nil
+}
+
+// asMessage 取出 proto.Message，结构体值（可寻址）时取其地址。
+func asMessage(val reflect.Value) (proto.Message, bool) {
+	if val.Kind() != reflect.Ptr && val.CanAddr() {
+		val = val.Addr()
+	}
+	msg, ok := val.Interface().(proto.Message)
+	return msg, ok
+}
+
+// fieldNames 返回消息字段编号到文档键的映射：优先使用 Go 结构体上的 bson tag，否则使用 proto 字段名。
+func (c *codec) fieldNames(m protoreflect.Message) map[protoreflect.FieldNumber]string {
+	t := reflect.TypeOf(m.Interface())
+	key := namesKey{t: t, d: m.Descriptor()}
+	if v, ok := c.names.Load(key); ok {
+		return v.(map[protoreflect.FieldNumber]string)
+	}
+
+	names := make(map[protoreflect.FieldNumber]string)
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		names[fd.Number()] = string(fd.Name())
+	}
+
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		st := t.Elem()
+		for i := 0; i < st.NumField(); i++ {
+			sf := st.Field(i)
+			name, ok := protoTagName(sf.Tag.Get("protobuf"))
+			if !ok {
+				continue
+			}
+			fd := fields.ByName(protoreflect.Name(name))
+			if fd == nil {
+				continue
+			}
+			if tag, ok := sf.Tag.Lookup("bson"); ok {
+				if key, _, _ := strings.Cut(tag, ","); key != "" {
+					names[fd.Number()] = key
+				}
+			}
+		}
+	}
+
+	c.names.Store(key, names)
+	return names
+}
+
+func protoTagName(tag string) (string, bool) {
+	for _, part := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (c *codec) toDocument(m protoreflect.Message) (bson.D, error) {
+	names := c.fieldNames(m)
+	doc := bson.D{}
+
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		key := names[fd.Number()]
+		if key == "-" {
+			return true
+		}
+
+		var value any
+		switch {
+		case fd.IsList():
+			list := v.List()
+			arr := make(bson.A, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				item, e := c.toValue(fd, list.Get(i))
+				if e != nil {
+					err = e
+					return false
+				}
+				arr = append(arr, item)
+			}
+			value = arr
+		case fd.IsMap():
+			sub := bson.D{}
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				item, e := c.toValue(fd.MapValue(), mv)
+				if e != nil {
+					err = e
+					return false
+				}
+				sub = append(sub, bson.E{Key: k.String(), Value: item})
+				return true
+			})
+			value = sub
+		default:
+			value, err = c.toValue(fd, v)
+		}
+		if err != nil {
+			return false
+		}
+
+		doc = append(doc, bson.E{Key: key, Value: value})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if unknown := m.GetUnknown(); len(unknown) != 0 {
+		doc = append(doc, bson.E{Key: UnknownFieldsKey, Value: bson.Binary{Data: unknown}})
+	}
+
+	return doc, nil
+}
+
+// toValue 将单个 protoreflect 值转换为 BSON 可编码的 Go 值。
+func (c *codec) toValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return int32(v.Int()), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int(), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return int64(v.Uint()), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return uint64Value(v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float(), nil
+	case protoreflect.StringKind:
+		return v.String(), nil
+	case protoreflect.BytesKind:
+		return bson.Binary{Data: v.Bytes()}, nil
+	case protoreflect.EnumKind:
+		return int32(v.Enum()), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return c.toMessageValue(v.Message())
+	default:
+		return nil, fmt.Errorf("protobson: unsupported field kind %v", fd.Kind())
+	}
+}
+
+// toMessageValue 处理消息值：Timestamp 转为时间（按 DateTime 保存，精度为毫秒），wrapperspb 转为标量，其余递归为子文档。
+// 按描述符全名识别，生成代码与 dynamicpb 等实现的消息处理一致。
+func (c *codec) toMessageValue(m protoreflect.Message) (any, error) {
+	d := m.Descriptor()
+	switch {
+	case d.FullName() == timestampName:
+		fields := d.Fields()
+		return time.Unix(m.Get(fields.ByNumber(1)).Int(), m.Get(fields.ByNumber(2)).Int()).UTC(), nil
+	case wrapperNames[d.FullName()]:
+		fd := d.Fields().ByNumber(1)
+		return c.toValue(fd, m.Get(fd))
+	}
+	return c.toDocument(m)
+}
+
+// uint64Value 将 uint64 保存为 int64，超出 int64 范围时返回 ErrUint64Overflow。
+func uint64Value(n uint64) (any, error) {
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: %d", ErrUint64Overflow, n)
+	}
+	return int64(n), nil
+}
+
+func (c *codec) fromDocument(m protoreflect.Message, raw bson.Raw) error {
+	names := c.fieldNames(m)
+	fields := m.Descriptor().Fields()
+
+	byKey := make(map[string]protoreflect.FieldDescriptor, len(names))
+	for number, key := range names {
+		byKey[key] = fields.ByNumber(number)
+	}
+
+	elems, err := raw.Elements()
+	if err != nil {
+		return err
+	}
+
+	for _, elem := range elems {
+		key := elem.Key()
+		rv := elem.Value()
+
+		if key == UnknownFieldsKey {
+			if _, data, ok := rv.BinaryOK(); ok {
+				m.SetUnknown(data)
+			}
+			continue
+		}
+
+		fd, ok := byKey[key]
+		if !ok || rv.Type == bson.TypeNull {
+			continue
+		}
+
+		switch {
+		case fd.IsList():
+			arr, ok := rv.ArrayOK()
+			if !ok {
+				return fmt.Errorf("protobson: field %q is not an array", key)
+			}
+			values, err := arr.Values()
+			if err != nil {
+				return err
+			}
+			list := m.Mutable(fd).List()
+			for _, item := range values {
+				v, err := c.fromValue(fd, list.NewElement(), item)
+				if err != nil {
+					return err
+				}
+				list.Append(v)
+			}
+		case fd.IsMap():
+			sub, ok := rv.DocumentOK()
+			if !ok {
+				return fmt.Errorf("protobson: field %q is not a document", key)
+			}
+			entries, err := sub.Elements()
+			if err != nil {
+				return err
+			}
+			mp := m.Mutable(fd).Map()
+			for _, entry := range entries {
+				mk, err := mapKey(fd.MapKey(), entry.Key())
+				if err != nil {
+					return err
+				}
+				v, err := c.fromValue(fd.MapValue(), mp.NewValue(), entry.Value())
+				if err != nil {
+					return err
+				}
+				mp.Set(mk, v)
+			}
+		default:
+			var zero protoreflect.Value
+			if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+				zero = m.NewField(fd)
+			}
+			v, err := c.fromValue(fd, zero, rv)
+			if err != nil {
+				return err
+			}
+			m.Set(fd, v)
+		}
+	}
+
+	return nil
+}
+
+// DecodeError 表示文档中字段的 BSON 类型与 proto 字段类型不兼容（无模式集合中的历史数据或脏数据）。
+type DecodeError struct {
+	// Field 为 proto 字段全名。
+	Field protoreflect.FullName
+	// Kind 为 proto 字段类型。
+	Kind protoreflect.Kind
+	// Type 为文档中实际存储的 BSON 类型。
+	Type bson.Type
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("protobson: cannot decode %v into %s (%v)", e.Type, e.Field, e.Kind)
+}
+
+func decodeError(fd protoreflect.FieldDescriptor, rv bson.RawValue) error {
+	return &DecodeError{Field: fd.FullName(), Kind: fd.Kind(), Type: rv.Type}
+}
+
+// fromValue 将 BSON 值转换为 protoreflect 值，消息类型写入 zero 所指向的新消息。
+// 整型字段兼容 int32/int64/double，浮点字段兼容任意数值类型；无符号字段遇到负数或超出范围的值、
+// 其余类型不匹配时返回 *DecodeError。
+func (c *codec) fromValue(fd protoreflect.FieldDescriptor, zero protoreflect.Value, rv bson.RawValue) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if b, ok := rv.BooleanOK(); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n, ok := rv.AsInt64OK(); ok {
+			return protoreflect.ValueOfInt32(int32(n)), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if n, ok := rv.AsInt64OK(); ok {
+			return protoreflect.ValueOfInt64(n), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if n, ok := rv.AsInt64OK(); ok && n >= 0 && n <= math.MaxUint32 {
+			return protoreflect.ValueOfUint32(uint32(n)), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if n, ok := rv.AsInt64OK(); ok && n >= 0 {
+			return protoreflect.ValueOfUint64(uint64(n)), nil
+		}
+	case protoreflect.FloatKind:
+		if f, ok := rv.AsFloat64OK(); ok {
+			return protoreflect.ValueOfFloat32(float32(f)), nil
+		}
+	case protoreflect.DoubleKind:
+		if f, ok := rv.AsFloat64OK(); ok {
+			return protoreflect.ValueOfFloat64(f), nil
+		}
+	case protoreflect.StringKind:
+		if str, ok := rv.StringValueOK(); ok {
+			return protoreflect.ValueOfString(str), nil
+		}
+	case protoreflect.BytesKind:
+		if _, data, ok := rv.BinaryOK(); ok {
+			return protoreflect.ValueOfBytes(data), nil
+		}
+	case protoreflect.EnumKind:
+		if n, ok := rv.AsInt64OK(); ok {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		m := zero.Message()
+		ok, err := c.fromMessageValue(m, rv)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		if ok {
+			return protoreflect.ValueOfMessage(m), nil
+		}
+	default:
+		return protoreflect.Value{}, fmt.Errorf("protobson: unsupported field kind %v", fd.Kind())
+	}
+	return protoreflect.Value{}, decodeError(fd, rv)
+}
+
+// fromMessageValue 将 BSON 值写入消息 m：Timestamp 与 wrapperspb 读取标量，其余读取子文档；类型不匹配时返回 false。
+func (c *codec) fromMessageValue(m protoreflect.Message, rv bson.RawValue) (bool, error) {
+	d := m.Descriptor()
+	switch {
+	case d.FullName() == timestampName:
+		t, ok := rv.TimeOK()
+		if !ok {
+			return false, nil
+		}
+		fields := d.Fields()
+		m.Set(fields.ByNumber(1), protoreflect.ValueOfInt64(t.Unix()))
+		m.Set(fields.ByNumber(2), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+		return true, nil
+	case wrapperNames[d.FullName()]:
+		fd := d.Fields().ByNumber(1)
+		v, err := c.fromValue(fd, protoreflect.Value{}, rv)
+		var de *DecodeError
+		if errors.As(err, &de) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		m.Set(fd, v)
+		return true, nil
+	}
+
+	doc, ok := rv.DocumentOK()
+	if !ok {
+		return false, nil
+	}
+	return true, c.fromDocument(m, doc)
+}
+
+func mapKey(fd protoreflect.FieldDescriptor, key string) (protoreflect.MapKey, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(key).MapKey(), nil
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(key == "true").MapKey(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var v int32
+		_, err := fmt.Sscan(key, &v)
+		return protoreflect.ValueOfInt32(v).MapKey(), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var v int64
+		_, err := fmt.Sscan(key, &v)
+		return protoreflect.ValueOfInt64(v).MapKey(), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var v uint32
+		_, err := fmt.Sscan(key, &v)
+		return protoreflect.ValueOfUint32(v).MapKey(), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var v uint64
+		_, err := fmt.Sscan(key, &v)
+		return protoreflect.ValueOfUint64(v).MapKey(), err
+	default:
+		return protoreflect.MapKey{}, fmt.Errorf("protobson: unsupported map key kind %v", fd.Kind())
+	}
+}
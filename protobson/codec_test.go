@@ -0,0 +1,331 @@
+package protobson
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// orderDescriptor 构造测试用消息：
+//
+//	message Item  { string sku = 1; uint64 qty = 2; }
+//	message Order {
+//	  string id = 1; uint64 big = 2; uint32 small = 3; bytes blob = 4; double ratio = 5;
+//	  repeated Item items = 6; map<string, Item> by_sku = 7;
+//	  google.protobuf.StringValue note = 8; repeated google.protobuf.Int64Value counts = 9;
+//	  map<string, google.protobuf.UInt64Value> totals = 10; google.protobuf.Timestamp at = 11;
+//	}
+func orderDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    label.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	entry := func(name, value string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+				field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, value, false),
+			},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+	}
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("protobson_test.proto"),
+		Package:    proto.String("protobson.test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/wrappers.proto", "google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					field("qty", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT64, "", false),
+				},
+			},
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					field("big", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT64, "", false),
+					field("small", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT32, "", false),
+					field("blob", 4, descriptorpb.FieldDescriptorProto_TYPE_BYTES, "", false),
+					field("ratio", 5, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, "", false),
+					field("items", 6, msg, ".protobson.test.Item", true),
+					field("by_sku", 7, msg, ".protobson.test.Order.BySkuEntry", true),
+					field("note", 8, msg, ".google.protobuf.StringValue", false),
+					field("counts", 9, msg, ".google.protobuf.Int64Value", true),
+					field("totals", 10, msg, ".protobson.test.Order.TotalsEntry", true),
+					field("at", 11, msg, ".google.protobuf.Timestamp", false),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					entry("BySkuEntry", ".protobson.test.Item"),
+					entry("TotalsEntry", ".google.protobuf.UInt64Value"),
+				},
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("Order")
+}
+
+func marshal(t *testing.T, v any) ([]byte, error) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	enc := bson.NewEncoder(bson.NewDocumentWriter(buf))
+	enc.SetRegistry(NewRegistry())
+	err := enc.Encode(v)
+	return buf.Bytes(), err
+}
+
+func unmarshal(data []byte, v any) error {
+	dec := bson.NewDecoder(bson.NewDocumentReader(bytes.NewReader(data)))
+	dec.SetRegistry(NewRegistry())
+	return dec.Decode(v)
+}
+
+func TestRoundTrip(t *testing.T) {
+	desc := orderDescriptor(t)
+	fields := desc.Fields()
+	at := time.Date(2026, 3, 4, 5, 6, 7, 8_000_000, time.UTC)
+
+	newOrder := func(set func(m *dynamicpb.Message)) *dynamicpb.Message {
+		m := dynamicpb.NewMessage(desc)
+		set(m)
+		return m
+	}
+	item := func(sku string, qty uint64) protoreflect.Value {
+		m := dynamicpb.NewMessage(fields.ByName("items").Message())
+		m.Set(m.Descriptor().Fields().ByName("sku"), protoreflect.ValueOfString(sku))
+		m.Set(m.Descriptor().Fields().ByName("qty"), protoreflect.ValueOfUint64(qty))
+		return protoreflect.ValueOfMessage(m)
+	}
+
+	tests := []struct {
+		name  string
+		order *dynamicpb.Message
+		// stored 校验写入的 BSON 文档。
+		stored func(t *testing.T, doc bson.Raw)
+		err    error
+	}{
+		{
+			name: "scalars",
+			order: newOrder(func(m *dynamicpb.Message) {
+				m.Set(fields.ByName("id"), protoreflect.ValueOfString("o1"))
+				m.Set(fields.ByName("big"), protoreflect.ValueOfUint64(math.MaxInt64))
+				m.Set(fields.ByName("small"), protoreflect.ValueOfUint32(math.MaxUint32))
+				m.Set(fields.ByName("blob"), protoreflect.ValueOfBytes([]byte{1, 2}))
+				m.Set(fields.ByName("ratio"), protoreflect.ValueOfFloat64(0.5))
+			}),
+			stored: func(t *testing.T, doc bson.Raw) {
+				if n, ok := doc.Lookup("big").Int64OK(); !ok || n != math.MaxInt64 {
+					t.Errorf("big = %v", doc.Lookup("big"))
+				}
+			},
+		},
+		{
+			name: "nested messages in lists and maps",
+			order: newOrder(func(m *dynamicpb.Message) {
+				items := m.Mutable(fields.ByName("items")).List()
+				items.Append(item("a", 1))
+				items.Append(item("b", 2))
+				m.Mutable(fields.ByName("by_sku")).Map().Set(protoreflect.ValueOfString("a").MapKey(), item("a", 1))
+			}),
+			stored: func(t *testing.T, doc bson.Raw) {
+				if sku := doc.Lookup("by_sku", "a", "sku").StringValue(); sku != "a" {
+					t.Errorf("by_sku.a.sku = %q", sku)
+				}
+			},
+		},
+		{
+			name: "wrappers are scalars at every depth",
+			order: newOrder(func(m *dynamicpb.Message) {
+				note := dynamicpb.NewMessage(fields.ByName("note").Message())
+				note.Set(note.Descriptor().Fields().ByNumber(1), protoreflect.ValueOfString("hi"))
+				m.Set(fields.ByName("note"), protoreflect.ValueOfMessage(note))
+
+				counts := m.Mutable(fields.ByName("counts")).List()
+				for _, n := range []int64{3, 0} {
+					c := dynamicpb.NewMessage(fields.ByName("counts").Message())
+					c.Set(c.Descriptor().Fields().ByNumber(1), protoreflect.ValueOfInt64(n))
+					counts.Append(protoreflect.ValueOfMessage(c))
+				}
+
+				total := dynamicpb.NewMessage(fields.ByName("totals").MapValue().Message())
+				total.Set(total.Descriptor().Fields().ByNumber(1), protoreflect.ValueOfUint64(7))
+				m.Mutable(fields.ByName("totals")).Map().Set(protoreflect.ValueOfString("x").MapKey(), protoreflect.ValueOfMessage(total))
+			}),
+			stored: func(t *testing.T, doc bson.Raw) {
+				if v := doc.Lookup("note").StringValue(); v != "hi" {
+					t.Errorf("note = %v", doc.Lookup("note"))
+				}
+				if v, ok := doc.Lookup("counts").Array().Index(0).Int64OK(); !ok || v != 3 {
+					t.Errorf("counts = %v", doc.Lookup("counts"))
+				}
+				if v, ok := doc.Lookup("totals", "x").Int64OK(); !ok || v != 7 {
+					t.Errorf("totals = %v", doc.Lookup("totals"))
+				}
+			},
+		},
+		{
+			name: "timestamp",
+			order: newOrder(func(m *dynamicpb.Message) {
+				ts := dynamicpb.NewMessage(fields.ByName("at").Message())
+				ts.Set(ts.Descriptor().Fields().ByNumber(1), protoreflect.ValueOfInt64(at.Unix()))
+				ts.Set(ts.Descriptor().Fields().ByNumber(2), protoreflect.ValueOfInt32(int32(at.Nanosecond())))
+				m.Set(fields.ByName("at"), protoreflect.ValueOfMessage(ts))
+			}),
+			stored: func(t *testing.T, doc bson.Raw) {
+				if v, ok := doc.Lookup("at").TimeOK(); !ok || !v.Equal(at) {
+					t.Errorf("at = %v", doc.Lookup("at"))
+				}
+			},
+		},
+		{
+			name: "uint64 above int64",
+			order: newOrder(func(m *dynamicpb.Message) {
+				m.Set(fields.ByName("big"), protoreflect.ValueOfUint64(math.MaxInt64+1))
+			}),
+			err: ErrUint64Overflow,
+		},
+		{
+			name: "uint64 above int64 in a nested message",
+			order: newOrder(func(m *dynamicpb.Message) {
+				m.Mutable(fields.ByName("items")).List().Append(item("a", math.MaxUint64))
+			}),
+			err: ErrUint64Overflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := marshal(t, tt.order)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			tt.stored(t, data)
+
+			got := dynamicpb.NewMessage(desc)
+			if err := unmarshal(data, got); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, tt.order) {
+				t.Errorf("round trip = %v, want %v", got, tt.order)
+			}
+		})
+	}
+}
+
+// TestWrapperField 确认 wrapperspb 作为 Go 结构体字段时与嵌套在消息中一样保存为标量。
+func TestWrapperField(t *testing.T) {
+	type doc struct {
+		Note  *wrapperspb.StringValue `bson:"note"`
+		Count *wrapperspb.UInt64Value `bson:"count"`
+		At    *timestamppb.Timestamp  `bson:"at"`
+	}
+
+	tests := []struct {
+		name string
+		in   doc
+		err  error
+	}{
+		{name: "values", in: doc{Note: wrapperspb.String("hi"), Count: wrapperspb.UInt64(5), At: timestamppb.New(time.UnixMilli(1700000000123))}},
+		{name: "nil wrappers", in: doc{}},
+		{name: "zero wrapper", in: doc{Note: wrapperspb.String(""), Count: wrapperspb.UInt64(0)}},
+		{name: "uint64 overflow", in: doc{Count: wrapperspb.UInt64(math.MaxUint64)}, err: ErrUint64Overflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := marshal(t, tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			raw := bson.Raw(data)
+			if tt.in.Note != nil {
+				if v, ok := raw.Lookup("note").StringValueOK(); !ok || v != tt.in.Note.GetValue() {
+					t.Errorf("note stored as %v", raw.Lookup("note"))
+				}
+			} else if raw.Lookup("note").Type != bson.TypeNull {
+				t.Errorf("nil note stored as %v", raw.Lookup("note"))
+			}
+
+			var got doc
+			if err := unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got.Note, tt.in.Note) || !proto.Equal(got.Count, tt.in.Count) || !proto.Equal(got.At, tt.in.At) {
+				t.Errorf("round trip = %+v, want %+v", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestDecodeMismatch(t *testing.T) {
+	desc := orderDescriptor(t)
+
+	tests := []struct {
+		name  string
+		doc   bson.D
+		field protoreflect.FullName
+	}{
+		{name: "negative uint64", doc: bson.D{{Key: "big", Value: int64(-1)}}, field: "protobson.test.Order.big"},
+		{name: "uint32 out of range", doc: bson.D{{Key: "small", Value: int64(math.MaxUint32 + 1)}}, field: "protobson.test.Order.small"},
+		{name: "wrapper of another type", doc: bson.D{{Key: "note", Value: int32(1)}}, field: "protobson.test.Order.note"},
+		{name: "negative wrapped uint64", doc: bson.D{{Key: "totals", Value: bson.D{{Key: "x", Value: int64(-1)}}}}, field: "protobson.test.Order.TotalsEntry.value"},
+		{name: "timestamp as string", doc: bson.D{{Key: "at", Value: "yesterday"}}, field: "protobson.test.Order.at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(tt.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var de *DecodeError
+			if err := unmarshal(data, dynamicpb.NewMessage(desc)); !errors.As(err, &de) || de.Field != tt.field {
+				t.Errorf("err = %v, want a DecodeError for %s", err, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package mongo
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrProxy 表示代理握手失败或代理拒绝建立到目标节点的连接。
+var ErrProxy = errors.New("mongo: proxy")
+
+// proxyDialer 经由 SOCKS5 或 HTTP CONNECT 代理建立到 Mongo 节点的连接。
+type proxyDialer struct {
+	proxy  *url.URL
+	dialer options.ContextDialer
+}
+
+// newProxyDialer 解析代理地址：socks5://（本地解析节点域名）、socks5h://（由代理解析）、http://（CONNECT），
+// 用户名与密码写在 URL 中。
+func newProxyDialer(rawURL string, dialer options.ContextDialer) (*proxyDialer, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// url.Error 会带上原始地址，避免把密码写进错误信息。
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%w: invalid url: %v", ErrProxy, err)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrProxy, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: host is empty", ErrProxy)
+	}
+	if u.Port() == "" {
+		port := "1080"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	return &proxyDialer{proxy: u, dialer: dialer}, nil
+}
+
+func (d *proxyDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, d.proxy.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	// 握手受 ctx 控制：截止时间作用于读写，取消时打断阻塞的读写。
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+
+	// tunnel 为握手成功后返回的连接（HTTP 代理可能包装了已缓冲的数据），失败时关闭原始连接。
+	tunnel := conn
+	if d.proxy.Scheme == "http" {
+		var hc net.Conn
+		if hc, err = d.connectHTTP(conn, address); err == nil {
+			tunnel = hc
+		}
+	} else {
+		err = d.connectSOCKS5(ctx, conn, address)
+	}
+
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return tunnel, nil
+}
+
+// connectSOCKS5 完成 RFC 1928 握手，配置了用户名时使用 RFC 1929 用户名密码认证。
+func (d *proxyDialer) connectSOCKS5(ctx context.Context, conn net.Conn, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("%w: invalid port %q", ErrProxy, portStr)
+	}
+
+	user := d.proxy.User
+	methods := []byte{0x00}
+	if user != nil {
+		methods = []byte{0x02}
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 || reply[1] != methods[0] {
+		return fmt.Errorf("%w: no acceptable authentication method", ErrProxy)
+	}
+
+	if user != nil {
+		password, _ := user.Password()
+		if len(user.Username()) > 255 || len(password) > 255 {
+			return fmt.Errorf("%w: username or password too long", ErrProxy)
+		}
+		req := []byte{0x01, byte(len(user.Username()))}
+		req = append(req, user.Username()...)
+		req = append(req, byte(len(password)))
+		req = append(req, password...)
+		if _, err := conn.Write(req); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return fmt.Errorf("%w: authentication failed", ErrProxy)
+		}
+	}
+
+	req := []byte{0x05, 0x01, 0x00}
+	ip := net.ParseIP(host)
+	if ip == nil && d.proxy.Scheme == "socks5" {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return err
+		}
+		if len(addrs) == 0 {
+			return fmt.Errorf("%w: no address for %s", ErrProxy, host)
+		}
+		ip = addrs[0].IP
+	}
+	switch {
+	case ip == nil:
+		if len(host) > 255 {
+			return fmt.Errorf("%w: host name too long", ErrProxy)
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	case ip.To4() != nil:
+		req = append(req, 0x01)
+		req = append(req, ip.To4()...)
+	default:
+		req = append(req, 0x04)
+		req = append(req, ip.To16()...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// 应答：VER REP RSV ATYP BND.ADDR BND.PORT，绑定地址不使用，读完丢弃。
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return err
+	}
+	if head[1] != 0x00 {
+		return fmt.Errorf("%w: connect %s failed with code %d", ErrProxy, address, head[1])
+	}
+	var skip int
+	switch head[3] {
+	case 0x01:
+		skip = net.IPv4len + 2
+	case 0x04:
+		skip = net.IPv6len + 2
+	case 0x03:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return err
+		}
+		skip = int(size[0]) + 2
+	default:
+		return fmt.Errorf("%w: unknown address type %d", ErrProxy, head[3])
+	}
+	_, err = io.CopyN(io.Discard, conn, int64(skip))
+	return err
+}
+
+// connectHTTP 通过 HTTP CONNECT 建立隧道。
+func (d *proxyDialer) connectHTTP(conn net.Conn, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := d.proxy.User; user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: connect %s: %s", ErrProxy, address, resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn 先读取 CONNECT 应答后已缓冲的数据。
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
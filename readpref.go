@@ -0,0 +1,30 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/tag"
+)
+
+// readPref 根据 Conf 构造读偏好，未配置 ReadPreference 时返回 nil（沿用 driver 默认的 primary）。
+func (c *Conf) readPref() (*readpref.ReadPref, error) {
+	if c.ReadPreference == "" {
+		return nil, nil
+	}
+
+	mode, err := readpref.ModeFromString(c.ReadPreference)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []readpref.Option
+	if len(c.ReadPreferenceTags) != 0 {
+		opts = append(opts, readpref.WithTagSets(tag.NewTagSetsFromMaps(c.ReadPreferenceTags)...))
+	}
+	if c.MaxStaleness > 0 {
+		opts = append(opts, readpref.WithMaxStaleness(time.Second*time.Duration(c.MaxStaleness)))
+	}
+
+	return readpref.New(mode, opts...)
+}
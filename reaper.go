@@ -0,0 +1,175 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ReapMode 为到期文档的处理方式。
+type ReapMode uint8
+
+const (
+	// ReapDelete 物理删除到期文档。
+	ReapDelete ReapMode = iota
+	// ReapSoftDelete 写入 deleted_at 软删除到期文档。
+	ReapSoftDelete
+)
+
+// ReaperConf 为 Reaper 的配置。
+type ReaperConf struct {
+	// Field 为到期时间字段（为空时默认 expires_at）。
+	Field string
+	// Grace 为到期后的宽限期，到期时间早于 now-Grace 的文档才会被处理。
+	Grace time.Duration
+	// BatchSize 为单批处理的文档数（<=0 时默认 100）。
+	BatchSize int
+	// Interval 为 Run 的扫描间隔（<=0 时默认 1m）。
+	Interval time.Duration
+	// Mode 为到期文档的处理方式。
+	Mode ReapMode
+	// OnExpire 在删除前以批为单位回调，返回错误时本批不删除并停止本轮扫描，下次扫描会重新回调。
+	// 多实例同时运行时同一文档可能被回调多次，回调应保持幂等。
+	OnExpire func(ctx context.Context, docs []bson.Raw) error
+}
+
+// Reaper 扫描 expires_at 到期的文档，回调后分批删除或软删除，用于需要感知到期事件而非由 TTL 索引静默删除的场景。
+type Reaper struct {
+	collection *mongo.Collection
+	conf       ReaperConf
+}
+
+// NewReaper 创建 Reaper。
+func NewReaper(collection *mongo.Collection, conf *ReaperConf) *Reaper {
+	c := ReaperConf{}
+	if conf != nil {
+		c = *conf
+	}
+	if c.Field == "" {
+		c.Field = "expires_at"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Minute
+	}
+
+	return &Reaper{collection: collection, conf: c}
+}
+
+// EnsureIndexes 在到期字段上创建索引，避免扫描退化为全表扫描。
+func (r *Reaper) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: r.conf.Field, Value: 1}},
+	})
+	return err
+}
+
+// Run 按 Interval 周期执行 RunOnce，直到 ctx 取消。
+func (r *Reaper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 分批处理当前所有到期文档，返回实际删除或软删除的文档数。
+func (r *Reaper) RunOnce(ctx context.Context) (int64, error) {
+	cutoff := time.Now().UTC().Add(-r.conf.Grace)
+	filter := bson.D{{Key: r.conf.Field, Value: bson.D{{Key: "$lte", Value: cutoff}}}}
+	if r.conf.Mode == ReapSoftDelete {
+		filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
+	}
+
+	var total int64
+	for {
+		found, affected, err := r.batch(ctx, filter)
+		total += affected
+		if err != nil || found < r.conf.BatchSize {
+			return total, err
+		}
+	}
+}
+
+// batch 以 reap 操作经过拦截器后处理一批到期文档，返回查到的文档数与实际处理的文档数。
+func (r *Reaper) batch(ctx context.Context, filter bson.D) (_ int, _ int64, err error) {
+	op := &Operation{Name: "reap", Filter: filter}
+	ctx, finish, err := intercept(ctx, r.collection, op)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { finish(err) }()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: r.conf.Field, Value: 1}}).
+		SetLimit(int64(r.conf.BatchSize))
+	if r.conf.OnExpire == nil {
+		opts.SetProjection(bson.D{{Key: "_id", Value: 1}})
+	}
+
+	cursor, err := r.collection.Find(ctx, op.Filter, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var docs []bson.Raw
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, 0, err
+	}
+	if len(docs) == 0 {
+		return 0, 0, nil
+	}
+
+	if r.conf.OnExpire != nil {
+		if err := r.conf.OnExpire(ctx, docs); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	ids := make(bson.A, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.Lookup("_id"))
+	}
+	// 保留到期条件，避免删除在回调期间被续期的文档。
+	target := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "$and", Value: bson.A{op.Filter}},
+	}
+
+	ctx, collection, cancel := withWrite(ctx, r.collection)
+	defer cancel()
+
+	if r.conf.Mode == ReapSoftDelete {
+		timer := time.Now().UTC()
+		result, err := collection.UpdateMany(ctx, target, bson.D{
+			{Key: "$set", Value: bson.M{
+				"updated_at": timer,
+				"deleted_at": timer,
+			}},
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		return len(docs), result.ModifiedCount, nil
+	}
+
+	result, err := collection.DeleteMany(ctx, target)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(docs), result.DeletedCount, nil
+}
@@ -0,0 +1,128 @@
+package mongo
+
+import (
+	"path"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RedactedValue 为日志中被脱敏字段的替换值。
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactFields 为默认脱敏的字段名模式。
+var DefaultRedactFields = []string{"*password*", "*passwd*", "*token*", "*secret*", "ssn"}
+
+// RedactConf 为命令日志的敏感字段脱敏配置，零值为按 DefaultRedactFields 脱敏。
+type RedactConf struct {
+	// Disabled 为 true 时记录完整命令。
+	Disabled bool `json:"disabled"`
+	// Fields 为字段名模式（不区分大小写，支持 path.Match 通配符，如 "*token*"），为空时使用 DefaultRedactFields。
+	// 模式只匹配字段名本身，点路径键（如 "profile.password"）按最后一段匹配。
+	Fields []string `json:"fields"`
+}
+
+// redactor 在命令写入日志前替换敏感字段的值。
+type redactor struct {
+	patterns []string
+}
+
+// redactor 返回命令日志使用的脱敏器，Redact.Disabled 时返回 nil。
+func (c *Conf) redactor() *redactor {
+	conf := c.Redact
+	if conf == nil {
+		conf = &RedactConf{}
+	}
+	if conf.Disabled {
+		return nil
+	}
+
+	fields := conf.Fields
+	if len(fields) == 0 {
+		fields = DefaultRedactFields
+	}
+	r := &redactor{patterns: make([]string, 0, len(fields))}
+	for _, field := range fields {
+		r.patterns = append(r.patterns, strings.ToLower(field))
+	}
+	return r
+}
+
+// statement 返回脱敏后的命令文本，未命中任何字段时直接返回原命令文本。
+func (r *redactor) statement(cmd bson.Raw) string {
+	if r == nil {
+		return cmd.String()
+	}
+	doc, changed := r.document(cmd)
+	if !changed {
+		return cmd.String()
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		// 无法重新编码时宁可丢弃命令内容，也不输出原文。
+		return RedactedValue
+	}
+	return bson.Raw(raw).String()
+}
+
+// document 递归处理文档，changed 表示是否有字段被替换。
+func (r *redactor) document(doc bson.Raw) (bson.D, bool) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil, false
+	}
+
+	out := make(bson.D, 0, len(elems))
+	changed := false
+	for _, elem := range elems {
+		key := elem.Key()
+		if r.match(key) {
+			out = append(out, bson.E{Key: key, Value: RedactedValue})
+			changed = true
+			continue
+		}
+		value, ok := r.value(elem.Value())
+		changed = changed || ok
+		out = append(out, bson.E{Key: key, Value: value})
+	}
+	return out, changed
+}
+
+// value 处理嵌套文档与数组，其余值原样返回。
+func (r *redactor) value(v bson.RawValue) (any, bool) {
+	switch v.Type {
+	case bson.TypeEmbeddedDocument:
+		if doc, changed := r.document(v.Document()); changed {
+			return doc, true
+		}
+	case bson.TypeArray:
+		values, err := v.Array().Values()
+		if err != nil {
+			return v, false
+		}
+		arr := make(bson.A, 0, len(values))
+		changed := false
+		for _, item := range values {
+			value, ok := r.value(item)
+			changed = changed || ok
+			arr = append(arr, value)
+		}
+		if changed {
+			return arr, true
+		}
+	}
+	return v, false
+}
+
+func (r *redactor) match(key string) bool {
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = key[i+1:]
+	}
+	key = strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,352 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrNotReadOnly 表示命令不是只读命令，回放时会被跳过。
+var ErrNotReadOnly = errors.New("replay: command is not read-only")
+
+// ErrRedacted 表示命令中含有脱敏后的值（Conf.Redact），回放得到的不是原始查询，会被跳过。
+var ErrRedacted = errors.New("replay: command contains redacted values")
+
+// ErrTruncated 表示命令文本被截断（Conf.MaxStatementLength），无法还原为完整命令，会被跳过。
+var ErrTruncated = errors.New("replay: command is truncated")
+
+// redactedValue 与 mongo.RedactedValue 一致，为日志中被脱敏字段的替换值。
+const redactedValue = "[REDACTED]"
+
+// truncatedSuffix 匹配被截断的命令文本末尾追加的原始大小标记。
+var truncatedSuffix = regexp.MustCompile(`\.\.\.\[truncated, \d+ bytes total\]$`)
+
+// readOnlyCommands 为允许回放的只读命令。
+var readOnlyCommands = map[string]bool{
+	"find":      true,
+	"aggregate": true,
+	"count":     true,
+	"distinct":  true,
+}
+
+// sessionFields 为与原始会话绑定、回放时需要移除的字段。
+var sessionFields = map[string]bool{
+	"$db":              true,
+	"lsid":             true,
+	"txnNumber":        true,
+	"autocommit":       true,
+	"startTransaction": true,
+	"$clusterTime":     true,
+	"$readPreference":  true,
+	"readConcern":      true,
+}
+
+// Statement 为一条可回放的命令。
+type Statement struct {
+	Database string
+	Name     string
+	Command  bson.D
+}
+
+// Parse 将 OperationLogger 中记录的命令文本（Extended JSON）解析为 Statement，非只读命令返回 ErrNotReadOnly，
+// 被截断或含脱敏值的命令分别返回 ErrTruncated、ErrRedacted。database 为命令中缺少 $db 时使用的数据库。
+func Parse(database, statement string) (*Statement, error) {
+	if truncatedSuffix.MatchString(statement) {
+		return nil, ErrTruncated
+	}
+
+	var cmd bson.D
+	if err := bson.UnmarshalExtJSON([]byte(statement), false, &cmd); err != nil {
+		return nil, err
+	}
+	if len(cmd) == 0 {
+		return nil, errors.New("replay: empty command")
+	}
+
+	st := &Statement{Database: database, Name: cmd[0].Key}
+	if !readOnlyCommands[st.Name] {
+		return nil, fmt.Errorf("%w: %s", ErrNotReadOnly, st.Name)
+	}
+
+	for _, e := range cmd {
+		if e.Key == "$db" {
+			if db, ok := e.Value.(string); ok {
+				st.Database = db
+			}
+		}
+		if sessionFields[e.Key] {
+			continue
+		}
+		st.Command = append(st.Command, e)
+	}
+
+	// 写出到集合的聚合不是只读操作。
+	if st.Name == "aggregate" {
+		if pipeline, ok := lookup(st.Command, "pipeline").(bson.A); ok {
+			for _, stage := range pipeline {
+				if doc, ok := stage.(bson.D); ok && len(doc) != 0 && (doc[0].Key == "$out" || doc[0].Key == "$merge") {
+					return nil, fmt.Errorf("%w: aggregate with %s", ErrNotReadOnly, doc[0].Key)
+				}
+			}
+		}
+	}
+
+	if redacted(st.Command) {
+		return nil, ErrRedacted
+	}
+
+	return st, nil
+}
+
+// redacted 判断命令中是否有值被替换为 redactedValue。
+func redacted(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v == redactedValue
+	case bson.D:
+		for _, e := range v {
+			if redacted(e.Value) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, item := range v {
+			if redacted(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Skipped 为加载时被跳过的记录数，按原因区分。
+type Skipped struct {
+	// Invalid 为无法解析的行或命令。
+	Invalid int `json:"invalid"`
+	// NotReadOnly 为非只读命令。
+	NotReadOnly int `json:"not_read_only"`
+	// Redacted 为含脱敏值的命令；需要回放时应在采集端关闭 Conf.Redact 或排除相关字段。
+	Redacted int `json:"redacted"`
+	// Truncated 为被截断的命令；需要回放时应调大或关闭 Conf.MaxStatementLength。
+	Truncated int `json:"truncated"`
+}
+
+// Total 返回被跳过的记录总数。
+func (s Skipped) Total() int {
+	return s.Invalid + s.NotReadOnly + s.Redacted + s.Truncated
+}
+
+// add 按 Parse 返回的错误累计被跳过的记录。
+func (s *Skipped) add(err error) {
+	switch {
+	case errors.Is(err, ErrNotReadOnly):
+		s.NotReadOnly++
+	case errors.Is(err, ErrRedacted):
+		s.Redacted++
+	case errors.Is(err, ErrTruncated):
+		s.Truncated++
+	default:
+		s.Invalid++
+	}
+}
+
+// Load 逐行读取 JSON 格式的 OperationLogger（OTel 日志的 body），返回可回放的只读命令与按原因统计的跳过数。
+// 脱敏或截断的命令不会以失真的形式回放，调用方应检查 Skipped.Redacted 与 Skipped.Truncated。
+func Load(r io.Reader) ([]Statement, Skipped, error) {
+	var list []Statement
+	var skipped Skipped
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record internal.OperationLogger
+		if err := json.Unmarshal(line, &record); err != nil || record.Statement == "" {
+			skipped.Invalid++
+			continue
+		}
+		st, err := Parse(record.Database, record.Statement)
+		if err != nil {
+			skipped.add(err)
+			continue
+		}
+		list = append(list, *st)
+	}
+
+	return list, skipped, scanner.Err()
+}
+
+// Conf 为回放配置。
+type Conf struct {
+	// Concurrency 为并发数，<=0 时为 1。
+	Concurrency int
+	// Database 非空时覆盖命令中记录的数据库，用于回放到其他环境。
+	Database string
+	// Loops 为完整回放的轮数，<=0 时为 1。
+	Loops int
+	// Duration 大于 0 时按时长循环回放，忽略 Loops。
+	Duration time.Duration
+}
+
+// Stats 为单个命令的回放统计。
+type Stats struct {
+	Name   string        `json:"name"`
+	Count  int64         `json:"count"`
+	Errors int64         `json:"errors"`
+	Total  time.Duration `json:"total"`
+	Max    time.Duration `json:"max"`
+}
+
+// Report 为回放结果。
+type Report struct {
+	Executed int64            `json:"executed"`
+	Errors   int64            `json:"errors"`
+	Elapsed  time.Duration    `json:"elapsed"`
+	Commands map[string]Stats `json:"commands"`
+}
+
+// String 输出便于阅读的结果表。
+func (r *Report) String() string {
+	names := make([]string, 0, len(r.Commands))
+	for name := range r.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	qps := float64(r.Executed) / r.Elapsed.Seconds()
+	s := fmt.Sprintf("executed=%d errors=%d elapsed=%s qps=%.1f\n", r.Executed, r.Errors, r.Elapsed.Round(time.Millisecond), qps)
+	for _, name := range names {
+		st := r.Commands[name]
+		var mean time.Duration
+		if st.Count != 0 {
+			mean = st.Total / time.Duration(st.Count)
+		}
+		s += fmt.Sprintf("%-10s count=%d errors=%d mean=%s max=%s\n", name, st.Count, st.Errors, mean, st.Max)
+	}
+	return s
+}
+
+// Run 以 conf.Concurrency 个 worker 并发回放 statements，直到完成指定轮数、达到 Duration 或 ctx 取消。
+// 命令执行错误计入统计而不会中断回放；find 与 aggregate 只读取首批结果，随后关闭服务端游标，不会在服务端残留打开的游标。
+func Run(ctx context.Context, client *mongo.Client, statements []Statement, conf *Conf) (*Report, error) {
+	if len(statements) == 0 {
+		return nil, errors.New("replay: no statements")
+	}
+
+	c := Conf{}
+	if conf != nil {
+		c = *conf
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 1
+	}
+	if c.Loops <= 0 {
+		c.Loops = 1
+	}
+	if c.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Duration)
+		defer cancel()
+	}
+
+	var next atomic.Int64
+	total := int64(len(statements) * c.Loops)
+
+	var mu sync.Mutex
+	report := &Report{Commands: make(map[string]Stats)}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < c.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := next.Add(1) - 1
+				if c.Duration <= 0 && i >= total {
+					return
+				}
+				st := statements[i%int64(len(statements))]
+
+				database := st.Database
+				if c.Database != "" {
+					database = c.Database
+				}
+
+				begin := time.Now()
+				err := execute(ctx, client.Database(database), &st)
+				elapsed := time.Since(begin)
+				// 时长到期导致的取消不计入错误。
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+
+				mu.Lock()
+				s := report.Commands[st.Name]
+				s.Name = st.Name
+				s.Count++
+				s.Total += elapsed
+				s.Max = max(s.Max, elapsed)
+				report.Executed++
+				if err != nil {
+					s.Errors++
+					report.Errors++
+				}
+				report.Commands[st.Name] = s
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	// 按时长回放时到期属于正常结束。
+	if c.Duration > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return report, nil
+	}
+	return report, ctx.Err()
+}
+
+// cursorCommands 为返回游标的命令，回放后需要关闭服务端游标。
+var cursorCommands = map[string]bool{
+	"find":      true,
+	"aggregate": true,
+}
+
+// execute 执行单条命令，返回游标的命令在读取首批结果后关闭游标。
+func execute(ctx context.Context, db *mongo.Database, st *Statement) error {
+	if !cursorCommands[st.Name] {
+		return db.RunCommand(ctx, st.Command).Err()
+	}
+
+	cursor, err := db.RunCommandCursor(ctx, st.Command)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(context.WithoutCancel(ctx))
+}
+
+func lookup(doc bson.D, key string) any {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package replay
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		database  string
+		command   bson.D
+		err       error
+	}{
+		{
+			name:      "find",
+			statement: `{"find":"users","filter":{"age":{"$gt":1}},"$db":"app","lsid":{"id":"x"}}`,
+			database:  "app",
+			command: bson.D{
+				{Key: "find", Value: "users"},
+				{Key: "filter", Value: bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: int32(1)}}}}},
+			},
+		},
+		{
+			name:      "default database",
+			statement: `{"count":"users"}`,
+			database:  "fallback",
+			command:   bson.D{{Key: "count", Value: "users"}},
+		},
+		{
+			name:      "write command",
+			statement: `{"insert":"users","documents":[]}`,
+			err:       ErrNotReadOnly,
+		},
+		{
+			name:      "aggregate with $out",
+			statement: `{"aggregate":"users","pipeline":[{"$match":{}},{"$out":"copy"}]}`,
+			err:       ErrNotReadOnly,
+		},
+		{
+			name:      "aggregate with $merge",
+			statement: `{"aggregate":"users","pipeline":[{"$merge":{"into":"copy"}}]}`,
+			err:       ErrNotReadOnly,
+		},
+		{
+			name:      "redacted top-level value",
+			statement: `{"find":"users","filter":{"email":"[REDACTED]"}}`,
+			err:       ErrRedacted,
+		},
+		{
+			name:      "redacted value in array",
+			statement: `{"find":"users","filter":{"email":{"$in":["a","[REDACTED]"]}}}`,
+			err:       ErrRedacted,
+		},
+		{
+			name:      "redacted value in pipeline",
+			statement: `{"aggregate":"users","pipeline":[{"$match":{"token":"[REDACTED]"}}]}`,
+			err:       ErrRedacted,
+		},
+		{
+			name:      "redacted marker as a substring",
+			statement: `{"find":"users","filter":{"note":"was [REDACTED] before"}}`,
+			database:  "fallback",
+			command: bson.D{
+				{Key: "find", Value: "users"},
+				{Key: "filter", Value: bson.D{{Key: "note", Value: "was [REDACTED] before"}}},
+			},
+		},
+		{
+			name:      "truncated",
+			statement: `{"find":"users","filter":{"name":"a...[truncated, 4096 bytes total]`,
+			err:       ErrTruncated,
+		},
+		{
+			name:      "invalid json",
+			statement: `{"find":`,
+		},
+		{
+			name:      "empty command",
+			statement: `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := Parse("fallback", tt.statement)
+			if tt.command == nil {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.Database != tt.database {
+				t.Errorf("database = %q, want %q", st.Database, tt.database)
+			}
+			if !reflect.DeepEqual(st.Command, tt.command) {
+				t.Errorf("command = %v, want %v", st.Command, tt.command)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	lines := []string{
+		`{"database":"app","statement":"{\"find\":\"users\"}"}`,
+		`{"database":"app","statement":"{\"insert\":\"users\"}"}`,
+		`{"database":"app","statement":"{\"find\":\"users\",\"filter\":{\"e\":\"[REDACTED]\"}}"}`,
+		`{"database":"app","statement":"{\"find\":\"us...[truncated, 900 bytes total]"}`,
+		`not json`,
+		``,
+	}
+
+	list, skipped, err := Load(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "find" {
+		t.Errorf("statements = %+v, want a single find", list)
+	}
+	want := Skipped{Invalid: 1, NotReadOnly: 1, Redacted: 1, Truncated: 1}
+	if skipped != want {
+		t.Errorf("skipped = %+v, want %+v", skipped, want)
+	}
+}
@@ -0,0 +1,70 @@
+package replay
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fireflycore/go-mongo/internal"
+)
+
+// DefaultRingSize 为 NewRing 未指定容量时保留的命令条数。
+const DefaultRingSize = 10000
+
+// Ring 在内存中保留最近 size 条命令日志，作为回放的数据源，适用于没有日志采集、需要直接从运行中的进程抓取查询组合的场景。
+// 通过 Conf.WithLogHandler(ring.Handle) 注册，只保留只读、未脱敏且未截断的命令。
+type Ring struct {
+	mu      sync.Mutex
+	items   []Statement
+	next    int
+	full    bool
+	skipped Skipped
+}
+
+// NewRing 创建容量为 size 的 Ring，size<=0 时使用 DefaultRingSize。
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		size = DefaultRingSize
+	}
+	return &Ring{items: make([]Statement, size)}
+}
+
+// Handle 实现 mongo.LogHandler，解析命令并写入环形缓冲区，容量满后覆盖最早的命令。不可回放的命令计入 Skipped。
+func (r *Ring) Handle(_ context.Context, log *internal.OperationLogger) error {
+	if log.Statement == "" {
+		return nil
+	}
+	st, err := Parse(log.Database, log.Statement)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.skipped.add(err)
+		return nil
+	}
+	r.items[r.next] = *st
+	r.next++
+	if r.next == len(r.items) {
+		r.next, r.full = 0, true
+	}
+	return nil
+}
+
+// Statements 按记录顺序返回当前保留的命令副本，可直接传给 Run。
+func (r *Ring) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]Statement(nil), r.items[:r.next]...)
+	}
+	list := make([]Statement, 0, len(r.items))
+	list = append(list, r.items[r.next:]...)
+	return append(list, r.items[:r.next]...)
+}
+
+// Skipped 返回累计被跳过的命令数。
+func (r *Ring) Skipped() Skipped {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skipped
+}
@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SagaStep 为 Saga 中的一个写入步骤及其补偿操作。
+type SagaStep struct {
+	Name string
+	// Do 执行写入。
+	Do func(ctx context.Context) error
+	// Compensate 撤销 Do 的效果，为空表示该步骤无需补偿。
+	Compensate func(ctx context.Context) error
+}
+
+// SagaError 表示 Saga 某一步失败，CompensationErr 为补偿过程中出现的错误。
+type SagaError struct {
+	Step            string
+	Err             error
+	CompensationErr error
+}
+
+func (e *SagaError) Error() string {
+	if e.CompensationErr != nil {
+		return fmt.Sprintf("mongo: saga step %q failed: %v (compensation failed: %v)", e.Step, e.Err, e.CompensationErr)
+	}
+	return fmt.Sprintf("mongo: saga step %q failed: %v", e.Step, e.Err)
+}
+
+func (e *SagaError) Unwrap() []error {
+	return []error{e.Err, e.CompensationErr}
+}
+
+// Saga 为不支持多文档事务的部署（单节点、DocumentDB 等）提供尽力而为的原子性：
+// 按顺序执行步骤并记录已完成的步骤，失败时按相反顺序执行补偿。补偿本身不保证原子，应设计为幂等。
+type Saga struct {
+	done []SagaStep
+}
+
+// NewSaga 创建 Saga。
+func NewSaga() *Saga {
+	return &Saga{}
+}
+
+// Step 执行一个步骤，成功后登记其补偿操作；失败时不会自动补偿，由调用方决定是否调用 Compensate。
+func (s *Saga) Step(ctx context.Context, step SagaStep) error {
+	if step.Do == nil {
+		return fmt.Errorf("mongo: saga step %q has no Do", step.Name)
+	}
+	if err := step.Do(ctx); err != nil {
+		return err
+	}
+	s.done = append(s.done, step)
+	return nil
+}
+
+// Compensate 按相反顺序执行已完成步骤的补偿，单个补偿失败不会中断其余补偿，错误合并返回。
+// 补偿在脱离取消的 ctx 上执行，避免原请求取消导致补偿中途放弃。
+func (s *Saga) Compensate(ctx context.Context) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(s.done) - 1; i >= 0; i-- {
+		step := s.done[i]
+		if step.Compensate == nil {
+			continue
+		}
+		if err := step.Compensate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("compensate %q: %w", step.Name, err))
+		}
+	}
+	s.done = nil
+
+	return errors.Join(errs...)
+}
+
+// RunSaga 依次执行步骤，任一步骤失败时补偿已完成的步骤并返回 *SagaError。
+func RunSaga(ctx context.Context, steps ...SagaStep) error {
+	saga := NewSaga()
+	for _, step := range steps {
+		if err := saga.Step(ctx, step); err != nil {
+			return &SagaError{Step: step.Name, Err: err, CompensationErr: saga.Compensate(ctx)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package scope
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Selection 为字段选择树（例如 GraphQL 的 selection set），由调用方从 gqlgen/graphql-go 的解析结果转换而来。
+type Selection struct {
+	Name     string
+	Children []Selection
+}
+
+// Relation 描述通过 $lookup 关联到其他集合的字段。
+type Relation struct {
+	// From 为关联集合名。
+	From string
+	// LocalField 为当前文档中的关联字段。
+	LocalField string
+	// ForeignField 为关联集合中的字段（通常为 _id）。
+	ForeignField string
+	// Single 为 true 时展开为单个对象（$unwind），否则保留为数组。
+	Single bool
+	// Mapping 为关联集合的字段映射，为空表示字段名与文档字段一致。
+	Mapping *SelectionMapping
+}
+
+// SelectionMapping 定义选择字段到文档字段的映射。
+type SelectionMapping struct {
+	// Fields 为选择字段名到文档字段名的映射（例如 id -> _id），未配置时使用原名。
+	Fields map[string]string
+	// Relations 为需要 $lookup 的字段。
+	Relations map[string]Relation
+	// Children 为嵌入子文档字段（按选择字段名）的映射，子文档中的字段映射与关联在其中配置。
+	Children map[string]*SelectionMapping
+}
+
+func (m *SelectionMapping) field(name string) string {
+	if m != nil {
+		if v, ok := m.Fields[name]; ok {
+			return v
+		}
+	}
+	return name
+}
+
+func (m *SelectionMapping) child(name string) *SelectionMapping {
+	if m == nil {
+		return nil
+	}
+	return m.Children[name]
+}
+
+func (m *SelectionMapping) relation(name string) (Relation, bool) {
+	if m == nil {
+		return Relation{}, false
+	}
+	r, ok := m.Relations[name]
+	return r, ok
+}
+
+// WithSelection 为 FindOptions 设置与选择树对应的投影（忽略关联字段，关联查询请使用 SelectionPipeline）。
+func WithSelection(opt *options.FindOptionsBuilder, selections []Selection, mapping *SelectionMapping) {
+	if projection := SelectionProjection(selections, mapping); len(projection) != 0 {
+		opt.SetProjection(projection)
+	}
+}
+
+// SelectionProjection 将选择树转换为投影文档，关联字段只保留其 LocalField。
+func SelectionProjection(selections []Selection, mapping *SelectionMapping) bson.D {
+	projection := bson.D{}
+	seen := map[string]bool{}
+	appendProjection(&projection, seen, "", selections, mapping, false)
+	return projection
+}
+
+// SelectionPipeline 将选择树转换为聚合阶段：关联字段（包括嵌入子文档中的关联）生成 $lookup（子选择作为子管道投影），
+// 最后追加 $project。
+func SelectionPipeline(selections []Selection, mapping *SelectionMapping) mongo.Pipeline {
+	pipeline := mongo.Pipeline{}
+	appendLookups(&pipeline, "", selections, mapping)
+
+	projection := bson.D{}
+	seen := map[string]bool{}
+	appendProjection(&projection, seen, "", selections, mapping, true)
+	if len(projection) != 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: projection}})
+	}
+
+	return pipeline
+}
+
+// appendLookups 递归生成关联字段的 $lookup，prefix 为嵌入子文档的路径。
+func appendLookups(pipeline *mongo.Pipeline, prefix string, selections []Selection, mapping *SelectionMapping) {
+	for _, sel := range selections {
+		rel, ok := mapping.relation(sel.Name)
+		if !ok {
+			if len(sel.Children) != 0 {
+				appendLookups(pipeline, joinPath(prefix, mapping.field(sel.Name)), sel.Children, mapping.child(sel.Name))
+			}
+			continue
+		}
+
+		as := joinPath(prefix, sel.Name)
+		lookup := bson.D{
+			{Key: "from", Value: rel.From},
+			{Key: "localField", Value: joinPath(prefix, rel.LocalField)},
+			{Key: "foreignField", Value: rel.ForeignField},
+			{Key: "as", Value: as},
+		}
+		if len(sel.Children) != 0 {
+			lookup = append(lookup, bson.E{Key: "pipeline", Value: SelectionPipeline(sel.Children, rel.Mapping)})
+		}
+		*pipeline = append(*pipeline, bson.D{{Key: "$lookup", Value: lookup}})
+
+		if rel.Single {
+			*pipeline = append(*pipeline, bson.D{{Key: "$unwind", Value: bson.D{
+				{Key: "path", Value: "$" + as},
+				{Key: "preserveNullAndEmptyArrays", Value: true},
+			}}})
+		}
+	}
+}
+
+// appendProjection 递归展开选择树，lookup 为 true 时关联字段以自身名称保留（已由 $lookup 填充）。
+func appendProjection(projection *bson.D, seen map[string]bool, prefix string, selections []Selection, mapping *SelectionMapping, lookup bool) {
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		*projection = append(*projection, bson.E{Key: path, Value: 1})
+	}
+
+	for _, sel := range selections {
+		if strings.HasPrefix(sel.Name, "__") {
+			continue // 忽略 __typename 等内省字段。
+		}
+
+		if rel, ok := mapping.relation(sel.Name); ok {
+			if lookup {
+				add(joinPath(prefix, sel.Name))
+			} else {
+				add(joinPath(prefix, rel.LocalField))
+			}
+			continue
+		}
+
+		path := joinPath(prefix, mapping.field(sel.Name))
+		if len(sel.Children) == 0 {
+			add(path)
+			continue
+		}
+		appendProjection(projection, seen, path, sel.Children, mapping.child(sel.Name), lookup)
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
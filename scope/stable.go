@@ -0,0 +1,174 @@
+package scope
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrInvalidPageToken 表示分页令牌格式错误、签名校验失败，或令牌不是由相同的过滤条件与排序生成的。
+var ErrInvalidPageToken = errors.New("mongo: invalid page token")
+
+// pageToken 为分页令牌的载荷：快照时间、上一页最后一条记录的排序键，以及生成令牌时过滤条件与排序的摘要。
+type pageToken struct {
+	Snapshot time.Time     `bson:"s"`
+	Query    []byte        `bson:"q"`
+	Value    bson.RawValue `bson:"v,omitempty"`
+	Id       bson.RawValue `bson:"i,omitempty"`
+}
+
+// StablePager 基于快照时间与键集（sort 字段 + _id）实现稳定分页。
+// 快照条件为 created_at <= 快照时间，只排除快照之后插入且 created_at 晚于快照时间的文档；created_at 由写入方时钟生成，
+// 时钟偏差或补写的历史数据仍可能出现在后续页中。sort 字段须在文档创建后不再修改，翻页期间修改了排序字段的文档可能重复或遗漏。
+type StablePager struct {
+	secret    []byte
+	sortField string
+	desc      bool
+	// snapshotField 为快照过滤字段，晚于快照时间写入的文档不会出现在后续页中。
+	snapshotField string
+}
+
+// NewStablePager 创建稳定分页器，secret 用于签名令牌，sortField 为排序字段（为空时按 _id 排序）。
+func NewStablePager(secret []byte, sortField string, desc bool) *StablePager {
+	if sortField == "" {
+		sortField = "_id"
+	}
+	return &StablePager{
+		secret:        secret,
+		sortField:     sortField,
+		desc:          desc,
+		snapshotField: "created_at",
+	}
+}
+
+// WithStablePagination 根据令牌向 filter 追加快照与键集条件，并为 FindOptions 设置排序与条数。
+// token 为空表示第一页，此时以当前时间作为快照，返回值为本次查询使用的快照时间。
+// 令牌与生成它的过滤条件绑定，filter（追加条件前）与生成令牌时不同时返回 ErrInvalidPageToken。
+func (p *StablePager) WithStablePagination(filter *bson.D, opt *options.FindOptionsBuilder, token string, size uint64) (time.Time, error) {
+	if size == 0 {
+		size = 5
+	}
+	if size > 100 {
+		size = 100
+	}
+
+	state := pageToken{Snapshot: time.Now().UTC()}
+	if token != "" {
+		var err error
+		if state, err = p.decode(token); err != nil {
+			return time.Time{}, err
+		}
+		query, err := p.query(*filter)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !hmac.Equal(query, state.Query) {
+			return time.Time{}, fmt.Errorf("%w: filter or sort changed", ErrInvalidPageToken)
+		}
+	}
+
+	*filter = append(*filter, bson.E{Key: p.snapshotField, Value: bson.D{{Key: "$lte", Value: state.Snapshot}}})
+
+	op := "$gt"
+	order := 1
+	if p.desc {
+		op = "$lt"
+		order = -1
+	}
+
+	if state.Id.Type != 0 {
+		if p.sortField == "_id" {
+			*filter = append(*filter, bson.E{Key: "_id", Value: bson.D{{Key: op, Value: state.Id}}})
+		} else {
+			*filter = append(*filter, bson.E{Key: "$or", Value: bson.A{
+				bson.D{{Key: p.sortField, Value: bson.D{{Key: op, Value: state.Value}}}},
+				bson.D{
+					{Key: p.sortField, Value: state.Value},
+					{Key: "_id", Value: bson.D{{Key: op, Value: state.Id}}},
+				},
+			}})
+		}
+	}
+
+	sort := bson.D{{Key: p.sortField, Value: order}}
+	if p.sortField != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: order})
+	}
+	opt.SetSort(sort)
+	opt.SetLimit(int64(size))
+
+	return state.Snapshot, nil
+}
+
+// NextPageToken 根据快照时间、本页的过滤条件（传给 WithStablePagination 前的 filter）与本页最后一条文档生成下一页令牌。
+func (p *StablePager) NextPageToken(snapshot time.Time, filter bson.D, last bson.Raw) (string, error) {
+	query, err := p.query(filter)
+	if err != nil {
+		return "", err
+	}
+	state := pageToken{
+		Snapshot: snapshot,
+		Query:    query,
+		Value:    last.Lookup(p.sortField),
+		Id:       last.Lookup("_id"),
+	}
+	if state.Id.Type == 0 {
+		return "", errors.New("mongo: last document has no _id")
+	}
+
+	payload, err := bson.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(p.sign(payload)), nil
+}
+
+func (p *StablePager) decode(token string) (pageToken, error) {
+	var state pageToken
+
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return state, ErrInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return state, ErrInvalidPageToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, p.sign(payload)) {
+		return state, ErrInvalidPageToken
+	}
+	if err := bson.Unmarshal(payload, &state); err != nil {
+		return state, ErrInvalidPageToken
+	}
+
+	return state, nil
+}
+
+// query 返回过滤条件与排序的摘要，过滤条件中不应含有每次请求都会变化的值（如当前时间）。
+func (p *StablePager) query(filter bson.D) ([]byte, error) {
+	raw, err := bson.Marshal(bson.D{
+		{Key: "filter", Value: append(bson.D{}, filter...)},
+		{Key: "sort", Value: p.sortField},
+		{Key: "desc", Value: p.desc},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return sum[:], nil
+}
+
+func (p *StablePager) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
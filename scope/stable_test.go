@@ -0,0 +1,128 @@
+package scope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestStablePagerToken(t *testing.T) {
+	secret := []byte("secret")
+	pager := NewStablePager(secret, "score", true)
+	filter := bson.D{{Key: "status", Value: "active"}}
+	snapshot := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	last, err := bson.Marshal(bson.D{{Key: "_id", Value: "a"}, {Key: "score", Value: int32(7)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := pager.NextPageToken(snapshot, filter, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := "A" + token[1:]
+	if token[0] == 'A' {
+		tampered = "B" + token[1:]
+	}
+
+	tests := []struct {
+		name   string
+		pager  *StablePager
+		filter bson.D
+		token  string
+		err    error
+	}{
+		{name: "same query", pager: pager, filter: filter, token: token},
+		{name: "changed filter", pager: pager, filter: bson.D{{Key: "status", Value: "deleted"}}, token: token, err: ErrInvalidPageToken},
+		{name: "extra condition", pager: pager, filter: append(bson.D{{Key: "owner", Value: "u1"}}, filter...), token: token, err: ErrInvalidPageToken},
+		{name: "changed sort field", pager: NewStablePager(secret, "name", true), filter: filter, token: token, err: ErrInvalidPageToken},
+		{name: "changed direction", pager: NewStablePager(secret, "score", false), filter: filter, token: token, err: ErrInvalidPageToken},
+		{name: "other secret", pager: NewStablePager([]byte("other"), "score", true), filter: filter, token: token, err: ErrInvalidPageToken},
+		{name: "tampered payload", pager: pager, filter: filter, token: tampered, err: ErrInvalidPageToken},
+		{name: "missing signature", pager: pager, filter: filter, token: strings.Split(token, ".")[0], err: ErrInvalidPageToken},
+		{name: "not base64", pager: pager, filter: filter, token: "!!.!!", err: ErrInvalidPageToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := append(bson.D{}, tt.filter...)
+			opt := options.Find()
+			got, err := tt.pager.WithStablePagination(&query, opt, tt.token, 10)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if !got.Equal(snapshot) {
+				t.Errorf("snapshot = %v, want %v", got, snapshot)
+			}
+			// 原条件 + 快照条件 + 键集条件。
+			if len(query) != len(tt.filter)+2 {
+				t.Fatalf("filter = %v", query)
+			}
+			if query[len(query)-1].Key != "$or" {
+				t.Errorf("keyset condition = %v, want $or", query[len(query)-1])
+			}
+		})
+	}
+}
+
+func TestStablePagerFirstPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		sortField string
+		desc      bool
+		sort      bson.D
+	}{
+		{name: "by _id", sort: bson.D{{Key: "_id", Value: 1}}},
+		{name: "by field", sortField: "score", desc: true, sort: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pager := NewStablePager([]byte("secret"), tt.sortField, tt.desc)
+			filter := bson.D{}
+			opt := options.Find()
+			snapshot, err := pager.WithStablePagination(&filter, opt, "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(filter) != 1 || filter[0].Key != "created_at" {
+				t.Errorf("filter = %v, want only the snapshot condition", filter)
+			}
+
+			var applied options.FindOptions
+			for _, set := range opt.List() {
+				if err := set(&applied); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sort, _ := applied.Sort.(bson.D); len(sort) != len(tt.sort) || sort[0] != tt.sort[0] {
+				t.Errorf("sort = %v, want %v", applied.Sort, tt.sort)
+			}
+			if applied.Limit == nil || *applied.Limit != 5 {
+				t.Errorf("limit = %v, want 5", applied.Limit)
+			}
+			if time.Since(snapshot) > time.Minute {
+				t.Errorf("snapshot = %v, want the current time", snapshot)
+			}
+		})
+	}
+}
+
+func TestStablePagerTokenWithoutId(t *testing.T) {
+	pager := NewStablePager([]byte("secret"), "", false)
+	last, err := bson.Marshal(bson.D{{Key: "score", Value: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pager.NextPageToken(time.Now(), nil, last); err == nil {
+		t.Error("expected an error for a document without _id")
+	}
+}
@@ -0,0 +1,148 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// serverKindPrimary 为副本集主节点的节点类型。
+const serverKindPrimary = "RSPrimary"
+
+// ServerHooks 为节点发现与监控（SDAM）事件回调，未设置的回调会被跳过。
+// 回调在 driver 的监控协程中同步执行，不应在回调中对同一 client 发起操作。
+type ServerHooks struct {
+	// OnPrimaryChanged 在副本集主节点变化时触发，previous/current 为节点地址，无主时为空。
+	OnPrimaryChanged func(previous, current string)
+	// OnServerChanged 在单个节点的描述变化时触发（如 RSPrimary -> RSSecondary、节点变为 Unknown）。
+	OnServerChanged func(e *event.ServerDescriptionChangedEvent)
+	// OnTopologyChanged 在整体拓扑描述变化时触发。
+	OnTopologyChanged func(e *event.TopologyDescriptionChangedEvent)
+	// OnHeartbeatFailed 在节点心跳失败时触发。
+	OnHeartbeatFailed func(e *event.ServerHeartbeatFailedEvent)
+}
+
+// monitor 将回调转换为 driver 的节点监控器。
+func (h ServerHooks) monitor() *event.ServerMonitor {
+	return &event.ServerMonitor{
+		ServerDescriptionChanged: h.OnServerChanged,
+		ServerHeartbeatFailed:    h.OnHeartbeatFailed,
+		TopologyDescriptionChanged: func(e *event.TopologyDescriptionChangedEvent) {
+			if h.OnPrimaryChanged != nil {
+				if prev, cur := primaryOf(e.PreviousDescription), primaryOf(e.NewDescription); prev != cur {
+					h.OnPrimaryChanged(prev, cur)
+				}
+			}
+			if h.OnTopologyChanged != nil {
+				h.OnTopologyChanged(e)
+			}
+		},
+	}
+}
+
+// primaryOf 返回拓扑中主节点的地址，无主时返回空。
+func primaryOf(topology event.TopologyDescription) string {
+	for _, server := range topology.Servers {
+		if server.Kind == serverKindPrimary {
+			return server.Addr.String()
+		}
+	}
+	return ""
+}
+
+// newTopologyLogMonitor 构造把节点类型变化与主节点切换写入结构化日志的监控器。
+func newTopologyLogMonitor(database string, out internal.Output) *event.ServerMonitor {
+	// kinds 记录各节点最近一次的类型，只在类型变化时输出，避免心跳带来的描述变化刷屏。
+	var (
+		mu    sync.Mutex
+		kinds = map[string]string{}
+	)
+
+	return ServerHooks{
+		OnPrimaryChanged: func(previous, current string) {
+			internal.EmitTopologyLog(context.Background(), out, &internal.TopologyLogger{
+				Database: database,
+				Event:    "primary_changed",
+				Previous: previous,
+				Current:  current,
+			})
+		},
+		OnServerChanged: func(e *event.ServerDescriptionChangedEvent) {
+			address := e.Address.String()
+			current := e.NewDescription.Kind
+
+			mu.Lock()
+			previous, seen := kinds[address]
+			kinds[address] = current
+			mu.Unlock()
+			if !seen {
+				previous = e.PreviousDescription.Kind
+			}
+			if previous == current {
+				return
+			}
+
+			internal.EmitTopologyLog(context.Background(), out, &internal.TopologyLogger{
+				Database: database,
+				Event:    "server_changed",
+				Address:  address,
+				Previous: previous,
+				Current:  current,
+			})
+		},
+	}.monitor()
+}
+
+// chainServerMonitors 将多个节点监控器按顺序串联为一个（nil 监控器与 nil 回调会被跳过）。
+func chainServerMonitors(monitors ...*event.ServerMonitor) *event.ServerMonitor {
+	var list []*event.ServerMonitor
+	for _, m := range monitors {
+		if m != nil {
+			list = append(list, m)
+		}
+	}
+	switch len(list) {
+	case 0:
+		return nil
+	case 1:
+		return list[0]
+	}
+
+	return &event.ServerMonitor{
+		ServerDescriptionChanged: fanout(list, func(m *event.ServerMonitor) func(*event.ServerDescriptionChangedEvent) {
+			return m.ServerDescriptionChanged
+		}),
+		ServerOpening: fanout(list, func(m *event.ServerMonitor) func(*event.ServerOpeningEvent) { return m.ServerOpening }),
+		ServerClosed:  fanout(list, func(m *event.ServerMonitor) func(*event.ServerClosedEvent) { return m.ServerClosed }),
+		TopologyDescriptionChanged: fanout(list, func(m *event.ServerMonitor) func(*event.TopologyDescriptionChangedEvent) {
+			return m.TopologyDescriptionChanged
+		}),
+		TopologyOpening:        fanout(list, func(m *event.ServerMonitor) func(*event.TopologyOpeningEvent) { return m.TopologyOpening }),
+		TopologyClosed:         fanout(list, func(m *event.ServerMonitor) func(*event.TopologyClosedEvent) { return m.TopologyClosed }),
+		ServerHeartbeatStarted: fanout(list, func(m *event.ServerMonitor) func(*event.ServerHeartbeatStartedEvent) { return m.ServerHeartbeatStarted }),
+		ServerHeartbeatSucceeded: fanout(list, func(m *event.ServerMonitor) func(*event.ServerHeartbeatSucceededEvent) {
+			return m.ServerHeartbeatSucceeded
+		}),
+		ServerHeartbeatFailed: fanout(list, func(m *event.ServerMonitor) func(*event.ServerHeartbeatFailedEvent) { return m.ServerHeartbeatFailed }),
+	}
+}
+
+// fanout 收集各监控器中的同一回调，依次调用；全部为空时返回 nil。
+func fanout[E any](list []*event.ServerMonitor, get func(*event.ServerMonitor) func(*E)) func(*E) {
+	var fns []func(*E)
+	for _, m := range list {
+		if fn := get(m); fn != nil {
+			fns = append(fns, fn)
+		}
+	}
+	if len(fns) == 0 {
+		return nil
+	}
+	return func(e *E) {
+		for _, fn := range fns {
+			fn(e)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package mongo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Secrets 为建立连接所需的敏感信息，空字段表示沿用 Conf 中的配置。
+type Secrets struct {
+	Username string
+	Password string
+
+	// CaCert 为 CA 证书 PEM 内容。
+	CaCert []byte
+	// ClientCert 为客户端证书 PEM 内容。
+	ClientCert []byte
+	// ClientCertKey 为客户端证书私钥 PEM 内容。
+	ClientCertKey []byte
+}
+
+// hasTLS 判断是否提供了完整的 TLS 证书材料。
+func (s *Secrets) hasTLS() bool {
+	return len(s.CaCert) != 0 && len(s.ClientCert) != 0 && len(s.ClientCertKey) != 0
+}
+
+// tlsConfig 根据 PEM 内容构造双向 TLS 配置。
+func (s *Secrets) tlsConfig() (*tls.Config, error) {
+	certPool := x509.NewCertPool()
+	if ok := certPool.AppendCertsFromPEM(s.CaCert); !ok {
+		return nil, errors.New("mongo: failed to append ca cert from secrets")
+	}
+
+	clientCert, err := tls.X509KeyPair(s.ClientCert, s.ClientCertKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      certPool,
+	}, nil
+}
+
+// SecretsProvider 在建立连接时提供密码与 TLS 证书，Vault、AWS Secrets Manager 等可通过实现该接口接入。
+type SecretsProvider interface {
+	Secrets(ctx context.Context) (*Secrets, error)
+}
+
+// SecretsProviderFunc 将函数适配为 SecretsProvider。
+type SecretsProviderFunc func(ctx context.Context) (*Secrets, error)
+
+// Secrets 实现 SecretsProvider。
+func (f SecretsProviderFunc) Secrets(ctx context.Context) (*Secrets, error) {
+	return f(ctx)
+}
+
+// EnvSecretsProvider 从环境变量读取敏感信息：{Prefix}_USERNAME、{Prefix}_PASSWORD、
+// {Prefix}_TLS_CA_CERT、{Prefix}_TLS_CLIENT_CERT、{Prefix}_TLS_CLIENT_CERT_KEY（PEM 内容），Prefix 默认为 MONGO。
+type EnvSecretsProvider struct {
+	Prefix string
+}
+
+// Secrets 实现 SecretsProvider。
+func (p *EnvSecretsProvider) Secrets(_ context.Context) (*Secrets, error) {
+	prefix := p.Prefix
+	if prefix == "" {
+		prefix = "MONGO"
+	}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	return &Secrets{
+		Username:      os.Getenv(prefix + "USERNAME"),
+		Password:      os.Getenv(prefix + "PASSWORD"),
+		CaCert:        []byte(os.Getenv(prefix + "TLS_CA_CERT")),
+		ClientCert:    []byte(os.Getenv(prefix + "TLS_CLIENT_CERT")),
+		ClientCertKey: []byte(os.Getenv(prefix + "TLS_CLIENT_CERT_KEY")),
+	}, nil
+}
+
+// FileSecretsProvider 从文件读取敏感信息（例如 Kubernetes Secret 挂载），未配置的路径会被跳过。
+// 每次建立连接都会重新读取文件，因此挂载内容更新后新连接即可生效。
+type FileSecretsProvider struct {
+	UsernameFile      string
+	PasswordFile      string
+	CaCertFile        string
+	ClientCertFile    string
+	ClientCertKeyFile string
+}
+
+// Secrets 实现 SecretsProvider。
+func (p *FileSecretsProvider) Secrets(_ context.Context) (*Secrets, error) {
+	s := &Secrets{}
+
+	read := func(path string) ([]byte, error) {
+		if path == "" {
+			return nil, nil
+		}
+		return os.ReadFile(path)
+	}
+
+	username, err := read(p.UsernameFile)
+	if err != nil {
+		return nil, err
+	}
+	password, err := read(p.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	s.Username = strings.TrimSpace(string(username))
+	s.Password = strings.TrimRight(string(password), "\r\n")
+
+	if s.CaCert, err = read(p.CaCertFile); err != nil {
+		return nil, err
+	}
+	if s.ClientCert, err = read(p.ClientCertFile); err != nil {
+		return nil, err
+	}
+	if s.ClientCertKey, err = read(p.ClientCertKeyFile); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Topology 为部署拓扑类型。
+type Topology string
+
+const (
+	TopologyStandalone   Topology = "standalone"    // TopologyStandalone 单节点。
+	TopologyReplicaSet   Topology = "replica_set"   // TopologyReplicaSet 副本集。
+	TopologySharded      Topology = "sharded"       // TopologySharded 分片集群（mongos）。
+	TopologyLoadBalanced Topology = "load_balanced" // TopologyLoadBalanced 负载均衡模式。
+)
+
+// ServerInfo 为服务端版本、拓扑与能力信息。
+type ServerInfo struct {
+	Version    string   `json:"version"`
+	Topology   Topology `json:"topology"`
+	ReplicaSet string   `json:"replica_set"`
+	// MaxWireVersion 为服务端支持的最大协议版本。
+	MaxWireVersion int32 `json:"max_wire_version"`
+
+	Transactions  bool `json:"transactions"`
+	ChangeStreams bool `json:"change_streams"`
+}
+
+// VersionAtLeast 判断服务端版本是否不低于 major.minor。
+func (s *ServerInfo) VersionAtLeast(major, minor int) bool {
+	parts := strings.SplitN(s.Version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	ma, _ := strconv.Atoi(parts[0])
+	mi, _ := strconv.Atoi(parts[1])
+	return ma > major || (ma == major && mi >= minor)
+}
+
+// DescribeServer 通过 hello 与 buildInfo 获取服务端信息。
+func DescribeServer(ctx context.Context, client *mongo.Client) (*ServerInfo, error) {
+	admin := client.Database("admin")
+
+	var hello helloReply
+	if err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return nil, err
+	}
+
+	// buildInfo 不在 Stable API v1 中，strict 模式下会被拒绝，此时按协议版本推断主次版本号。
+	var build struct {
+		Version string `bson:"version"`
+	}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&build); err != nil {
+		build.Version = versionFromWire(hello.MaxWireVersion)
+	}
+
+	info := &ServerInfo{
+		Version:        build.Version,
+		Topology:       hello.topology(),
+		ReplicaSet:     hello.SetName,
+		MaxWireVersion: hello.MaxWireVersion,
+	}
+
+	// 副本集 4.0+、分片 4.2+ 支持多文档事务；change stream 需要副本集或分片且 3.6+。
+	switch info.Topology {
+	case TopologyReplicaSet:
+		info.Transactions = info.VersionAtLeast(4, 0)
+		info.ChangeStreams = info.VersionAtLeast(3, 6)
+	case TopologySharded, TopologyLoadBalanced:
+		info.Transactions = info.VersionAtLeast(4, 2)
+		info.ChangeStreams = info.VersionAtLeast(3, 6)
+	}
+
+	return info, nil
+}
+
+// helloReply 为 hello 命令响应中本包关心的字段。
+type helloReply struct {
+	SetName           string `bson:"setName"`
+	Msg               string `bson:"msg"`
+	MaxWireVersion    int32  `bson:"maxWireVersion"`
+	ServiceId         any    `bson:"serviceId"`
+	IsWritablePrimary bool   `bson:"isWritablePrimary"`
+	Primary           string `bson:"primary"`
+}
+
+// topology 根据 hello 响应判断部署拓扑。
+func (h *helloReply) topology() Topology {
+	switch {
+	case h.ServiceId != nil:
+		return TopologyLoadBalanced
+	case h.Msg == "isdbgrid":
+		return TopologySharded
+	case h.SetName != "":
+		return TopologyReplicaSet
+	default:
+		return TopologyStandalone
+	}
+}
+
+// wireVersions 为协议版本与服务端版本的对应关系（降序）。
+var wireVersions = []struct {
+	wire    int32
+	version string
+}{
+	{25, "8.0"},
+	{21, "7.0"},
+	{17, "6.0"},
+	{13, "5.0"},
+	{9, "4.4"},
+	{8, "4.2"},
+	{7, "4.0"},
+	{6, "3.6"},
+}
+
+// versionFromWire 按 maxWireVersion 推断服务端的最低版本。
+func versionFromWire(wire int32) string {
+	for _, v := range wireVersions {
+		if wire >= v.wire {
+			return v.version
+		}
+	}
+	return "0.0"
+}
@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ServerAPIConf 为 Stable API 配置，用于固定 API 版本并提前发现不兼容的命令。
+type ServerAPIConf struct {
+	// Version 为 API 版本，为空时默认 "1"。
+	Version string `json:"version"`
+	// Strict 为 true 时服务端拒绝不在该 API 版本中的命令与参数。
+	Strict bool `json:"strict"`
+	// DeprecationErrors 为 true 时服务端对已废弃的命令返回错误。
+	DeprecationErrors bool `json:"deprecation_errors"`
+}
+
+// serverAPIOptions 构造 driver 的 ServerAPIOptions。
+func (c *ServerAPIConf) serverAPIOptions() (*options.ServerAPIOptions, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	version := options.ServerAPIVersion(c.Version)
+	if version == "" {
+		version = options.ServerAPIVersion1
+	}
+	if version != options.ServerAPIVersion1 {
+		return nil, fmt.Errorf("mongo: unsupported server api version %q", c.Version)
+	}
+
+	return options.ServerAPI(version).
+		SetStrict(c.Strict).
+		SetDeprecationErrors(c.DeprecationErrors), nil
+}
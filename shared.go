@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// sharedEntry 为 Conf.Shared 复用的连接与引用计数。
+type sharedEntry struct {
+	key    string
+	client *Client
+	err    error
+	refs   int
+	// ready 在连接建立完成（无论成功与否）后关闭。
+	ready chan struct{}
+}
+
+var (
+	sharedMu sync.Mutex
+	// sharedClients 按规范化配置索引复用的连接。
+	sharedClients = map[string]*sharedEntry{}
+	// sharedByClient 按底层 driver client 索引，供 Close(ctx, db) 查找引用计数。
+	sharedByClient sync.Map
+)
+
+// acquireShared 返回与 c 规范化后相同配置的已有连接并增加引用计数，不存在时新建；配置无法安全比较时建立独立连接。
+func acquireShared(ctx context.Context, c *Conf) (*Client, error) {
+	key, ok, err := c.sharedKey()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return newClient(ctx, c)
+	}
+
+	sharedMu.Lock()
+	entry, ok := sharedClients[key]
+	if ok {
+		entry.refs++
+		sharedMu.Unlock()
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			releaseShared(entry)
+			return nil, ctx.Err()
+		}
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		return entry.client, nil
+	}
+
+	entry = &sharedEntry{key: key, refs: 1, ready: make(chan struct{})}
+	sharedClients[key] = entry
+	sharedMu.Unlock()
+
+	entry.client, entry.err = newClient(ctx, c)
+	sharedMu.Lock()
+	if entry.err != nil {
+		delete(sharedClients, key)
+	} else {
+		sharedByClient.Store(entry.client.Raw(), entry)
+	}
+	sharedMu.Unlock()
+	close(entry.ready)
+
+	return entry.client, entry.err
+}
+
+// releaseShared 减少引用计数，返回 true 表示最后一个引用已释放、调用方应关闭连接。
+func releaseShared(entry *sharedEntry) bool {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	entry.refs--
+	if entry.refs > 0 {
+		return false
+	}
+	if sharedClients[entry.key] == entry {
+		delete(sharedClients, entry.key)
+	}
+	if entry.client != nil {
+		sharedByClient.Delete(entry.client.Raw())
+	}
+	return true
+}
+
+// releaseClient 释放 client 对应的共享引用，返回 false 表示仍有其他持有者、不应关闭连接。
+func releaseClient(client *mongo.Client) bool {
+	entry, ok := sharedByClient.Load(client)
+	if !ok {
+		return true
+	}
+	return releaseShared(entry.(*sharedEntry))
+}
+
+// forgetShared 移除 client 对应的共享登记，之后相同配置的调用会建立新连接。
+func forgetShared(client *mongo.Client) {
+	entry, ok := sharedByClient.LoadAndDelete(client)
+	if !ok {
+		return
+	}
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if e := entry.(*sharedEntry); sharedClients[e.key] == e {
+		delete(sharedClients, e.key)
+	}
+}
+
+// sharedKey 返回规范化后的配置摘要：地址去除空白并排序，密码等敏感字段只参与哈希，
+// WithCommandMonitor、WithDialer 等非导出配置按实例地址区分。
+// 函数形式的配置（WithLogHandler、WithCodecs、函数形式的 SecretsProvider/ContextExtractor/拨号器等）无法区分捕获了不同状态的闭包，
+// 未设置 ShareKey 时返回 false，不参与复用；设置 ShareKey 时由调用方保证相同 ShareKey 的函数配置等价，函数配置不参与摘要。
+func (c *Conf) sharedKey() (string, bool, error) {
+	if c.ShareKey == "" && c.hasFuncOptions() {
+		return "", false, nil
+	}
+
+	n := *c
+	n.Address = strings.TrimSpace(n.Address)
+	n.Addresses = make([]string, 0, len(c.Addresses))
+	for _, addr := range c.Addresses {
+		n.Addresses = append(n.Addresses, strings.TrimSpace(addr))
+	}
+	slices.Sort(n.Addresses)
+
+	raw, err := json.Marshal(&n)
+	if err != nil {
+		return "", false, fmt.Errorf("mongo: shared conf key: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(raw)
+	fmt.Fprintf(h, "|%t|%p|%s|%p", c.loggerConsole, c.slog, c.appId, c.registry)
+	for _, v := range []any{c.secrets, c.dialer, c.tracerProvider, c.meterProvider} {
+		if !isFunc(v) {
+			fmt.Fprintf(h, "|%T:%p", v, v)
+		}
+	}
+	for _, m := range c.monitors {
+		fmt.Fprintf(h, "|cm:%p", m)
+	}
+	for _, m := range c.poolMonitors {
+		fmt.Fprintf(h, "|pm:%p", m)
+	}
+	for _, m := range c.serverMonitors {
+		fmt.Fprintf(h, "|sm:%p", m)
+	}
+	for _, e := range c.extractors {
+		if !isFunc(e) {
+			fmt.Fprintf(h, "|ex:%T:%p", e, e)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true, nil
+}
+
+// hasFuncOptions 判断是否设置了函数形式的配置。
+func (c *Conf) hasFuncOptions() bool {
+	if len(c.logHandlers) != 0 || len(c.codecs) != 0 {
+		return true
+	}
+	for _, v := range []any{c.secrets, c.dialer, c.tracerProvider, c.meterProvider} {
+		if isFunc(v) {
+			return true
+		}
+	}
+	return slices.ContainsFunc(c.extractors, func(e ContextExtractor) bool { return isFunc(e) })
+}
+
+func isFunc(v any) bool {
+	return v != nil && reflect.TypeOf(v).Kind() == reflect.Func
+}
@@ -0,0 +1,174 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestSharedKey(t *testing.T) {
+	handler := func(tag string) LogHandler {
+		return func(ctx context.Context, log *OperationLog) error {
+			_ = tag
+			return nil
+		}
+	}
+	monitor := &event.CommandMonitor{}
+
+	base := func() *Conf {
+		return &Conf{Address: "db1:27017", Database: "app", Shared: true}
+	}
+
+	tests := []struct {
+		name      string
+		a, b      func() *Conf
+		shareable bool
+		same      bool
+	}{
+		{
+			name:      "identical",
+			a:         base,
+			b:         base,
+			shareable: true,
+			same:      true,
+		},
+		{
+			name: "addresses are normalised",
+			a: func() *Conf {
+				c := base()
+				c.Addresses = []string{" db2:27017", "db1:27017"}
+				return c
+			},
+			b: func() *Conf {
+				c := base()
+				c.Addresses = []string{"db1:27017", "db2:27017 "}
+				return c
+			},
+			shareable: true,
+			same:      true,
+		},
+		{
+			name: "different database",
+			a:    base,
+			b: func() *Conf {
+				c := base()
+				c.Database = "other"
+				return c
+			},
+			shareable: true,
+			same:      false,
+		},
+		{
+			name: "same monitor instance",
+			a: func() *Conf {
+				c := base()
+				c.WithCommandMonitor(monitor)
+				return c
+			},
+			b: func() *Conf {
+				c := base()
+				c.WithCommandMonitor(monitor)
+				return c
+			},
+			shareable: true,
+			same:      true,
+		},
+		{
+			name: "different monitor instances",
+			a: func() *Conf {
+				c := base()
+				c.WithCommandMonitor(&event.CommandMonitor{})
+				return c
+			},
+			b: func() *Conf {
+				c := base()
+				c.WithCommandMonitor(&event.CommandMonitor{})
+				return c
+			},
+			shareable: true,
+			same:      false,
+		},
+		{
+			name: "closures are not shareable",
+			a: func() *Conf {
+				c := base()
+				c.WithLogHandler(handler("a"))
+				return c
+			},
+			b: func() *Conf {
+				c := base()
+				c.WithLogHandler(handler("b"))
+				return c
+			},
+			shareable: false,
+		},
+		{
+			name: "secrets provider func is not shareable",
+			a: func() *Conf {
+				c := base()
+				c.WithSecretsProvider(SecretsProviderFunc(func(ctx context.Context) (*Secrets, error) { return nil, nil }))
+				return c
+			},
+			b:         base,
+			shareable: false,
+		},
+		{
+			name: "share key enables sharing",
+			a: func() *Conf {
+				c := base()
+				c.ShareKey = "audit"
+				c.WithLogHandler(handler("a"))
+				return c
+			},
+			b: func() *Conf {
+				c := base()
+				c.ShareKey = "audit"
+				c.WithLogHandler(handler("b"))
+				return c
+			},
+			shareable: true,
+			same:      true,
+		},
+		{
+			name: "different share keys",
+			a: func() *Conf {
+				c := base()
+				c.ShareKey = "a"
+				return c
+			},
+			b: func() *Conf {
+				c := base()
+				c.ShareKey = "b"
+				return c
+			},
+			shareable: true,
+			same:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ka, oka, err := tt.a().sharedKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			kb, okb, err := tt.b().sharedKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if oka != tt.shareable {
+				t.Fatalf("shareable = %v, want %v", oka, tt.shareable)
+			}
+			if !tt.shareable {
+				return
+			}
+			if !okb {
+				t.Fatal("second conf is not shareable")
+			}
+			if (ka == kb) != tt.same {
+				t.Errorf("same key = %v, want %v", ka == kb, tt.same)
+			}
+		})
+	}
+}
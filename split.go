@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/tag"
+)
+
+// SplitReadsConf 为读写分离中只读句柄的读偏好配置。
+type SplitReadsConf struct {
+	// ReadPreference 为只读句柄的读偏好，为空时为 secondaryPreferred。
+	ReadPreference string `json:"read_preference"`
+	// MaxStaleness 为允许的从节点最大复制延迟（秒，<=0 表示不限制，设置时需 >= 90）。
+	MaxStaleness int `json:"max_staleness"`
+	// Tags 为按顺序匹配的节点标签集合，例如 [{"workload": "analytics"}, {}]。
+	Tags []map[string]string `json:"tags"`
+}
+
+// readPref 构造只读句柄的读偏好。
+func (s *SplitReadsConf) readPref() (*readpref.ReadPref, error) {
+	mode := readpref.SecondaryPreferredMode
+	var opts []readpref.Option
+	if s != nil {
+		if s.ReadPreference != "" {
+			m, err := readpref.ModeFromString(s.ReadPreference)
+			if err != nil {
+				return nil, err
+			}
+			mode = m
+		}
+		if len(s.Tags) != 0 {
+			opts = append(opts, readpref.WithTagSets(tag.NewTagSetsFromMaps(s.Tags)...))
+		}
+		if s.MaxStaleness > 0 {
+			opts = append(opts, readpref.WithMaxStaleness(time.Second*time.Duration(s.MaxStaleness)))
+		}
+	}
+	return readpref.New(mode, opts...)
+}
+
+// NewSplit 创建连接并返回读写分离的两个数据库句柄：reads 按 Conf.SplitReads 读从节点，writes 固定读写主节点。
+// 两个句柄共用同一连接池。
+func NewSplit(c *Conf) (reads, writes *mongo.Database, err error) {
+	client, err := NewClient(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.Reads(), client.Writes(), nil
+}
+
+// Reads 返回默认数据库的只读句柄，读偏好由 Conf.SplitReads 决定（默认 secondaryPreferred），
+// 适合统计分析等可容忍复制延迟的查询，避免占用主节点。
+func (c *Client) Reads() *mongo.Database {
+	return c.Raw().Database(c.database, options.Database().SetReadPreference(c.readsPref))
+}
+
+// Writes 返回默认数据库的主节点句柄，读写均发往主节点。
+func (c *Client) Writes() *mongo.Database {
+	return c.Raw().Database(c.database, options.Database().SetReadPreference(readpref.Primary()))
+}
@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/fireflycore/go-mongo/internal"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// 连接监护事件类型。
+const (
+	SuperviseEventPingFailed      = "ping_failed"      // SuperviseEventPingFailed 健康检查失败。
+	SuperviseEventReconnecting    = "reconnecting"     // SuperviseEventReconnecting 连续失败达到阈值，开始重建连接。
+	SuperviseEventReconnectFailed = "reconnect_failed" // SuperviseEventReconnectFailed 本次重建失败，退避后重试。
+	SuperviseEventReconnected     = "reconnected"      // SuperviseEventReconnected 重建成功并已替换底层连接。
+)
+
+// SuperviseEvent 为连接监护事件。
+type SuperviseEvent struct {
+	Type string
+	// Attempt 为连续失败次数（ping_failed）或重建尝试次数（reconnect_*）。
+	Attempt int
+	Err     error
+}
+
+// SuperviseConf 为连接监护配置，零值字段使用默认值。
+type SuperviseConf struct {
+	// Interval 为健康检查间隔，默认 10s。
+	Interval time.Duration
+	// Timeout 为单次 Ping 超时，默认 5s。
+	Timeout time.Duration
+	// FailureThreshold 为判定连接不可恢复的连续失败次数，默认 3。
+	FailureThreshold int
+	// MinBackoff/MaxBackoff 为重建连接的指数退避区间，默认 1s~1m。
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnEvent 接收监护事件；Conf.Logger 开启时事件同时写入结构化日志。
+	OnEvent func(e SuperviseEvent)
+}
+
+func (s *SuperviseConf) withDefaults() SuperviseConf {
+	conf := SuperviseConf{}
+	if s != nil {
+		conf = *s
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = 10 * time.Second
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 5 * time.Second
+	}
+	if conf.FailureThreshold <= 0 {
+		conf.FailureThreshold = 3
+	}
+	if conf.MinBackoff <= 0 {
+		conf.MinBackoff = time.Second
+	}
+	if conf.MaxBackoff < conf.MinBackoff {
+		conf.MaxBackoff = max(time.Minute, conf.MinBackoff)
+	}
+	return conf
+}
+
+// Supervise 在后台按 Conf.ReadPreference（未设置时为主节点）周期性 Ping，连续失败达到阈值后按指数退避重建连接，成功后原子替换底层 client
+// （旧连接在后台等待进行中的操作完成后关闭）。阻塞直到 ctx 结束，通常以 go client.Supervise(ctx, conf) 启动。
+//
+// driver 自身会在节点恢复后自动重连，Supervise 用于 DNS/SRV 记录变更、凭证轮换等 driver 无法自行恢复的场景。
+func (c *Client) Supervise(ctx context.Context, conf *SuperviseConf) {
+	s := conf.withDefaults()
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := c.ping(ctx, s.Timeout)
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		failures++
+		c.superviseEvent(ctx, s, SuperviseEvent{Type: SuperviseEventPingFailed, Attempt: failures, Err: err})
+		if failures < s.FailureThreshold {
+			continue
+		}
+
+		c.superviseEvent(ctx, s, SuperviseEvent{Type: SuperviseEventReconnecting})
+		if !c.rebuild(ctx, s) {
+			return
+		}
+		failures = 0
+	}
+}
+
+// ping 按配置的读偏好探测连接：secondaryPreferred 等部署在主节点故障期间仍可读，不应触发重建。
+func (c *Client) ping(ctx context.Context, timeout time.Duration) error {
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rp := c.readPref
+	if rp == nil {
+		rp = readpref.Primary()
+	}
+	return c.Raw().Ping(pingCtx, rp)
+}
+
+// rebuild 按指数退避重建连接直到成功，ctx 结束时返回 false。
+func (c *Client) rebuild(ctx context.Context, s SuperviseConf) bool {
+	backoff := s.MinBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.Reconnect(ctx)
+		if err == nil {
+			c.superviseEvent(ctx, s, SuperviseEvent{Type: SuperviseEventReconnected, Attempt: attempt})
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		c.superviseEvent(ctx, s, SuperviseEvent{Type: SuperviseEventReconnectFailed, Attempt: attempt, Err: err})
+
+		// 加入 ±20% 抖动，避免多实例同时重连。
+		wait := backoff + time.Duration((rand.Float64()*0.4-0.2)*float64(backoff))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, s.MaxBackoff)
+	}
+}
+
+func (c *Client) superviseEvent(ctx context.Context, s SuperviseConf, e SuperviseEvent) {
+	if c.logger {
+		logData := &internal.ReconnectLogger{Database: c.database, Event: e.Type, Attempt: e.Attempt}
+		if e.Err != nil {
+			logData.Error = e.Err.Error()
+		}
+		internal.EmitReconnectLog(ctx, c.output, logData)
+	}
+	if s.OnEvent != nil {
+		s.OnEvent(e)
+	}
+}
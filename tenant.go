@@ -0,0 +1,181 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fireflycore/go-micro/constant"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	// ErrNoTenant 表示 ctx 中没有租户标识。
+	ErrNoTenant = errors.New("mongo: tenant not found in context")
+	// ErrInvalidTenant 表示租户标识不能用于数据库名。
+	ErrInvalidTenant = errors.New("mongo: invalid tenant")
+)
+
+// maxDatabaseNameLen 为 MongoDB 数据库名的最大长度。
+const maxDatabaseNameLen = 63
+
+// reservedDatabases 为 MongoDB 保留的系统数据库名，租户路由不得指向这些库。
+var reservedDatabases = map[string]bool{"admin": true, "local": true, "config": true}
+
+// AppIdFromContext 从 gRPC 入站 metadata 中读取 x-firefly-app-id。
+func AppIdFromContext(ctx context.Context) (string, bool) {
+	return metadataValue(ctx, constant.AppId)
+}
+
+// TenantIdFromContext 从 gRPC 入站 metadata 中读取 x-firefly-tenant-id。
+func TenantIdFromContext(ctx context.Context) (string, bool) {
+	return metadataValue(ctx, constant.TenantId)
+}
+
+func metadataValue(ctx context.Context, key string) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if gd := md.Get(key); len(gd) != 0 && gd[0] != "" {
+		return gd[0], true
+	}
+	return "", false
+}
+
+// TenantOverride 为单个租户的覆盖配置，空字段沿用默认规则。
+type TenantOverride struct {
+	// Database 为租户使用的数据库名，为空时为 DatabasePrefix + 租户标识。
+	Database string `json:"database"`
+	// ReadPreference 为租户数据库的读偏好（如 secondaryPreferred）。
+	ReadPreference string `json:"read_preference"`
+	// CollectionPrefix 为租户集合名前缀，为空时使用 TenantRouterConf.CollectionPrefix。
+	CollectionPrefix string `json:"collection_prefix"`
+}
+
+// TenantRouterConf 为租户路由配置。
+type TenantRouterConf struct {
+	// DatabasePrefix 为租户数据库名前缀，租户 a 的数据库为 DatabasePrefix + "a"。
+	DatabasePrefix string `json:"database_prefix"`
+	// CollectionPrefix 为默认的集合名前缀。
+	CollectionPrefix string `json:"collection_prefix"`
+	// Overrides 为按租户标识的覆盖配置。
+	Overrides map[string]TenantOverride `json:"overrides"`
+
+	// Resolve 从 ctx 中解析租户标识，为空时使用 AppIdFromContext。
+	Resolve func(ctx context.Context) (string, bool) `json:"-"`
+}
+
+// TenantRouter 按租户将请求路由到独立的数据库（database-per-tenant）。
+type TenantRouter struct {
+	raw       func() *mongo.Client
+	conf      TenantRouterConf
+	readPrefs map[string]*readpref.ReadPref
+}
+
+// NewTenantRouter 基于 driver client 创建租户路由，覆盖配置中的读偏好非法时返回错误。
+func NewTenantRouter(client *mongo.Client, conf *TenantRouterConf) (*TenantRouter, error) {
+	return newTenantRouter(func() *mongo.Client { return client }, conf)
+}
+
+// TenantRouter 基于 Client 创建租户路由，Reconnect 后自动使用新的底层连接。
+func (c *Client) TenantRouter(conf *TenantRouterConf) (*TenantRouter, error) {
+	return newTenantRouter(c.Raw, conf)
+}
+
+func newTenantRouter(raw func() *mongo.Client, conf *TenantRouterConf) (*TenantRouter, error) {
+	r := &TenantRouter{raw: raw, readPrefs: map[string]*readpref.ReadPref{}}
+	if conf != nil {
+		r.conf = *conf
+	}
+	if r.conf.Resolve == nil {
+		r.conf.Resolve = AppIdFromContext
+	}
+
+	for tenant, override := range r.conf.Overrides {
+		if override.ReadPreference == "" {
+			continue
+		}
+		mode, err := readpref.ModeFromString(override.ReadPreference)
+		if err != nil {
+			return nil, fmt.Errorf("mongo: tenant %q: %w", tenant, err)
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, fmt.Errorf("mongo: tenant %q: %w", tenant, err)
+		}
+		r.readPrefs[tenant] = rp
+	}
+
+	return r, nil
+}
+
+// Tenant 从 ctx 中解析租户标识。
+func (r *TenantRouter) Tenant(ctx context.Context) (string, error) {
+	tenant, ok := r.conf.Resolve(ctx)
+	if !ok {
+		return "", ErrNoTenant
+	}
+	return tenant, nil
+}
+
+// Database 返回 ctx 中租户对应的数据库。
+func (r *TenantRouter) Database(ctx context.Context) (*mongo.Database, error) {
+	tenant, err := r.Tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.DatabaseFor(tenant)
+}
+
+// DatabaseFor 返回指定租户的数据库；租户标识来自请求头等不可信来源，包含数据库名非法字符时返回 ErrInvalidTenant。
+func (r *TenantRouter) DatabaseFor(tenant string) (*mongo.Database, error) {
+	name, err := r.databaseName(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Database()
+	if rp, ok := r.readPrefs[tenant]; ok {
+		opts.SetReadPreference(rp)
+	}
+	return r.raw().Database(name, opts), nil
+}
+
+// Collection 返回 ctx 中租户数据库下的集合，集合名会加上租户的集合前缀。
+func (r *TenantRouter) Collection(ctx context.Context, name string) (*mongo.Collection, error) {
+	tenant, err := r.Tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.DatabaseFor(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := r.conf.CollectionPrefix
+	if override, ok := r.conf.Overrides[tenant]; ok && override.CollectionPrefix != "" {
+		prefix = override.CollectionPrefix
+	}
+	return db.Collection(prefix + name), nil
+}
+
+func (r *TenantRouter) databaseName(tenant string) (string, error) {
+	if tenant == "" {
+		return "", fmt.Errorf("%w: empty tenant", ErrInvalidTenant)
+	}
+
+	name := r.conf.DatabasePrefix + tenant
+	if override, ok := r.conf.Overrides[tenant]; ok && override.Database != "" {
+		name = override.Database
+	}
+	if len(name) > maxDatabaseNameLen || strings.ContainsAny(name, "/\\. \"$\x00") {
+		return "", fmt.Errorf("%w: %q is not a valid database name", ErrInvalidTenant, name)
+	}
+	// 数据库名在部分平台上不区分大小写，按小写比较保留库名。
+	if reservedDatabases[strings.ToLower(name)] {
+		return "", fmt.Errorf("%w: %q is a reserved database", ErrInvalidTenant, name)
+	}
+	return name, nil
+}
@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fireflycore/go-utils/tlsx"
+)
+
+// TLSConfig 根据 Tls、TlsSystemCA、TlsInsecureSkipVerify 生成 TLS 配置，未启用 TLS 时返回 nil。
+//
+// Tls 三个字段齐全时为双向 TLS；只设置 CaCert 时为单向 TLS（不提供客户端证书）；
+// TlsSystemCA 为 true 时信任系统根证书（设置了 CaCert 时一并信任）。
+// 返回的配置不包含 SecretsProvider 提供的证书与 TlsReload 的热加载。
+func (c *Conf) TLSConfig() (*tls.Config, error) {
+	tlsConfig, enabled, err := tlsx.NewTLSConfig(c.Tls)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled || c.TlsSystemCA {
+		var caCert, clientCert, clientCertKey string
+		if c.Tls != nil {
+			caCert, clientCert, clientCertKey = c.Tls.CaCert, c.Tls.ClientCert, c.Tls.ClientCertKey
+		}
+		if caCert == "" && !c.TlsSystemCA && !c.TlsInsecureSkipVerify {
+			return nil, nil
+		}
+
+		tlsConfig = &tls.Config{}
+		if c.TlsSystemCA || caCert == "" {
+			// RootCAs 为 nil 时 crypto/tls 使用系统根证书。
+			if caCert != "" {
+				if tlsConfig.RootCAs, err = x509.SystemCertPool(); err != nil {
+					return nil, fmt.Errorf("mongo: load system cert pool: %w", err)
+				}
+			}
+		} else {
+			tlsConfig.RootCAs = x509.NewCertPool()
+		}
+		if caCert != "" {
+			pem, err := os.ReadFile(caCert)
+			if err != nil {
+				return nil, err
+			}
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, errors.New("failed to append ca cert")
+			}
+		}
+		if clientCert != "" && clientCertKey != "" {
+			cert, err := tls.LoadX509KeyPair(clientCert, clientCertKey)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	// 仅用于开发环境：跳过服务端证书与主机名校验，存在中间人攻击风险。
+	tlsConfig.InsecureSkipVerify = c.TlsInsecureSkipVerify
+	return tlsConfig, nil
+}
@@ -0,0 +1,61 @@
+package mongo
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader 在每次 TLS 握手时检查客户端证书文件的修改时间，变化后重新加载，
+// 已建立的连接不受影响，新建连接使用新证书。
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// newCertReloader 加载初始证书，失败时返回错误。
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetClientCertificate 实现 tls.Config.GetClientCertificate。
+// 重新加载失败（如证书与私钥只更新了其中一个）时继续使用上一次成功加载的证书。
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.load()
+}
+
+func (r *certReloader) load() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certStat, certErr := os.Stat(r.certFile)
+	keyStat, keyErr := os.Stat(r.keyFile)
+	if certErr == nil && keyErr == nil && r.cert != nil &&
+		certStat.ModTime().Equal(r.certMod) && keyStat.ModTime().Equal(r.keyMod) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, err
+	}
+
+	r.cert = &cert
+	if certErr == nil && keyErr == nil {
+		r.certMod, r.keyMod = certStat.ModTime(), keyStat.ModTime()
+	}
+	return r.cert, nil
+}
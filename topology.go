@@ -0,0 +1,115 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"weak"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrRequiresReplicaSet 表示操作依赖副本集或分片集群（事务、change stream、oplog），当前部署为单节点。
+var ErrRequiresReplicaSet = errors.New("mongo: operation requires a replica set or sharded cluster")
+
+// capabilities 按 client（见 clientKey）缓存服务端信息，拓扑在连接生命周期内视为不变。
+var capabilities sync.Map
+
+// Capabilities 返回 client 对应的服务端信息，首次调用时探测并缓存。
+func Capabilities(ctx context.Context, client *mongo.Client) (*ServerInfo, error) {
+	if v, ok := capabilities.Load(weak.Make(client)); ok {
+		return v.(*ServerInfo), nil
+	}
+
+	info, err := DescribeServer(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _ := capabilities.LoadOrStore(trackClient(client), info)
+	return v.(*ServerInfo), nil
+}
+
+// ForgetCapabilities 清除 client 的缓存信息，通常在 Disconnect 后调用。
+func ForgetCapabilities(client *mongo.Client) {
+	capabilities.Delete(weak.Make(client))
+}
+
+// WithTransaction 在事务中执行 fn，事务冲突等可重试错误由 driver 自动重试。
+// 单节点部署不支持事务时降级为直接执行 fn：fn 内的写入不具备原子性，出错时不会回滚。
+// 需要严格事务语义时使用 WithStrictTransaction。
+func WithTransaction(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error, opts ...options.Lister[options.TransactionOptions]) error {
+	info, err := Capabilities(ctx, client)
+	if err != nil {
+		return err
+	}
+	if !info.Transactions {
+		return fn(ctx)
+	}
+	return runTransaction(ctx, client, fn, opts...)
+}
+
+// WithStrictTransaction 与 WithTransaction 相同，但部署不支持事务时返回 ErrRequiresReplicaSet。
+func WithStrictTransaction(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error, opts ...options.Lister[options.TransactionOptions]) error {
+	info, err := Capabilities(ctx, client)
+	if err != nil {
+		return err
+	}
+	if !info.Transactions {
+		return ErrRequiresReplicaSet
+	}
+	return runTransaction(ctx, client, fn, opts...)
+}
+
+func runTransaction(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error, opts ...options.Lister[options.TransactionOptions]) error {
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx)
+	}, opts...)
+	return err
+}
+
+// Watch 打开集合的 change stream。change stream 没有等价的降级行为，单节点部署时返回 ErrRequiresReplicaSet。
+// 以 watch 操作经过拦截器，Filter 为 pipeline，拦截器改写后的 pipeline 用于打开 change stream。
+func Watch(ctx context.Context, collection *mongo.Collection, pipeline any, opts ...options.Lister[options.ChangeStreamOptions]) (_ *mongo.ChangeStream, err error) {
+	info, err := Capabilities(ctx, collection.Database().Client())
+	if err != nil {
+		return nil, err
+	}
+	if !info.ChangeStreams {
+		return nil, ErrRequiresReplicaSet
+	}
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+	if err := CheckFilter(pipeline); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "watch", Filter: pipeline}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	return collection.Watch(ctx, op.Filter, opts...)
+}
+
+// RequireReplicaSet 在部署不支持事务与 change stream 时返回 ErrRequiresReplicaSet，用于启动时的前置检查。
+func RequireReplicaSet(ctx context.Context, client *mongo.Client) error {
+	info, err := Capabilities(ctx, client)
+	if err != nil {
+		return err
+	}
+	if info.Topology == TopologyStandalone {
+		return ErrRequiresReplicaSet
+	}
+	return nil
+}
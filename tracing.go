@@ -0,0 +1,41 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/v2/mongo/otelmongo"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingConf 为命令级 OpenTelemetry Span 配置，零值为启用并记录完整命令。
+type TracingConf struct {
+	// Disabled 为 true 时不创建 Span。
+	Disabled bool `json:"disabled"`
+	// DisableStatement 为 true 时不记录 db.query.text（完整命令包含参数值，可能带有敏感数据）。
+	DisableStatement bool `json:"disable_statement"`
+}
+
+// WithTracerProvider 设置创建命令 Span 使用的 TracerProvider，未设置时使用全局 provider。
+func (c *Conf) WithTracerProvider(provider trace.TracerProvider) {
+	c.tracerProvider = provider
+}
+
+// tracingMonitor 构造 otelmongo 命令监控器：每条命令以 ctx 中的 Span 为父节点创建 client Span，
+// 成功或失败时结束，失败时记录错误状态。Tracing.Disabled 时返回 nil。
+func (c *Conf) tracingMonitor() *event.CommandMonitor {
+	tracing := c.Tracing
+	if tracing == nil {
+		tracing = &TracingConf{}
+	}
+	if tracing.Disabled {
+		return nil
+	}
+
+	opts := []otelmongo.Option{otelmongo.WithCommandAttributeDisabled(tracing.DisableStatement)}
+	if c.tracerProvider != nil {
+		opts = append(opts, otelmongo.WithTracerProvider(c.tracerProvider))
+	}
+	if c.meterProvider != nil {
+		opts = append(opts, otelmongo.WithMeterProvider(c.meterProvider))
+	}
+	return otelmongo.NewMonitor(opts...)
+}
@@ -0,0 +1,130 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TrashedMode 为软删除文档的查询范围。
+type TrashedMode uint8
+
+const (
+	// TrashedExclude 只查询未软删除的文档（默认）。
+	TrashedExclude TrashedMode = iota
+	// TrashedInclude 同时查询未删除与已软删除的文档。
+	TrashedInclude
+	// TrashedOnly 只查询已软删除的文档（回收站）。
+	TrashedOnly
+)
+
+type trashedKey struct{}
+
+// WithTrashed 返回查询时包含软删除文档的 ctx。
+func WithTrashed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trashedKey{}, TrashedInclude)
+}
+
+// OnlyTrashed 返回查询时只包含软删除文档的 ctx。
+func OnlyTrashed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trashedKey{}, TrashedOnly)
+}
+
+// TrashedFromContext 返回 ctx 中的软删除查询范围，未设置时为 TrashedExclude。
+func TrashedFromContext(ctx context.Context) TrashedMode {
+	mode, _ := ctx.Value(trashedKey{}).(TrashedMode)
+	return mode
+}
+
+// ScopeDeleted 按 ctx 的软删除查询范围向 filter 追加 deleted_at 条件，返回新的 filter。
+func ScopeDeleted(ctx context.Context, filter bson.D) bson.D {
+	out := append(bson.D{}, filter...)
+	switch TrashedFromContext(ctx) {
+	case TrashedInclude:
+		return out
+	case TrashedOnly:
+		return append(out, bson.E{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}})
+	default:
+		return append(out, bson.E{Key: "deleted_at", Value: nil})
+	}
+}
+
+// Find 查询满足 filter 的文档，自动按 ctx 的软删除查询范围过滤（默认排除已软删除的文档），并剔除 ctx 角色无权读取的字段。
+func Find[T any](ctx context.Context, collection *mongo.Collection, filter bson.D, opts ...options.Lister[options.FindOptions]) (list []T, err error) {
+	filter = ScopeDeleted(ctx, filter)
+	if err := CheckFilter(filter); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "find", Filter: filter}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withProfile(ctx, collection)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, op.Filter, append([]options.Lister[options.FindOptions]{profileFind(collection)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAll[T](ctx, PolicyNamespace(collection), cursor)
+}
+
+// FindById 按 id 查询单个文档，自动按 ctx 的软删除查询范围过滤并剔除无权读取的字段；不存在时返回 mongo.ErrNoDocuments。
+func FindById[T any](ctx context.Context, collection *mongo.Collection, id string) (_ *T, err error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "find_by_id", Ids: []string{id}, Filter: ScopeDeleted(ctx, bson.D{{Key: "_id", Value: id}})}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withProfile(ctx, collection)
+	defer cancel()
+
+	raw, err := collection.FindOne(ctx, op.Filter).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var doc T
+	if err := DecodeRestricted(ctx, PolicyNamespace(collection), raw, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// RestoreById 恢复单条软删除文档：清除 deleted_at 并刷新 updated_at。
+func RestoreById(ctx context.Context, collection *mongo.Collection, id string) (result *mongo.UpdateResult, err error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	op := &Operation{Name: "restore", Ids: []string{id}, Filter: bson.D{
+		{Key: "_id", Value: id},
+		{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}},
+	}, Update: bson.D{
+		{Key: "$set", Value: bson.M{"updated_at": time.Now().UTC()}},
+		{Key: "$unset", Value: bson.M{"deleted_at": ""}},
+	}}
+	ctx, finish, err := intercept(ctx, collection, op)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { finish(err) }()
+
+	ctx, collection, cancel := withWrite(ctx, collection)
+	defer cancel()
+
+	return collection.UpdateOne(ctx, op.Filter, op.Update)
+}
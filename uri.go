@@ -0,0 +1,93 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/fireflycore/go-utils/network"
+)
+
+// srvScheme 为 DNS 种子列表连接协议前缀。
+const srvScheme = "mongodb+srv://"
+
+// isSrv 判断是否使用 mongodb+srv，返回去掉协议前缀后的地址。
+func (c *Conf) isSrv() (string, bool) {
+	if address, ok := strings.CutPrefix(c.Address, srvScheme); ok {
+		return address, true
+	}
+	return c.Address, c.Srv
+}
+
+// URI 根据配置组装连接串：Srv 为 true 或 Address 以 mongodb+srv:// 开头时使用 DNS 种子列表发现，
+// Addresses 非空时为 mongodb://host1:port1,host2:port2，否则为 mongodb://host:port；
+// 设置了 ReplicaSet 时追加 /?replicaSet={name}。
+func (c *Conf) URI() (string, error) {
+	uri, err := c.hostsURI()
+	if err != nil {
+		return "", err
+	}
+	if c.ReplicaSet != "" {
+		uri += "/?replicaSet=" + url.QueryEscape(c.ReplicaSet)
+	}
+	return uri, nil
+}
+
+// hostsURI 返回不带参数的连接串。
+func (c *Conf) hostsURI() (string, error) {
+	address, srv := c.isSrv()
+	if len(c.Addresses) != 0 {
+		if srv {
+			return "", errors.New("mongo: addresses cannot be used with mongodb+srv")
+		}
+
+		hosts := make([]string, 0, len(c.Addresses))
+		for _, addr := range c.Addresses {
+			host, port, err := network.SplitHostPort(addr, "27017")
+			if err != nil {
+				return "", err
+			}
+			hosts = append(hosts, net.JoinHostPort(host, port))
+		}
+		return "mongodb://" + strings.Join(hosts, ","), nil
+	}
+	if srv {
+		if address == "" {
+			return "", errors.New("mongo: address is empty")
+		}
+		// SRV 记录已包含端口，连接串中不允许再指定。
+		if _, _, err := net.SplitHostPort(address); err == nil {
+			return "", fmt.Errorf("mongo: mongodb+srv address %q must not contain a port", address)
+		}
+		return srvScheme + address, nil
+	}
+
+	host, port, err := network.SplitHostPort(address, "27017")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("mongodb://%s", net.JoinHostPort(host, port)), nil
+}
+
+// displayAddress 返回用于日志展示的地址。
+func (c *Conf) displayAddress() string {
+	if len(c.Addresses) != 0 {
+		return strings.Join(c.Addresses, ",")
+	}
+	return c.Address
+}
+
+// tlsServerName 返回 TLS 校验使用的主机名；SRV 与多节点模式下每个节点主机名不同，交由 driver 按节点设置。
+func (c *Conf) tlsServerName() string {
+	address, srv := c.isSrv()
+	if srv || len(c.Addresses) != 0 {
+		return ""
+	}
+	host, _, err := network.SplitHostPort(address, "27017")
+	if err != nil {
+		return ""
+	}
+	return host
+}
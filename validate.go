@@ -0,0 +1,267 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidConf 为配置校验错误的哨兵值，ConfError 与 ValidationError 均可通过 errors.Is 匹配。
+var ErrInvalidConf = errors.New("mongo: invalid conf")
+
+// defaultHeartbeatInterval 为 driver 默认的心跳间隔（毫秒）。
+const defaultHeartbeatInterval = 10000
+
+// ConfError 为单个字段的校验错误。
+type ConfError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfError) Error() string {
+	return fmt.Sprintf("mongo: invalid conf %s: %s", e.Field, e.Reason)
+}
+
+func (e *ConfError) Unwrap() error {
+	return ErrInvalidConf
+}
+
+// ValidationError 汇总 Validate 发现的全部字段错误。
+type ValidationError struct {
+	Errors []*ConfError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// validCompressors 为 driver 支持的压缩算法。
+var validCompressors = map[string]bool{"snappy": true, "zlib": true, "zstd": true}
+
+// Validate 在建立连接前检查地址格式与端口范围、连接池与超时参数、TLS 字段完整性及互斥配置，
+// 发现问题时返回 *ValidationError。New 建立连接前会自动调用。
+func (c *Conf) Validate() error {
+	v := &validator{}
+
+	address, srv := c.isSrv()
+	switch {
+	case len(c.Addresses) != 0:
+		if srv {
+			v.fail("Addresses", "cannot be used with mongodb+srv")
+		}
+		for i, addr := range c.Addresses {
+			v.address(fmt.Sprintf("Addresses[%d]", i), addr)
+		}
+	case address == "":
+		v.fail("Address", "is empty")
+	case srv:
+		if strings.Contains(address, "/") {
+			v.fail("Address", fmt.Sprintf("mongodb+srv address %q must be a host name", address))
+		} else if _, _, err := net.SplitHostPort(address); err == nil {
+			v.fail("Address", fmt.Sprintf("mongodb+srv address %q must not contain a port", address))
+		}
+	default:
+		v.address("Address", address)
+	}
+
+	if c.LoadBalanced {
+		if len(c.Addresses) > 1 {
+			v.fail("LoadBalanced", "cannot be used with multiple Addresses")
+		}
+		if c.ReplicaSet != "" {
+			v.fail("LoadBalanced", "cannot be used with ReplicaSet")
+		}
+	}
+
+	if c.Database != "" && strings.ContainsAny(c.Database, "/\\. \"$") {
+		v.fail("Database", fmt.Sprintf("%q contains characters not allowed in database names", c.Database))
+	}
+
+	switch c.AuthMechanism {
+	case AuthMechanismX509:
+		if c.Password != "" {
+			v.fail("Password", "must be empty for MONGODB-X509")
+		}
+	case AuthMechanismPLAIN:
+		if c.Username == "" && c.secrets == nil {
+			v.fail("Username", "is required for PLAIN")
+		}
+	}
+	if c.Kerberos != nil && c.AuthMechanism != AuthMechanismGSSAPI {
+		v.fail("Kerberos", "requires AuthMechanism GSSAPI")
+	}
+	if c.Password != "" && c.Username == "" && c.AuthMechanism == "" {
+		v.fail("Username", "is required when Password is set")
+	}
+
+	if c.Tls != nil {
+		if countNonEmpty(c.Tls.ClientCert, c.Tls.ClientCertKey) == 1 {
+			v.fail("Tls", "ClientCert and ClientCertKey must be set together")
+		}
+		if c.Tls.ClientCert != "" && c.Tls.CaCert == "" && !c.TlsSystemCA && !c.TlsInsecureSkipVerify {
+			v.fail("Tls", "ClientCert requires CaCert or TlsSystemCA")
+		}
+	}
+
+	v.nonNegative("MaxOpenConnects", c.MaxOpenConnects)
+	v.nonNegative("MinOpenConnects", c.MinOpenConnects)
+	if c.MaxOpenConnects > 0 && c.MinOpenConnects > c.MaxOpenConnects {
+		v.fail("MinOpenConnects", "must not exceed MaxOpenConnects")
+	}
+	if c.WarmUp && c.MinOpenConnects == 0 {
+		v.fail("WarmUp", "requires MinOpenConnects")
+	}
+	v.nonNegative("ConnMaxLifeTime", c.ConnMaxLifeTime)
+	v.nonNegative("Timeout", c.Timeout)
+	v.nonNegative("ServerSelectionTimeout", c.ServerSelectionTimeout)
+	v.nonNegative("ConnectTimeout", c.ConnectTimeout)
+	v.nonNegative("SocketTimeout", c.SocketTimeout)
+	v.nonNegative("KeepAlive", c.KeepAlive)
+	v.nonNegative("MaxStatementLength", c.MaxStatementLength)
+	if c.LogSampleRate < 0 || c.LogSampleRate > 1 {
+		v.fail("LogSampleRate", "must be between 0 and 1")
+	}
+	if len(c.LogCommands) != 0 && len(c.LogSkipCommands) != 0 {
+		v.fail("LogSkipCommands", "must not be set together with LogCommands")
+	}
+	if c.LogFile != nil {
+		if c.LogFile.Path == "" {
+			v.fail("LogFile.Path", "is required")
+		}
+		v.nonNegative("LogFile.MaxSize", c.LogFile.MaxSize)
+		v.nonNegative("LogFile.MaxAge", c.LogFile.MaxAge)
+		if !c.Logger {
+			v.fail("LogFile", "requires Logger")
+		}
+	}
+	if c.Redact != nil {
+		for _, field := range c.Redact.Fields {
+			if _, err := path.Match(field, ""); err != nil {
+				v.fail("Redact.Fields", fmt.Sprintf("invalid pattern %q", field))
+			}
+		}
+	}
+	if c.Proxy != "" {
+		if _, err := newProxyDialer(c.Proxy, nil); err != nil {
+			v.fail("Proxy", err.Error())
+		}
+	}
+	if c.SocketTimeout > 0 && !c.LoadBalanced {
+		heartbeat := c.HeartbeatInterval
+		if heartbeat <= 0 {
+			heartbeat = defaultHeartbeatInterval
+		}
+		if c.SocketTimeout <= heartbeat {
+			v.fail("SocketTimeout", fmt.Sprintf("must be greater than HeartbeatInterval (%dms)", heartbeat))
+		}
+	}
+	if c.HeartbeatInterval > 0 && c.HeartbeatInterval < 500 {
+		v.fail("HeartbeatInterval", "must be at least 500ms")
+	}
+
+	if _, err := c.readPref(); err != nil {
+		v.fail("ReadPreference", err.Error())
+	}
+	if c.MaxStaleness > 0 && c.MaxStaleness < 90 {
+		v.fail("MaxStaleness", "must be at least 90 seconds")
+	}
+	if (len(c.ReadPreferenceTags) != 0 || c.MaxStaleness > 0) && c.ReadPreference == "" {
+		v.fail("ReadPreference", "is required when ReadPreferenceTags or MaxStaleness is set")
+	}
+
+	if _, err := c.SplitReads.readPref(); err != nil {
+		v.fail("SplitReads", err.Error())
+	}
+	if c.SplitReads != nil && c.SplitReads.MaxStaleness > 0 && c.SplitReads.MaxStaleness < 90 {
+		v.fail("SplitReads.MaxStaleness", "must be at least 90 seconds")
+	}
+
+	for _, name := range c.Compressors {
+		if !validCompressors[name] {
+			v.fail("Compressors", fmt.Sprintf("unknown compressor %q", name))
+		}
+	}
+	if c.ZlibLevel != 0 && (c.ZlibLevel < -1 || c.ZlibLevel > 9) {
+		v.fail("ZlibLevel", "must be in [-1, 9]")
+	}
+	if c.ZstdLevel != 0 && (c.ZstdLevel < 1 || c.ZstdLevel > 20) {
+		v.fail("ZstdLevel", "must be in [1, 20]")
+	}
+
+	if c.BSON != nil && c.BSON.DefaultDocumentM && c.BSON.DefaultDocumentMap {
+		v.fail("BSON", "DefaultDocumentM and DefaultDocumentMap are mutually exclusive")
+	}
+
+	if _, err := c.Encryption.autoEncryptionOptions(); err != nil {
+		v.fail("Encryption", err.Error())
+	}
+
+	if _, err := c.ServerAPI.serverAPIOptions(); err != nil {
+		v.fail("ServerAPI", err.Error())
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// validator 收集字段校验错误。
+type validator struct {
+	errs []*ConfError
+}
+
+func (v *validator) fail(field, reason string) {
+	v.errs = append(v.errs, &ConfError{Field: field, Reason: reason})
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.fail(field, "must not be negative")
+	}
+}
+
+// address 校验 host[:port]，端口需在 1~65535 之间，IPv6 需使用 [host]:port 形式。
+func (v *validator) address(field, addr string) {
+	if strings.Contains(addr, "://") {
+		v.fail(field, fmt.Sprintf("%q must be host:port without scheme", addr))
+		return
+	}
+
+	host, port := addr, ""
+	if strings.HasPrefix(addr, "[") || strings.Count(addr, ":") == 1 {
+		h, p, err := net.SplitHostPort(addr)
+		if err != nil {
+			v.fail(field, fmt.Sprintf("%q: %v", addr, err))
+			return
+		}
+		host, port = h, p
+	} else if strings.Count(addr, ":") > 1 {
+		v.fail(field, fmt.Sprintf("IPv6 address %q must be written as [host]:port", addr))
+		return
+	}
+
+	if host == "" || strings.ContainsAny(host, "/?@ ") {
+		v.fail(field, fmt.Sprintf("invalid host in %q", addr))
+	}
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			v.fail(field, fmt.Sprintf("port %q must be in [1, 65535]", port))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/fireflycore/go-utils/tlsx"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   Conf
+		fields []string
+	}{
+		{name: "valid", conf: Conf{Address: "127.0.0.1:27017", Database: "app"}},
+		{name: "host without port", conf: Conf{Address: "db.local"}},
+		{name: "ipv6", conf: Conf{Addresses: []string{"[::1]:27017", "db2:27018"}}},
+		{name: "srv", conf: Conf{Address: "mongodb+srv://cluster.example.com"}},
+		{name: "empty address", fields: []string{"Address"}},
+		{name: "scheme in address", conf: Conf{Address: "mongodb://db:27017"}, fields: []string{"Address"}},
+		{name: "port out of range", conf: Conf{Address: "db:70000"}, fields: []string{"Address"}},
+		{name: "bare ipv6", conf: Conf{Addresses: []string{"::1:27017"}}, fields: []string{"Addresses[0]"}},
+		{name: "srv with port", conf: Conf{Address: "mongodb+srv://cluster.example.com:27017"}, fields: []string{"Address"}},
+		{name: "srv with addresses", conf: Conf{Srv: true, Addresses: []string{"db:27017"}}, fields: []string{"Addresses"}},
+		{
+			name:   "load balanced",
+			conf:   Conf{Addresses: []string{"a:1", "b:2"}, LoadBalanced: true, ReplicaSet: "rs0"},
+			fields: []string{"LoadBalanced", "LoadBalanced"},
+		},
+		{name: "database name", conf: Conf{Address: "db:27017", Database: "a.b"}, fields: []string{"Database"}},
+		{name: "password without user", conf: Conf{Address: "db:27017", Password: "p"}, fields: []string{"Username"}},
+		{
+			name:   "pool",
+			conf:   Conf{Address: "db:27017", MaxOpenConnects: 5, MinOpenConnects: 10, Timeout: -1},
+			fields: []string{"MinOpenConnects", "Timeout"},
+		},
+		{name: "warm up", conf: Conf{Address: "db:27017", WarmUp: true}, fields: []string{"WarmUp"}},
+		{
+			name:   "tls cert without key",
+			conf:   Conf{Address: "db:27017", Tls: &tlsx.TLS{ClientCert: "cert"}},
+			fields: []string{"Tls", "Tls"},
+		},
+		{name: "log sample rate", conf: Conf{Address: "db:27017", LogSampleRate: 2}, fields: []string{"LogSampleRate"}},
+		{name: "log file without logger", conf: Conf{Address: "db:27017", LogFile: &LogFileConf{}}, fields: []string{"LogFile.Path", "LogFile"}},
+		{name: "socket timeout below heartbeat", conf: Conf{Address: "db:27017", SocketTimeout: 1000}, fields: []string{"SocketTimeout"}},
+		{name: "unknown compressor", conf: Conf{Address: "db:27017", Compressors: []string{"lz4"}}, fields: []string{"Compressors"}},
+		{name: "zstd level", conf: Conf{Address: "db:27017", ZstdLevel: 30}, fields: []string{"ZstdLevel"}},
+		{name: "max staleness", conf: Conf{Address: "db:27017", MaxStaleness: 10}, fields: []string{"MaxStaleness", "ReadPreference"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.conf.Validate()
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidConf) {
+				t.Fatalf("Validate = %v, want a ValidationError", err)
+			}
+			var fields []string
+			for _, e := range verr.Errors {
+				fields = append(fields, e.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("fields = %v, want %v\n%v", fields, tt.fields, err)
+			}
+		})
+	}
+}
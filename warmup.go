@@ -0,0 +1,32 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// warmUp 并发执行 n 个 Ping，使连接池预先建立连接（含 TCP、TLS 握手与认证）。
+// 预热失败不影响连接可用，返回成功的 Ping 数量。
+func warmUp(ctx context.Context, client *mongo.Client, rp *readpref.ReadPref, n int) int {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ok int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Ping(ctx, rp); err == nil {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return ok
+}
@@ -0,0 +1,70 @@
+package mongo
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+	"weak"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// WriteConcernConf 为写关注配置。
+type WriteConcernConf struct {
+	// W 为确认级别："majority"、节点数（如 "2"，"0" 表示不确认）或副本集自定义标签名，为空时为 driver 默认值。
+	W string `json:"w"`
+	// Journal 为 true 时要求写入落盘到 journal 后才确认。
+	Journal *bool `json:"journal"`
+	// WTimeout 为等待确认的超时（毫秒，<=0 表示不限制）。
+	// mongo-driver v2 已移除 wtimeout，这里作为本包写入 helper 的操作超时（CSOT）生效。
+	WTimeout int `json:"wtimeout"`
+}
+
+// writeConcern 构造 driver 写关注，W 与 Journal 均未设置时返回 nil。
+func (c *WriteConcernConf) writeConcern() *writeconcern.WriteConcern {
+	if c == nil || (c.W == "" && c.Journal == nil) {
+		return nil
+	}
+
+	wc := &writeconcern.WriteConcern{Journal: c.Journal}
+	if c.W != "" {
+		if n, err := strconv.Atoi(c.W); err == nil {
+			wc.W = n
+		} else {
+			wc.W = c.W
+		}
+	}
+	return wc
+}
+
+// writeTimeouts 按 client（见 clientKey）保存 WriteConcernConf.WTimeout。
+var writeTimeouts sync.Map
+
+type writeConcernKey struct{}
+
+// WithWriteConcern 为本次调用覆盖写关注，优先于集合 Profile 与 Conf 中的配置，对本包的写入 helper 生效。
+func WithWriteConcern(ctx context.Context, wc *writeconcern.WriteConcern) context.Context {
+	return context.WithValue(ctx, writeConcernKey{}, wc)
+}
+
+// withWrite 供写入 helper 在操作前调用：在 withProfile 的基础上应用 ctx 中的写关注覆盖与 client 的 WTimeout。
+func withWrite(ctx context.Context, collection *mongo.Collection) (context.Context, *mongo.Collection, context.CancelFunc) {
+	ctx, collection, cancel := withProfile(ctx, collection)
+
+	if wc, ok := ctx.Value(writeConcernKey{}).(*writeconcern.WriteConcern); ok && wc != nil {
+		collection = collection.Clone(options.Collection().SetWriteConcern(wc))
+	}
+
+	v, ok := writeTimeouts.Load(weak.Make(collection.Database().Client()))
+	if !ok {
+		return ctx, collection, cancel
+	}
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, v.(time.Duration))
+	return timeoutCtx, collection, func() {
+		timeoutCancel()
+		cancel()
+	}
+}